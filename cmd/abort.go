@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/restack"
+	"github.com/spf13/cobra"
+)
+
+var abortCmd = &cobra.Command{
+	Use:   "abort",
+	Short: "Abort a stopped restack and restore every branch",
+	Long: `Abort a restack that stopped on a conflict.
+
+This command:
+1. Aborts the in-progress rebase (git rebase --abort)
+2. Moves every branch the operation already rewrote back to its
+   commit from before the operation; uncommitted changes are kept, and
+   the abort stops if they are in the way
+3. Restores the recorded parent commits in the stack metadata
+4. Returns to the branch the operation started from
+
+Example:
+  gw abort`,
+	Args: cobra.NoArgs,
+	RunE: withOperationLog("abort", runAbort),
+}
+
+func init() {
+	rootCmd.AddCommand(abortCmd)
+}
+
+func runAbort(cmd *cobra.Command, args []string) error {
+	// Initialize repository
+	repo, err := openRepo()
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	state, err := restack.Load(repo.GetRestackStatePath())
+	if err != nil {
+		return err
+	}
+
+	if isRebaseInProgress(repo) {
+		if err := repo.AbortRebase(); err != nil {
+			return fmt.Errorf("failed to abort rebase: %w", err)
+		}
+		fmt.Printf("%s Aborted rebase\n", colors.Success("✓"))
+	} else if state == nil {
+		fmt.Println(colors.Muted("Nothing to abort."))
+		return nil
+	}
+
+	if state == nil {
+		return nil
+	}
+
+	if state.OriginalBranch != "" && repo.BranchExists(state.OriginalBranch) {
+		if err := repo.CheckoutBranch(state.OriginalBranch); err != nil {
+			return err
+		}
+	}
+
+	if err := restoreRestackBranches(repo, state); err != nil {
+		return err
+	}
+
+	if err := clearRestackState(repo.GetRestackStatePath()); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Aborted 'gw %s'\n", colors.Success("✓"), state.Operation)
+	return nil
+}
+
+// restoreRestackBranches moves rewritten branches back to their pre-operation commits
+func restoreRestackBranches(repo *git.Repo, state *restack.State) error {
+	metadata, err := config.LoadRepoMetadata(repo)
+	if err != nil {
+		return fmt.Errorf("failed to load metadata: %w", err)
+	}
+
+	branches := make([]string, 0, len(state.Original))
+	for branch := range state.Original {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+
+	currentBranch, _ := repo.GetCurrentBranch()
+	updates := []git.RefUpdate{}
+	for _, branch := range branches {
+		original := state.Original[branch]
+		if metadata.IsTracked(branch) {
+			if err := metadata.SetParentSHA(branch, original.ParentSHA); err != nil {
+				return err
+			}
+		}
+
+		sha, err := repo.GetBranchCommit(branch)
+		if err != nil || sha == original.SHA {
+			continue
+		}
+
+		if branch == currentBranch {
+			if err := resetKeep(repo, branch, original.SHA); err != nil {
+				return err
+			}
+		} else {
+			updates = append(updates, git.RefUpdate{Ref: "refs/heads/" + branch, OldSHA: sha, NewSHA: original.SHA})
+		}
+		fmt.Printf("  ↺ Restored %s to %s\n", colors.BranchCurrent(branch), colors.CommitSHA(original.SHA[:7]))
+	}
+
+	if err := repo.UpdateRefs(updates); err != nil {
+		return err
+	}
+
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
+		return fmt.Errorf("failed to save metadata: %w", err)
+	}
+	return nil
+}
+
+// resetKeep moves the checked-out branch to commit with 'git reset --keep', which
+// carries uncommitted changes along and refuses rather than discard them
+func resetKeep(repo *git.Repo, branch, commit string) error {
+	if _, err := repo.RunGitCommand("reset", "--keep", commit); err != nil {
+		changed, _ := repo.ChangedFiles()
+		if len(changed) == 0 {
+			return fmt.Errorf("failed to move %s: %w", branch, err)
+		}
+		return fmt.Errorf("cannot move %s without discarding uncommitted changes in:\n  %s\ncommit or stash them and try again",
+			branch, strings.Join(changed, "\n  "))
+	}
+	return nil
+}
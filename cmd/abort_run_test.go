@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/restack"
+)
+
+// setupStoppedRestack restacks from trunk until feat-b conflicts, leaving feat-z queued.
+// It returns the branch commits from before the restack.
+func setupStoppedRestack(t *testing.T, repo *cmdTestRepo) map[string]string {
+	t.Helper()
+
+	t.Setenv("GIT_EDITOR", "true")
+	t.Setenv("GIT_SEQUENCE_EDITOR", "true")
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.commitFile(t, "a.txt", "a", "a commit")
+	repo.createBranch(t, "feat-b", "feat-a")
+	repo.commitFile(t, "shared.txt", "b", "b commit")
+	repo.createBranch(t, "feat-z", "main")
+	repo.commitFile(t, "z.txt", "z", "z commit")
+
+	// feat-a changes the file feat-b touched, and trunk moves on
+	if err := repo.repo.CheckoutBranch("feat-a"); err != nil {
+		t.Fatalf("failed to checkout feat-a: %v", err)
+	}
+	repo.commitFile(t, "shared.txt", "a", "a touches shared")
+	if err := repo.repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	repo.commitFile(t, "main.txt", "main", "main moves")
+
+	original := map[string]string{}
+	for _, branch := range []string{"feat-a", "feat-b", "feat-z"} {
+		sha, err := repo.repo.GetBranchCommit(branch)
+		if err != nil {
+			t.Fatalf("failed to get %s: %v", branch, err)
+		}
+		original[branch] = sha
+	}
+
+	if err := runStackRestack(nil, nil); err == nil {
+		t.Fatalf("expected restack to stop on a conflict")
+	}
+
+	state, err := restack.Load(repo.repo.GetRestackStatePath())
+	if err != nil || state == nil {
+		t.Fatalf("expected restack state to be saved, got %+v (%v)", state, err)
+	}
+	if state.OriginalBranch != "main" || len(state.Queue) != 2 || state.Queue[0] != "feat-b" || state.Queue[1] != "feat-z" {
+		t.Fatalf("unexpected restack state: %+v", state)
+	}
+	return original
+}
+
+func TestRunContinueResumesRestackQueue(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	setupStoppedRestack(t, repo)
+
+	if err := os.WriteFile(repo.dir+"/shared.txt", []byte("resolved"), 0644); err != nil {
+		t.Fatalf("failed to resolve conflict: %v", err)
+	}
+	if _, err := repo.repo.RunGitCommand("add", "shared.txt"); err != nil {
+		t.Fatalf("failed to stage resolution: %v", err)
+	}
+
+	if err := runContinue(nil, nil); err != nil {
+		t.Fatalf("runContinue failed: %v", err)
+	}
+
+	// The unrelated branch queued after the conflict is restacked too
+	for branch, parent := range map[string]string{"feat-b": "feat-a", "feat-z": "main"} {
+		if !repo.repo.IsAncestor(parent, branch) {
+			t.Fatalf("expected %s to be restacked onto %s", branch, parent)
+		}
+	}
+	if current, _ := repo.repo.GetCurrentBranch(); current != "main" {
+		t.Fatalf("expected to return to main, got %s", current)
+	}
+	if state, _ := restack.Load(repo.repo.GetRestackStatePath()); state != nil {
+		t.Fatalf("expected restack state to be cleared")
+	}
+}
+
+func TestRunAbortRestoresBranches(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	original := setupStoppedRestack(t, repo)
+
+	// feat-a was already rewritten before the conflict
+	if sha, _ := repo.repo.GetBranchCommit("feat-a"); sha == original["feat-a"] {
+		t.Fatalf("expected feat-a to be rewritten before the conflict")
+	}
+
+	if err := runAbort(nil, nil); err != nil {
+		t.Fatalf("runAbort failed: %v", err)
+	}
+
+	if isRebaseInProgress(repo.repo) {
+		t.Fatalf("expected rebase to be aborted")
+	}
+	for branch, want := range original {
+		if sha, _ := repo.repo.GetBranchCommit(branch); sha != want {
+			t.Fatalf("expected %s to be restored to %s, got %s", branch, want, sha)
+		}
+	}
+	if current, _ := repo.repo.GetCurrentBranch(); current != "main" {
+		t.Fatalf("expected to return to main, got %s", current)
+	}
+	if state, _ := restack.Load(repo.repo.GetRestackStatePath()); state != nil {
+		t.Fatalf("expected restack state to be cleared")
+	}
+
+	if err := runAbort(nil, nil); err != nil {
+		t.Fatalf("runAbort with nothing to abort failed: %v", err)
+	}
+}
+
+func TestRunAbortKeepsUncommittedChanges(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.commitFile(t, "a.txt", "a", "a commit")
+	if err := repo.repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	repo.commitFile(t, "main.txt", "main", "main moves")
+	if err := repo.repo.CheckoutBranch("feat-a"); err != nil {
+		t.Fatalf("failed to checkout feat-a: %v", err)
+	}
+	original, _ := repo.repo.GetBranchCommit("feat-a")
+
+	// The current branch is restacked in memory with an edit to a tracked file
+	if err := os.WriteFile(repo.dir+"/a.txt", []byte("edited"), 0644); err != nil {
+		t.Fatalf("failed to edit a.txt: %v", err)
+	}
+	state := restack.New("restack", "feat-a")
+	state.Remember("feat-a", original, repo.metadata.GetParentSHA("feat-a"))
+	if err := state.Save(repo.repo.GetRestackStatePath()); err != nil {
+		t.Fatalf("failed to save state: %v", err)
+	}
+	if err := restackBranch(repo.repo, repo.metadata, "feat-a", "main"); err != nil {
+		t.Fatalf("restackBranch failed: %v", err)
+	}
+
+	// An edit to a file the restack added cannot be carried back
+	if err := os.WriteFile(repo.dir+"/main.txt", []byte("edited"), 0644); err != nil {
+		t.Fatalf("failed to edit main.txt: %v", err)
+	}
+	if err := runAbort(nil, nil); err == nil || !strings.Contains(err.Error(), "main.txt") {
+		t.Fatalf("expected abort to refuse and name main.txt, got %v", err)
+	}
+	if data, _ := os.ReadFile(repo.dir + "/main.txt"); string(data) != "edited" {
+		t.Fatalf("expected the edit to main.txt to survive")
+	}
+
+	if _, err := repo.repo.RunGitCommand("checkout", "--", "main.txt"); err != nil {
+		t.Fatalf("failed to discard main.txt edit: %v", err)
+	}
+	if err := runAbort(nil, nil); err != nil {
+		t.Fatalf("runAbort failed: %v", err)
+	}
+	if sha, _ := repo.repo.GetBranchCommit("feat-a"); sha != original {
+		t.Fatalf("expected feat-a to be restored to %s, got %s", original, sha)
+	}
+	if data, _ := os.ReadFile(repo.dir + "/a.txt"); string(data) != "edited" {
+		t.Fatalf("expected the edit to a.txt to survive the abort")
+	}
+}
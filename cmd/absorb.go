@@ -0,0 +1,455 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/israelmalagutti/git-wrapper/internal/absorb"
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+var absorbAmend bool
+
+var absorbCmd = &cobra.Command{
+	Use:   "absorb",
+	Short: "Fold staged changes into the commits they belong to",
+	Long: `Fold staged changes into the commits of the current stack that they fix.
+
+Each staged hunk is blamed to find the commit that last changed the lines it
+touches. When that is a single commit on a branch between trunk and the
+current branch, the hunk is absorbed into it:
+- By default a "fixup! <subject>" commit is added on top of that branch
+- With --amend the commit itself is rewritten, keeping its author and message
+
+Branches above the rewritten ones are restacked. Everything happens in
+memory, so nothing changes if any step would conflict.
+
+Hunks stay staged when their lines came from trunk or from several commits,
+when they would not apply to the target, and for new, deleted, renamed or
+binary files.
+
+Example:
+  git add -p
+  gw absorb --dry-run    # Show where each hunk would go
+  gw absorb              # Add fixup commits to the owning branches
+  gw absorb --amend      # Rewrite the owning commits instead`,
+	Args: cobra.NoArgs,
+	RunE: withOperationLog("absorb", runAbsorb),
+}
+
+func init() {
+	rootCmd.AddCommand(absorbCmd)
+	absorbCmd.Flags().BoolVar(&absorbAmend, "amend", false, "Amend the owning commits instead of adding fixup commits")
+}
+
+// absorbBranch is a branch between trunk and the current branch as it was before absorbing
+type absorbBranch struct {
+	name    string
+	parent  string
+	base    string
+	tip     string
+	commits []string // newest first
+	subject map[string]string
+}
+
+// absorbPlan is where each staged hunk goes
+type absorbPlan struct {
+	branches    []*absorbBranch
+	assignments []absorb.Assignment
+	skipped     []absorb.Skipped
+}
+
+// assigned returns the hunks going into each commit
+func (p *absorbPlan) assigned() map[string][]absorb.Hunk {
+	byCommit := map[string][]absorb.Hunk{}
+	for _, a := range p.assignments {
+		if a.Target != nil {
+			byCommit[a.Target.Commit] = append(byCommit[a.Target.Commit], a.Hunk)
+		}
+	}
+	return byCommit
+}
+
+func runAbsorb(cmd *cobra.Command, args []string) error {
+	// Initialize repository
+	repo, err := openRepo()
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	// Load config
+	cfg, err := config.Load(repo.GetConfigPath())
+	if err != nil {
+		return err
+	}
+
+	// Load metadata
+	metadata, err := config.LoadRepoMetadata(repo)
+	if err != nil {
+		return fmt.Errorf("failed to load metadata: %w", err)
+	}
+
+	// Get current branch
+	currentBranch, err := repo.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	if currentBranch == cfg.Trunk {
+		return fmt.Errorf("cannot absorb into trunk; check out a branch of the stack first")
+	}
+
+	if !metadata.IsTracked(currentBranch) {
+		return fmt.Errorf("branch '%s' is not tracked by gw", currentBranch)
+	}
+
+	// Build stack
+	s, err := stack.BuildStack(repo, cfg, metadata)
+	if err != nil {
+		return fmt.Errorf("failed to build stack: %w", err)
+	}
+
+	plan, err := planAbsorb(repo, metadata, s.FindPath(currentBranch), absorbAmend)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Println("Planned absorb:")
+		printAbsorbPlan(plan)
+		return nil
+	}
+
+	if len(plan.assigned()) == 0 {
+		printAbsorbPlan(plan)
+		fmt.Println("Nothing to absorb.")
+		return nil
+	}
+
+	// Branches off the path are restacked afterwards; find their bases while the refs are untouched
+	offPath := absorbOffPath(repo, metadata, s, plan)
+
+	newTips, err := rewriteAbsorb(repo, plan, absorbAmend)
+	if err != nil {
+		return err
+	}
+
+	if err := applyAbsorb(repo, metadata, plan, newTips); err != nil {
+		return err
+	}
+
+	fmt.Println("Absorbed staged changes:")
+	printAbsorbPlan(plan)
+	for _, b := range plan.branches {
+		if _, ok := newTips[b.name]; ok {
+			fmt.Printf("%s Rewrote %s\n", colors.Success("✓"), colors.BranchCurrent(b.name))
+		}
+	}
+
+	restackAbsorbOffPath(repo, metadata, offPath)
+	return nil
+}
+
+// planAbsorb parses the staged changes and assigns each hunk to a commit on path
+func planAbsorb(repo *git.Repo, metadata *config.Metadata, path []*stack.Node, amend bool) (*absorbPlan, error) {
+	diff, err := repo.StagedDiff(1)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(diff) == "" {
+		return nil, fmt.Errorf("nothing staged to absorb")
+	}
+
+	plan := &absorbPlan{}
+	targets := map[string]*absorb.Target{}
+
+	// Path starts at trunk, whose commits are never rewritten
+	for _, node := range path {
+		if node.Parent == nil {
+			continue
+		}
+
+		b := &absorbBranch{name: node.Name, parent: node.Parent.Name, subject: map[string]string{}}
+		if b.tip, err = repo.GetBranchCommit(b.name); err != nil {
+			return nil, err
+		}
+		b.base, err = repo.GetRestackBase(b.name, b.parent, metadata.GetParentSHA(b.name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to find where '%s' starts: %w", b.name, err)
+		}
+
+		out, err := repo.RunGitCommand("log", "--format=%H %s", b.base+".."+b.tip)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list commits of '%s': %w", b.name, err)
+		}
+		for _, line := range strings.Split(out, "\n") {
+			sha, subject, _ := strings.Cut(line, " ")
+			if sha == "" {
+				continue
+			}
+			b.commits = append(b.commits, sha)
+			b.subject[sha] = subject
+			targets[sha] = &absorb.Target{Commit: sha, Branch: b.name, Subject: subject}
+		}
+		plan.branches = append(plan.branches, b)
+	}
+
+	var hunks []absorb.Hunk
+	hunks, plan.skipped = absorb.ParseDiff(diff)
+	plan.assignments = absorb.Assign(hunks, targets, func(file string) ([]string, error) {
+		return repo.BlameFile("HEAD", file)
+	})
+
+	// Keep hunks whose context has changed since the owning commit staged
+	for i, a := range plan.assignments {
+		if a.Target == nil {
+			continue
+		}
+		onto := a.Target.Commit
+		if !amend {
+			onto = targetBranch(plan, a.Target.Branch).tip
+		}
+		if _, err := repo.ApplyToTree(onto, absorb.Patch([]absorb.Hunk{a.Hunk})); err != nil {
+			plan.assignments[i].Target = nil
+			plan.assignments[i].Reason = fmt.Sprintf("does not apply to %s", a.Target.Branch)
+		}
+	}
+
+	return plan, nil
+}
+
+// targetBranch returns the planned branch called name
+func targetBranch(plan *absorbPlan, name string) *absorbBranch {
+	for _, b := range plan.branches {
+		if b.name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// rewriteAbsorb creates the new commits for each branch on the path, bottom-up,
+// without moving any ref, and returns the new tip of every branch that changed
+func rewriteAbsorb(repo *git.Repo, plan *absorbPlan, amend bool) (map[string]string, error) {
+	byCommit := plan.assigned()
+	newTips := map[string]string{}
+
+	for _, b := range plan.branches {
+		tip := b.tip
+
+		// Amend from the newest commit down, so each replay carries the amends above it
+		if amend {
+			for _, commit := range b.commits {
+				hunks := byCommit[commit]
+				if len(hunks) == 0 {
+					continue
+				}
+				tree, err := repo.ApplyToTree(commit, absorb.Patch(hunks))
+				if err != nil {
+					return nil, err
+				}
+				amended, err := repo.AmendTree(commit, tree)
+				if err != nil {
+					return nil, err
+				}
+				if tip, err = replayAbsorb(repo, b.name, tip, amended, commit); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		// Move the branch onto its rewritten parent
+		if newParent, ok := newTips[b.parent]; ok {
+			var err error
+			if tip, err = replayAbsorb(repo, b.name, tip, newParent, b.base); err != nil {
+				return nil, err
+			}
+		}
+
+		// Fixups go on top, oldest target first
+		if !amend {
+			for i := len(b.commits) - 1; i >= 0; i-- {
+				commit := b.commits[i]
+				hunks := byCommit[commit]
+				if len(hunks) == 0 {
+					continue
+				}
+				tree, err := repo.ApplyToTree(tip, absorb.Patch(hunks))
+				if err != nil {
+					return nil, err
+				}
+				if tip, err = repo.CommitTree(tree, tip, "fixup! "+b.subject[commit]); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if tip != b.tip {
+			newTips[b.name] = tip
+		}
+	}
+
+	return newTips, nil
+}
+
+// replayAbsorb replays base..tip onto onto, failing on conflicts
+func replayAbsorb(repo *git.Repo, branch, tip, onto, base string) (string, error) {
+	newTip, conflicts, err := repo.ReplayCommits(tip, onto, base)
+	if errors.Is(err, git.ErrCannotReplay) {
+		return "", fmt.Errorf("cannot absorb into '%s' because it contains merge commits", branch)
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(conflicts) > 0 {
+		return "", fmt.Errorf("absorbing would conflict in '%s' (%s); nothing was changed", branch, strings.Join(conflicts, ", "))
+	}
+	return newTip, nil
+}
+
+// applyAbsorb checks the rewritten current branch holds exactly the absorbed
+// changes, then moves the branch refs and records the new parent SHAs
+func applyAbsorb(repo *git.Repo, metadata *config.Metadata, plan *absorbPlan, newTips map[string]string) error {
+	current := plan.branches[len(plan.branches)-1]
+	newCurrent, ok := newTips[current.name]
+	if !ok {
+		return fmt.Errorf("absorb did not change '%s'; nothing was changed", current.name)
+	}
+
+	var hunks []absorb.Hunk
+	for _, a := range plan.assignments {
+		if a.Target != nil {
+			hunks = append(hunks, a.Hunk)
+		}
+	}
+	expected, err := repo.ApplyToTree(current.tip, absorb.Patch(hunks))
+	if err != nil {
+		return err
+	}
+	actual, err := repo.RunGitCommand("rev-parse", newCurrent+"^{tree}")
+	if err != nil {
+		return err
+	}
+	if actual != expected {
+		return fmt.Errorf("rewritten '%s' does not match the staged changes; nothing was changed", current.name)
+	}
+
+	// Branches checked out elsewhere would be left with a stale working tree
+	checkedOut, err := repo.CheckedOutBranches()
+	if err != nil {
+		return err
+	}
+
+	var updates []git.RefUpdate
+	for _, b := range plan.branches {
+		newTip, ok := newTips[b.name]
+		if !ok {
+			continue
+		}
+		if b != current && checkedOut[b.name] {
+			return fmt.Errorf("branch '%s' is checked out in another worktree; nothing was changed", b.name)
+		}
+		updates = append(updates, git.RefUpdate{Ref: "refs/heads/" + b.name, OldSHA: b.tip, NewSHA: newTip})
+	}
+
+	// The current branch only moves its ref: the index and working tree
+	// already hold its new tree plus whatever stays staged
+	if err := repo.UpdateRefs(updates); err != nil {
+		return fmt.Errorf("failed to update branches: %w", err)
+	}
+
+	for _, b := range plan.branches {
+		if newParent, ok := newTips[b.parent]; ok {
+			if err := metadata.SetParentSHA(b.name, newParent); err != nil {
+				return err
+			}
+		}
+	}
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
+		return fmt.Errorf("failed to save metadata: %w", err)
+	}
+	return nil
+}
+
+// absorbRestack is a branch off the path that needs restacking after absorb
+type absorbRestack struct {
+	name   string
+	parent string
+	base   string
+}
+
+// absorbOffPath lists the descendants of the path that are not on it, parents first,
+// with the base each will be replayed from
+func absorbOffPath(repo *git.Repo, metadata *config.Metadata, s *stack.Stack, plan *absorbPlan) []absorbRestack {
+	onPath := map[string]bool{}
+	for _, b := range plan.branches {
+		onPath[b.name] = true
+	}
+
+	var names []string
+	for _, b := range plan.branches {
+		for _, child := range s.GetNode(b.name).SortedChildren() {
+			if !onPath[child.Name] {
+				names = append(names, child.Name)
+				names = append(names, descendantNames(child)...)
+			}
+		}
+	}
+
+	var branches []absorbRestack
+	for _, name := range names {
+		parent, ok := metadata.GetParent(name)
+		if !ok {
+			continue
+		}
+		base, err := repo.GetRestackBase(name, parent, metadata.GetParentSHA(name))
+		if err != nil {
+			continue
+		}
+		branches = append(branches, absorbRestack{name: name, parent: parent, base: base})
+	}
+	return branches
+}
+
+// restackAbsorbOffPath replays the off-path branches whose parent moved, warning
+// about the ones that need a manual restack
+func restackAbsorbOffPath(repo *git.Repo, metadata *config.Metadata, branches []absorbRestack) {
+	for _, b := range branches {
+		// Parents that were not rewritten leave their children in place
+		parentTip, err := repo.GetBranchCommit(b.parent)
+		if err != nil || repo.IsAncestor(parentTip, b.name) {
+			continue
+		}
+
+		restacked, conflicts, err := restackInMemory(repo, b.name, b.parent, b.base)
+		if err != nil || !restacked {
+			fmt.Printf("%s Could not restack %s; run 'gw restack' on it\n", colors.Warning("⚠"), colors.BranchCurrent(b.name))
+			if len(conflicts) > 0 {
+				fmt.Printf("  Conflicts in %s\n", strings.Join(conflicts, ", "))
+			}
+			continue
+		}
+		_ = recordParentSHA(repo, metadata, b.name, b.parent)
+		fmt.Printf("%s Restacked %s onto %s\n", colors.Success("✓"), colors.BranchCurrent(b.name), colors.BranchParent(b.parent))
+	}
+}
+
+// printAbsorbPlan lists where each hunk goes and which files are left staged
+func printAbsorbPlan(plan *absorbPlan) {
+	for _, a := range plan.assignments {
+		if a.Target != nil {
+			fmt.Printf("  %s → %s %s %s\n", a.Hunk.Location(), colors.CommitSHA(a.Target.Commit[:7]),
+				a.Target.Subject, colors.Muted("("+a.Target.Branch+")"))
+		} else {
+			fmt.Printf("  %s %s\n", a.Hunk.Location(), colors.Muted("left staged: "+a.Reason))
+		}
+	}
+	for _, skipped := range plan.skipped {
+		fmt.Printf("  %s %s\n", skipped.File, colors.Muted("left staged: "+skipped.Reason))
+	}
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/stack"
+)
+
+// setupAbsorbStack builds main → feat-a → feat-b → feat-c plus feat-x on feat-a,
+// where feat-a changes line 3 of f.txt and feat-b changes line 8, and stages
+// changes to lines 3, 5 and 8 on feat-b
+func setupAbsorbStack(t *testing.T) *cmdTestRepo {
+	t.Helper()
+	repo := setupCmdTestRepo(t)
+
+	lines := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}
+	text := func() string { return strings.Join(lines, "\n") + "\n" }
+
+	if err := repo.repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	repo.commitFile(t, "f.txt", text(), "add f")
+
+	repo.createBranch(t, "feat-a", "main")
+	lines[2] = "three"
+	repo.commitFile(t, "f.txt", text(), "a edits 3")
+	repo.createBranch(t, "feat-x", "feat-a")
+	repo.commitFile(t, "x.txt", "x", "x commit")
+	repo.createBranch(t, "feat-b", "feat-a")
+	lines[7] = "eight"
+	repo.commitFile(t, "f.txt", text(), "b edits 8")
+	repo.createBranch(t, "feat-c", "feat-b")
+	repo.commitFile(t, "c.txt", "c", "c commit")
+	if err := repo.repo.CheckoutBranch("feat-b"); err != nil {
+		t.Fatalf("failed to checkout feat-b: %v", err)
+	}
+
+	lines[2], lines[4], lines[7] = "THREE", "five", "EIGHT"
+	if err := os.WriteFile(filepath.Join(repo.dir, "f.txt"), []byte(text()), 0644); err != nil {
+		t.Fatalf("failed to write f.txt: %v", err)
+	}
+	if _, err := repo.repo.RunGitCommand("add", "f.txt"); err != nil {
+		t.Fatalf("failed to stage f.txt: %v", err)
+	}
+	return repo
+}
+
+func TestRunAbsorb(t *testing.T) {
+	repo := setupAbsorbStack(t)
+	defer repo.cleanup()
+
+	prevDryRun, prevAmend := dryRun, absorbAmend
+	defer func() { dryRun, absorbAmend = prevDryRun, prevAmend }()
+	dryRun, absorbAmend = false, false
+
+	if err := runAbsorb(nil, nil); err != nil {
+		t.Fatalf("runAbsorb failed: %v", err)
+	}
+
+	for branch, subject := range map[string]string{"feat-a": "fixup! a edits 3", "feat-b": "fixup! b edits 8"} {
+		if got, _ := repo.repo.RunGitCommand("log", "-1", "--format=%s", branch); got != subject {
+			t.Fatalf("expected %s to end in %q, got %q", branch, subject, got)
+		}
+	}
+	if got, _ := repo.repo.RunGitCommand("show", "feat-b:f.txt"); !strings.Contains(got, "THREE") || !strings.Contains(got, "EIGHT") || strings.Contains(got, "five") {
+		t.Fatalf("unexpected feat-b contents:\n%s", got)
+	}
+
+	// The trunk line stays staged and the working tree is untouched
+	if staged, _ := repo.repo.RunGitCommand("diff", "--cached"); !strings.Contains(staged, "+five") || strings.Contains(staged, "+THREE") {
+		t.Fatalf("expected only line 5 to stay staged, got:\n%s", staged)
+	}
+	if unstaged, _ := repo.repo.RunGitCommand("diff"); unstaged != "" {
+		t.Fatalf("expected no unstaged changes, got:\n%s", unstaged)
+	}
+
+	// Branches above and beside the path follow
+	for branch, parent := range map[string]string{"feat-c": "feat-b", "feat-x": "feat-a"} {
+		if !repo.repo.IsAncestor(parent, branch) {
+			t.Fatalf("expected %s to be restacked onto %s", branch, parent)
+		}
+	}
+
+	metadata, err := config.LoadRepoMetadata(repo.repo)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	tipA, _ := repo.repo.GetBranchCommit("feat-a")
+	if metadata.GetParentSHA("feat-b") != tipA || metadata.GetParentSHA("feat-x") != tipA {
+		t.Fatalf("expected parent SHAs to record the new feat-a tip")
+	}
+}
+
+func TestRunAbsorbAmend(t *testing.T) {
+	repo := setupAbsorbStack(t)
+	defer repo.cleanup()
+
+	prevDryRun, prevAmend := dryRun, absorbAmend
+	defer func() { dryRun, absorbAmend = prevDryRun, prevAmend }()
+	dryRun, absorbAmend = false, true
+
+	if err := runAbsorb(nil, nil); err != nil {
+		t.Fatalf("runAbsorb failed: %v", err)
+	}
+
+	// No commits are added; the owning commits carry the changes
+	if got, _ := repo.repo.RunGitCommand("log", "--format=%s", "main..feat-b"); got != "b edits 8\na edits 3" {
+		t.Fatalf("unexpected feat-b history:\n%s", got)
+	}
+	if got, _ := repo.repo.RunGitCommand("show", "feat-a:f.txt"); !strings.Contains(got, "THREE") {
+		t.Fatalf("expected feat-a's commit to be amended:\n%s", got)
+	}
+	if got, _ := repo.repo.RunGitCommand("show", "feat-b:f.txt"); !strings.Contains(got, "EIGHT") {
+		t.Fatalf("expected feat-b's commit to be amended:\n%s", got)
+	}
+	if !repo.repo.IsAncestor("feat-b", "feat-c") {
+		t.Fatalf("expected feat-c to be restacked onto feat-b")
+	}
+}
+
+func TestRunAbsorbDryRun(t *testing.T) {
+	repo := setupAbsorbStack(t)
+	defer repo.cleanup()
+
+	prevDryRun, prevAmend := dryRun, absorbAmend
+	defer func() { dryRun, absorbAmend = prevDryRun, prevAmend }()
+	dryRun, absorbAmend = true, false
+
+	before := map[string]string{}
+	for _, branch := range []string{"feat-a", "feat-b", "feat-c", "feat-x"} {
+		before[branch], _ = repo.repo.GetBranchCommit(branch)
+	}
+
+	if err := runAbsorb(nil, nil); err != nil {
+		t.Fatalf("runAbsorb failed: %v", err)
+	}
+
+	for branch, sha := range before {
+		if after, _ := repo.repo.GetBranchCommit(branch); after != sha {
+			t.Fatalf("expected %s to be unchanged", branch)
+		}
+	}
+	if staged, _ := repo.repo.RunGitCommand("diff", "--cached", "--stat"); !strings.Contains(staged, "f.txt") {
+		t.Fatalf("expected changes to stay staged")
+	}
+}
+
+func TestPlanAbsorb(t *testing.T) {
+	repo := setupAbsorbStack(t)
+	defer repo.cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo.dir, "other.txt"), []byte("other"), 0644); err != nil {
+		t.Fatalf("failed to write other.txt: %v", err)
+	}
+	if _, err := repo.repo.RunGitCommand("add", "other.txt"); err != nil {
+		t.Fatalf("failed to stage other.txt: %v", err)
+	}
+
+	metadata, err := config.LoadRepoMetadata(repo.repo)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	s, err := stack.BuildStack(repo.repo, repo.cfg, metadata)
+	if err != nil {
+		t.Fatalf("BuildStack failed: %v", err)
+	}
+
+	plan, err := planAbsorb(repo.repo, metadata, s.FindPath("feat-b"), true)
+	if err != nil {
+		t.Fatalf("planAbsorb failed: %v", err)
+	}
+
+	got := map[string]string{}
+	for _, a := range plan.assignments {
+		if a.Target != nil {
+			got[a.Hunk.Location()] = a.Target.Branch
+		} else {
+			got[a.Hunk.Location()] = a.Reason
+		}
+	}
+	want := map[string]string{
+		"f.txt:3": "feat-a",
+		"f.txt:5": "lines last changed outside the current stack",
+		"f.txt:8": "feat-b",
+	}
+	for location, expected := range want {
+		if got[location] != expected {
+			t.Errorf("%s: got %q, want %q", location, got[location], expected)
+		}
+	}
+	if len(plan.skipped) != 1 || plan.skipped[0].File != "other.txt" || plan.skipped[0].Reason != "new file" {
+		t.Errorf("expected other.txt to be skipped as a new file, got %v", plan.skipped)
+	}
+}
+
+func TestRunAbsorbErrors(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.commitFile(t, "a.txt", "a", "a commit")
+
+	prevDryRun, prevAmend := dryRun, absorbAmend
+	defer func() { dryRun, absorbAmend = prevDryRun, prevAmend }()
+	dryRun, absorbAmend = false, false
+
+	if err := runAbsorb(nil, nil); err == nil || !strings.Contains(err.Error(), "nothing staged") {
+		t.Fatalf("expected nothing staged error, got %v", err)
+	}
+
+	if err := repo.repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	if err := runAbsorb(nil, nil); err == nil || !strings.Contains(err.Error(), "trunk") {
+		t.Fatalf("expected trunk error, got %v", err)
+	}
+}
@@ -4,15 +4,20 @@ import (
 	"fmt"
 
 	"github.com/israelmalagutti/git-wrapper/internal/config"
-	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/stack"
 	"github.com/spf13/cobra"
 )
 
+var bottomJSON bool
+
 var bottomCmd = &cobra.Command{
 	Use:   "bottom",
 	Short: "Jump to trunk branch",
 	Long: `Jump directly to the trunk branch (bottom of the stack).
 
+With --json, prints trunk as JSON instead of switching to it, in the
+format described in USAGE.md.
+
 Example:
   gw bottom    # Checkout trunk`,
 	Aliases: []string{"b"},
@@ -22,11 +27,12 @@ Example:
 
 func init() {
 	rootCmd.AddCommand(bottomCmd)
+	bottomCmd.Flags().BoolVar(&bottomJSON, "json", false, "Print trunk as JSON without switching")
 }
 
 func runBottom(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -37,6 +43,18 @@ func runBottom(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if bottomJSON {
+		metadata, err := config.LoadRepoMetadata(repo)
+		if err != nil {
+			return fmt.Errorf("failed to load metadata: %w", err)
+		}
+		s, err := stack.BuildStack(repo, cfg, metadata)
+		if err != nil {
+			return fmt.Errorf("failed to build stack: %w", err)
+		}
+		return printStackJSON(repo, s, metadata, []string{cfg.Trunk})
+	}
+
 	// Get current branch
 	currentBranch, err := repo.GetCurrentBranch()
 	if err != nil {
@@ -17,7 +17,7 @@ import (
 var (
 	checkoutTrunk         bool
 	checkoutShowUntracked bool
-	checkoutStack         bool
+	checkoutScope         scopeFlags
 )
 
 var checkoutCmd = &cobra.Command{
@@ -35,6 +35,7 @@ Example:
   gw checkout              # Interactive branch selector (tracked only)
   gw checkout -t           # Switch to trunk
   gw checkout -s           # Interactive selector (current stack only)
+  gw checkout --upstack    # Interactive selector (current branch and above)
   gw co -u                 # Show untracked branches in selector`,
 	RunE: runCheckout,
 }
@@ -43,12 +44,12 @@ func init() {
 	rootCmd.AddCommand(checkoutCmd)
 	checkoutCmd.Flags().BoolVarP(&checkoutTrunk, "trunk", "t", false, "Checkout the trunk branch")
 	checkoutCmd.Flags().BoolVarP(&checkoutShowUntracked, "show-untracked", "u", false, "Include untracked branches in interactive selection")
-	checkoutCmd.Flags().BoolVarP(&checkoutStack, "stack", "s", false, "Only show current stack in interactive selection")
+	addScopeFlags(checkoutCmd, &checkoutScope, "Select from", stack.ScopeUpstack, stack.ScopeDownstack, stack.ScopeStack)
 }
 
 func runCheckout(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -60,7 +61,7 @@ func runCheckout(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load metadata
-	metadata, err := config.LoadMetadata(repo.GetMetadataPath())
+	metadata, err := config.LoadRepoMetadata(repo)
 	if err != nil {
 		return fmt.Errorf("failed to load metadata: %w", err)
 	}
@@ -96,28 +97,15 @@ func runCheckout(cmd *cobra.Command, args []string) error {
 		filteredBranches := []string{}
 		currentBranch, _ := repo.GetCurrentBranch()
 
-		for _, branch := range branches {
-			// Handle --stack flag: only show current stack
-			if checkoutStack {
-				// Include if it's in the path from trunk to current, or is a descendant
-				path := s.FindPath(currentBranch)
-				inPath := false
-				for _, node := range path {
-					if node.Name == branch {
-						inPath = true
-						break
-					}
-				}
-
-				// Also include descendants of current branch
-				isDescendant := false
-				if currentNode := s.GetNode(currentBranch); currentNode != nil {
-					isDescendant = isInDescendants(s, currentNode, branch)
-				}
+		// Handle scope flags: only show part of the current stack
+		var inScope map[string]bool
+		if checkoutScope.isSet() {
+			inScope = scopeSet(s, currentBranch, checkoutScope.resolve(stack.ScopeStack))
+		}
 
-				if !inPath && !isDescendant {
-					continue
-				}
+		for _, branch := range branches {
+			if inScope != nil && !inScope[branch] {
+				continue
 			}
 
 			// Handle --show-untracked flag
@@ -247,15 +235,20 @@ func checkoutBranch(repo *git.Repo, s *stack.Stack, targetBranch string) error {
 	return nil
 }
 
-// isInDescendants checks if a branch is a descendant of a node
-func isInDescendants(s *stack.Stack, node *stack.Node, branchName string) bool {
-	for _, child := range node.Children {
-		if child.Name == branchName {
-			return true
-		}
-		if isInDescendants(s, child, branchName) {
-			return true
-		}
+// scopeSet returns the branches the interactive selector offers for scope. The current
+// branch is always included, and trunk too when the scope reaches down to it.
+func scopeSet(s *stack.Stack, currentBranch string, scope stack.Scope) map[string]bool {
+	set := map[string]bool{}
+	if s.GetNode(currentBranch) == nil {
+		return set
+	}
+
+	set[currentBranch] = true
+	if scope == stack.ScopeDownstack || scope == stack.ScopeStack {
+		set[s.TrunkName] = true
+	}
+	for _, node := range s.SelectScope(currentBranch, scope) {
+		set[node.Name] = true
 	}
-	return false
+	return set
 }
@@ -18,11 +18,11 @@ func TestRunCheckoutInteractiveAndFlags(t *testing.T) {
 
 	prevTrunk := checkoutTrunk
 	prevShow := checkoutShowUntracked
-	prevStack := checkoutStack
+	prevStack := checkoutScope.stack
 	defer func() {
 		checkoutTrunk = prevTrunk
 		checkoutShowUntracked = prevShow
-		checkoutStack = prevStack
+		checkoutScope.stack = prevStack
 	}()
 
 	// Trunk flag path
@@ -59,7 +59,7 @@ func TestRunCheckoutInteractiveAndFlags(t *testing.T) {
 	checkoutShowUntracked = false
 
 	// Stack-only filter
-	checkoutStack = true
+	checkoutScope.stack = true
 	if err := repo.repo.CheckoutBranch("feat-2"); err != nil {
 		t.Fatalf("failed to checkout feat-2: %v", err)
 	}
@@ -68,7 +68,7 @@ func TestRunCheckoutInteractiveAndFlags(t *testing.T) {
 			t.Fatalf("runCheckout stack failed: %v", err)
 		}
 	})
-	checkoutStack = false
+	checkoutScope.stack = false
 }
 
 func TestRunCheckoutNoBranchesMatch(t *testing.T) {
@@ -83,15 +83,15 @@ func TestRunCheckoutNoBranchesMatch(t *testing.T) {
 	}
 
 	prevShow := checkoutShowUntracked
-	prevStack := checkoutStack
+	prevStack := checkoutScope.stack
 	defer func() {
 		checkoutShowUntracked = prevShow
-		checkoutStack = prevStack
+		checkoutScope.stack = prevStack
 	}()
 
 	// Not tracked, stack-only filter should result in no branches
 	checkoutShowUntracked = false
-	checkoutStack = true
+	checkoutScope.stack = true
 	if err := runCheckout(nil, nil); err == nil {
 		t.Fatalf("expected no branches match error")
 	}
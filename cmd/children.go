@@ -2,40 +2,45 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/israelmalagutti/git-wrapper/internal/config"
-	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/stack"
 	"github.com/spf13/cobra"
 )
 
+var childrenJSON bool
+
 var childrenCmd = &cobra.Command{
 	Use:   "children [branch]",
 	Short: "Show the children branches",
 	Long: `Show all children branches of the specified branch.
 
-If no branch is specified, shows children of the current branch.`,
+If no branch is specified, shows children of the current branch.
+Use --json to describe the children in the format described in USAGE.md.`,
 	RunE: runChildren,
 }
 
 func init() {
 	rootCmd.AddCommand(childrenCmd)
+	childrenCmd.Flags().BoolVar(&childrenJSON, "json", false, "Print the children as JSON")
 }
 
 func runChildren(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
 
 	// Load config
-	_, err = config.Load(repo.GetConfigPath())
+	cfg, err := config.Load(repo.GetConfigPath())
 	if err != nil {
 		return err
 	}
 
 	// Load metadata
-	metadata, err := config.LoadMetadata(repo.GetMetadataPath())
+	metadata, err := config.LoadRepoMetadata(repo)
 	if err != nil {
 		return fmt.Errorf("failed to load metadata: %w", err)
 	}
@@ -54,6 +59,15 @@ func runChildren(cmd *cobra.Command, args []string) error {
 
 	// Get children
 	children := metadata.GetChildren(branchName)
+	if childrenJSON {
+		s, err := stack.BuildStack(repo, cfg, metadata)
+		if err != nil {
+			return fmt.Errorf("failed to build stack: %w", err)
+		}
+		sort.Strings(children)
+		return printStackJSON(repo, s, metadata, children)
+	}
+
 	if len(children) == 0 {
 		fmt.Printf("Branch '%s' has no children\n", branchName)
 		return nil
@@ -31,7 +31,7 @@ Examples:
   gw commit -pm "Add feature"    # Interactive patch mode
   gw commit                      # Interactive mode`,
 	Aliases: []string{"ci"},
-	RunE:    runCommit,
+	RunE:    withOperationLog("commit", runCommit),
 }
 
 func init() {
@@ -43,7 +43,7 @@ func init() {
 
 func runCommit(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -8,6 +8,7 @@ import (
 	"github.com/israelmalagutti/git-wrapper/internal/colors"
 	"github.com/israelmalagutti/git-wrapper/internal/config"
 	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/restack"
 	"github.com/israelmalagutti/git-wrapper/internal/stack"
 	"github.com/spf13/cobra"
 )
@@ -19,15 +20,17 @@ var continueCmd = &cobra.Command{
 
 This command:
 1. Continues the in-progress rebase (git rebase --continue)
-2. Restacks remaining children branches
+2. Restacks the branches the stopped operation had not reached yet
+3. Returns to the branch the operation started from
 
 Use this after resolving merge conflicts during a restack operation.
+Use 'gw abort' instead to undo the whole operation.
 
 Example:
   # After resolving conflicts:
   git add .
   gw continue`,
-	RunE: runContinue,
+	RunE: withOperationLog("continue", runContinue),
 }
 
 func init() {
@@ -36,11 +39,20 @@ func init() {
 
 func runContinue(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
 
+	// Resume the queue of the operation that stopped, if gw recorded one
+	state, err := restack.Load(repo.GetRestackStatePath())
+	if err != nil {
+		return err
+	}
+	if state != nil {
+		return resumeRestack(repo, state)
+	}
+
 	// Check if a rebase is in progress
 	if !isRebaseInProgress(repo) {
 		fmt.Println(colors.Muted("No rebase in progress."))
@@ -67,7 +79,7 @@ func runContinue(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	metadata, err := config.LoadMetadata(repo.GetMetadataPath())
+	metadata, err := config.LoadRepoMetadata(repo)
 	if err != nil {
 		return fmt.Errorf("failed to load metadata: %w", err)
 	}
@@ -85,12 +97,19 @@ func runContinue(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Record the parent the finished rebase landed on
+	if node.Parent != nil && repo.IsAncestor(node.Parent.Name, currentBranch) {
+		if err := recordParentSHA(repo, metadata, currentBranch, node.Parent.Name); err != nil {
+			return err
+		}
+	}
+
 	// Restack children if any
 	if len(node.Children) > 0 {
 		fmt.Println()
 		fmt.Println(colors.Muted("Restacking children..."))
 
-		if err := continueRestackChildren(repo, s, node); err != nil {
+		if err := continueRestackChildren(repo, metadata, node); err != nil {
 			return err
 		}
 
@@ -109,6 +128,41 @@ func runContinue(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// resumeRestack finishes the stopped rebase and restacks the rest of the recorded queue
+func resumeRestack(repo *git.Repo, state *restack.State) error {
+	if isRebaseInProgress(repo) {
+		fmt.Println(colors.Muted("Continuing rebase..."))
+		if _, err := repo.RunGitCommand("rebase", "--continue"); err != nil {
+			return fmt.Errorf("rebase --continue failed: resolve conflicts and try again")
+		}
+	}
+
+	metadata, err := config.LoadRepoMetadata(repo)
+	if err != nil {
+		return fmt.Errorf("failed to load metadata: %w", err)
+	}
+
+	fmt.Printf("Resuming 'gw %s': %d branch(es) left\n", state.Operation, len(state.Queue))
+
+	// The stopped branch is still first in the queue and is found up to date now
+	if err := runRestackQueue(repo, metadata, state); err != nil {
+		return err
+	}
+
+	if state.OriginalBranch != "" && repo.BranchExists(state.OriginalBranch) {
+		if err := repo.CheckoutBranch(state.OriginalBranch); err != nil {
+			fmt.Printf("%s Could not return to %s: %v\n",
+				colors.Warning("⚠"),
+				colors.BranchCurrent(state.OriginalBranch),
+				err)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("%s All done!\n", colors.Success("✓"))
+	return nil
+}
+
 // isRebaseInProgress checks if a rebase is currently in progress
 func isRebaseInProgress(repo *git.Repo) bool {
 	gitDir := repo.GetGitDir()
@@ -126,70 +180,9 @@ func isRebaseInProgress(repo *git.Repo) bool {
 	return false
 }
 
-// continueRestackChildren rebases children onto parent after a continue
-func continueRestackChildren(repo *git.Repo, s *stack.Stack, parent *stack.Node) error {
-	for _, child := range parent.Children {
-		// Checkout child branch
-		if err := repo.CheckoutBranch(child.Name); err != nil {
-			return fmt.Errorf("failed to checkout '%s': %w", child.Name, err)
-		}
-
-		// Check if needs rebase
-		needsRebase, err := childNeedsRebase(repo, child.Name, parent.Name)
-		if err != nil {
-			return err
-		}
-
-		if !needsRebase {
-			fmt.Printf("%s %s already up to date\n",
-				colors.Success("✓"),
-				colors.BranchCurrent(child.Name))
-		} else {
-			// Perform rebase
-			if _, err := repo.RunGitCommand("rebase", parent.Name, child.Name); err != nil {
-				fmt.Println()
-				fmt.Printf("%s Conflict restacking %s onto %s\n",
-					colors.Warning("⚠"),
-					colors.BranchCurrent(child.Name),
-					colors.BranchParent(parent.Name))
-				fmt.Println()
-				fmt.Println(colors.Muted("To continue:"))
-				fmt.Println(colors.Muted("  1. Resolve conflicts"))
-				fmt.Println(colors.Muted("  2. git add ."))
-				fmt.Println(colors.Muted("  3. gw continue"))
-				fmt.Println()
-				fmt.Println(colors.Muted("To abort: git rebase --abort"))
-				return fmt.Errorf("rebase conflict")
-			}
-
-			fmt.Printf("%s Restacked %s onto %s\n",
-				colors.Success("✓"),
-				colors.BranchCurrent(child.Name),
-				colors.BranchParent(parent.Name))
-		}
-
-		// Recursively restack grandchildren
-		if len(child.Children) > 0 {
-			if err := continueRestackChildren(repo, s, child); err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
-}
-
-// childNeedsRebase checks if a child branch needs rebasing onto parent
-func childNeedsRebase(repo *git.Repo, child, parent string) (bool, error) {
-	mergeBase, err := repo.RunGitCommand("merge-base", child, parent)
-	if err != nil {
-		return false, fmt.Errorf("failed to get merge base: %w", err)
-	}
-
-	parentCommit, err := repo.RunGitCommand("rev-parse", parent)
-	if err != nil {
-		return false, fmt.Errorf("failed to get parent commit: %w", err)
-	}
-
-	return mergeBase != parentCommit, nil
+// continueRestackChildren restacks the descendants of parent after a continue. It
+// goes through the restack queue, so a further conflict can be resumed with
+// 'gw continue' or rolled back with 'gw abort'.
+func continueRestackChildren(repo *git.Repo, metadata *config.Metadata, parent *stack.Node) error {
+	return startRestack(repo, metadata, descendantNames(parent))
 }
@@ -44,29 +44,13 @@ func TestContinueHelpers(t *testing.T) {
 		t.Fatalf("failed to remove rebase apply dir: %v", err)
 	}
 
-	// childNeedsRebase should be false for fresh branch
-	repo.createBranch(t, "feat-child", "main")
-	needs, err := childNeedsRebase(repo.repo, "feat-child", "main")
-	if err != nil {
-		t.Fatalf("childNeedsRebase failed: %v", err)
-	}
-	if needs {
-		t.Fatalf("expected no rebase needed")
-	}
-
 	// continueRestackChildren with no rebase needed
+	repo.createBranch(t, "feat-child", "main")
 	parent := &stack.Node{Name: "main"}
 	child := &stack.Node{Name: "feat-child", Parent: parent}
 	parent.Children = []*stack.Node{child}
-	s := &stack.Stack{
-		Trunk: parent,
-		Nodes: map[string]*stack.Node{
-			"main":       parent,
-			"feat-child": child,
-		},
-	}
 
-	if err := continueRestackChildren(repo.repo, s, parent); err != nil {
+	if err := continueRestackChildren(repo.repo, repo.metadata, parent); err != nil {
 		t.Fatalf("continueRestackChildren failed: %v", err)
 	}
 
@@ -84,14 +68,7 @@ func TestContinueHelpers(t *testing.T) {
 		t.Fatalf("failed to commit: %v", err)
 	}
 
-	s = &stack.Stack{
-		Trunk: parent,
-		Nodes: map[string]*stack.Node{
-			"main":       parent,
-			"feat-child": child,
-		},
-	}
-	if err := continueRestackChildren(repo.repo, s, parent); err != nil {
+	if err := continueRestackChildren(repo.repo, repo.metadata, parent); err != nil {
 		t.Fatalf("continueRestackChildren rebase failed: %v", err)
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/restack"
 	"github.com/israelmalagutti/git-wrapper/internal/stack"
 )
 
@@ -53,7 +54,7 @@ func TestContinueRestackChildrenPaths(t *testing.T) {
 		t.Fatalf("expected parent node")
 	}
 
-	if err := continueRestackChildren(repo.repo, s, parentNode); err != nil {
+	if err := continueRestackChildren(repo.repo, repo.metadata, parentNode); err != nil {
 		t.Fatalf("continueRestackChildren failed: %v", err)
 	}
 }
@@ -73,15 +74,6 @@ func TestRunContinueRebaseContinueFailure(t *testing.T) {
 	}
 }
 
-func TestChildNeedsRebaseError(t *testing.T) {
-	repo := setupCmdTestRepo(t)
-	defer repo.cleanup()
-
-	if _, err := childNeedsRebase(repo.repo, "missing-branch", "main"); err == nil {
-		t.Fatalf("expected childNeedsRebase error")
-	}
-}
-
 func TestContinueRestackChildrenConflict(t *testing.T) {
 	repo := setupCmdTestRepo(t)
 	defer repo.cleanup()
@@ -130,9 +122,16 @@ func TestContinueRestackChildrenConflict(t *testing.T) {
 		t.Fatalf("expected trunk node")
 	}
 
-	if err := continueRestackChildren(repo.repo, s, parent); err == nil {
+	if err := continueRestackChildren(repo.repo, repo.metadata, parent); err == nil {
 		t.Fatalf("expected conflict error")
 	}
+	state, err := restack.Load(repo.repo.GetRestackStatePath())
+	if err != nil || state == nil {
+		t.Fatalf("expected restack state to be kept for gw continue/abort, got %v", err)
+	}
+	if len(state.Queue) == 0 || state.Queue[0] != "feat-conflict" {
+		t.Fatalf("expected feat-conflict to head the queue, got %v", state.Queue)
+	}
 	_, _ = repo.repo.RunGitCommand("rebase", "--abort")
 }
 
@@ -17,6 +17,7 @@ var (
 	createMessage string
 	createAll     bool
 	createPatch   bool
+	createInsert  bool
 )
 
 var createCmd = &cobra.Command{
@@ -31,9 +32,13 @@ Examples:
   gw create feat-auth -m "Add login"     # Create and commit staged changes
   gw create feat-auth -am "Add login"    # Stage all changes and commit
   gw create feat-auth -pm "Add login"    # Interactive patch mode
-  gw create -m "Add login"               # Auto-generate branch name from message`,
+  gw create -m "Add login"               # Auto-generate branch name from message
+  gw create feat-prereq --insert         # Insert between current branch and its children
+
+With --insert the current branch's children are moved onto the new branch
+and restacked. When there are several children you choose which to move.`,
 	Aliases: []string{"c"},
-	RunE:    runCreate,
+	RunE:    withOperationLog("create", runCreate),
 }
 
 func init() {
@@ -41,11 +46,12 @@ func init() {
 	createCmd.Flags().StringVarP(&createMessage, "message", "m", "", "Commit staged changes with this message")
 	createCmd.Flags().BoolVarP(&createAll, "all", "a", false, "Stage all unstaged changes before committing")
 	createCmd.Flags().BoolVarP(&createPatch, "patch", "p", false, "Interactively select hunks to stage")
+	createCmd.Flags().BoolVarP(&createInsert, "insert", "i", false, "Move the current branch's children onto the new branch")
 }
 
 func runCreate(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -91,6 +97,19 @@ func runCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("branch '%s' already exists", branchName)
 	}
 
+	// Choose the children to move before anything is created
+	var insertChildren []string
+	if createInsert {
+		insertChildren, err = selectInsertChildren(repo, currentBranch, branchName)
+		if err != nil {
+			if errors.Is(err, terminal.InterruptErr) {
+				fmt.Println(colors.Muted("Cancelled."))
+				return nil
+			}
+			return err
+		}
+	}
+
 	// Create and checkout the new branch
 	if err := repo.CreateBranch(branchName); err != nil {
 		return err
@@ -101,14 +120,19 @@ func runCreate(cmd *cobra.Command, args []string) error {
 	}
 
 	// Track the branch in metadata
-	metadata, err := config.LoadMetadata(repo.GetMetadataPath())
+	metadata, err := config.LoadRepoMetadata(repo)
 	if err != nil {
 		return fmt.Errorf("failed to load metadata: %w", err)
 	}
 
 	metadata.TrackBranch(branchName, currentBranch)
 
-	if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+	// Record the parent commit the new branch starts from
+	if parentSHA, err := repo.GetBranchCommit(currentBranch); err == nil {
+		_ = metadata.SetParentSHA(branchName, parentSHA)
+	}
+
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
 		return fmt.Errorf("failed to save metadata: %w", err)
 	}
 
@@ -130,7 +154,17 @@ func runCreate(cmd *cobra.Command, args []string) error {
 		_ = repo.CheckoutBranch(currentBranch)
 		_ = repo.DeleteBranch(branchName, true)
 		metadata.UntrackBranch(branchName)
-		_ = metadata.Save(repo.GetMetadataPath())
+		_ = config.SaveRepoMetadata(repo, metadata)
+	}
+
+	// Cancelling a prompt keeps the created branch, but an insert is left unfinished
+	cancelCreate := func() error {
+		fmt.Println(colors.Muted("Cancelled."))
+		if len(insertChildren) > 0 {
+			return fmt.Errorf("created %s, but %s stayed on %s; move them with 'gw move -s <branch> -o %s'",
+				branchName, strings.Join(insertChildren, ", "), currentBranch, branchName)
+		}
+		return nil
 	}
 
 	// Handle commit logic based on changes and flags
@@ -203,8 +237,7 @@ func runCreate(cmd *cobra.Command, args []string) error {
 			action, err := promptHasChanges(hasStaged)
 			if err != nil {
 				if errors.Is(err, terminal.InterruptErr) {
-					fmt.Println(colors.Muted("Cancelled."))
-					return nil
+					return cancelCreate()
 				}
 				return err
 			}
@@ -218,8 +251,7 @@ func runCreate(cmd *cobra.Command, args []string) error {
 				msg, err := promptCommitMessage()
 				if err != nil {
 					if errors.Is(err, terminal.InterruptErr) {
-						fmt.Println(colors.Muted("Cancelled."))
-						return nil
+						return cancelCreate()
 					}
 					return err
 				}
@@ -231,8 +263,7 @@ func runCreate(cmd *cobra.Command, args []string) error {
 				// Prompt for untracked files before patch mode
 				if err := promptTrackUntrackedFiles(repo); err != nil {
 					if errors.Is(err, terminal.InterruptErr) {
-						fmt.Println(colors.Muted("Cancelled."))
-						return nil
+						return cancelCreate()
 					}
 					if errors.Is(err, errNoChangesToCommit) {
 						rollback()
@@ -244,8 +275,7 @@ func runCreate(cmd *cobra.Command, args []string) error {
 				msg, err := promptCommitMessage()
 				if err != nil {
 					if errors.Is(err, terminal.InterruptErr) {
-						fmt.Println(colors.Muted("Cancelled."))
-						return nil
+						return cancelCreate()
 					}
 					return err
 				}
@@ -258,8 +288,7 @@ func runCreate(cmd *cobra.Command, args []string) error {
 				msg, err := promptCommitMessage()
 				if err != nil {
 					if errors.Is(err, terminal.InterruptErr) {
-						fmt.Println(colors.Muted("Cancelled."))
-						return nil
+						return cancelCreate()
 					}
 					return err
 				}
@@ -284,6 +313,10 @@ func runCreate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if len(insertChildren) > 0 {
+		return insertBranch(repo, metadata, branchName, insertChildren)
+	}
+
 	return nil
 }
 
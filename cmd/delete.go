@@ -2,16 +2,17 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/israelmalagutti/git-wrapper/internal/config"
-	"github.com/israelmalagutti/git-wrapper/internal/git"
 	"github.com/israelmalagutti/git-wrapper/internal/stack"
 	"github.com/spf13/cobra"
 )
 
 var (
 	deleteForce bool
+	deleteScope scopeFlags
 )
 
 var deleteCmd = &cobra.Command{
@@ -22,22 +23,29 @@ var deleteCmd = &cobra.Command{
 If no branch is specified, deletes the current branch.
 Prompts for confirmation unless --force is used.
 
+Use --upstack to also delete the branch's descendants, --downstack to also
+delete its ancestors down to trunk, or --stack to delete its whole stack.
+Children of deleted branches that are kept move onto the closest ancestor
+that is kept.
+
 Example:
   gw delete feat-old       # Delete feat-old branch
   gw delete                # Delete current branch (interactive)
-  gw delete -f feat-old    # Delete without confirmation`,
+  gw delete -f feat-old    # Delete without confirmation
+  gw delete --upstack feat-old  # Delete feat-old and everything above it`,
 	Aliases: []string{"d", "remove", "rm"},
-	RunE:    runDelete,
+	RunE:    withOperationLog("delete", runDelete),
 }
 
 func init() {
 	deleteCmd.Flags().BoolVarP(&deleteForce, "force", "f", false, "Delete without confirmation")
+	addScopeFlags(deleteCmd, &deleteScope, "Delete", allScopes...)
 	rootCmd.AddCommand(deleteCmd)
 }
 
 func runDelete(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -49,7 +57,7 @@ func runDelete(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load metadata
-	metadata, err := config.LoadMetadata(repo.GetMetadataPath())
+	metadata, err := config.LoadRepoMetadata(repo)
 	if err != nil {
 		return fmt.Errorf("failed to load metadata: %w", err)
 	}
@@ -139,17 +147,43 @@ func runDelete(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("branch not found in stack")
 	}
 
-	parentBranch, ok := metadata.GetParent(branchToDelete)
-	if !ok {
+	if _, ok := metadata.GetParent(branchToDelete); !ok {
 		return fmt.Errorf("branch has no parent")
 	}
 
+	// Collect the branches in scope, parents first
+	toDelete := scopeBranchNames(s, branchToDelete, deleteScope.resolve(stack.ScopeOnly))
+	deleting := make(map[string]bool, len(toDelete))
+	for _, branch := range toDelete {
+		deleting[branch] = true
+	}
+
+	// Branches left behind move onto their closest ancestor that survives
+	survivingParent := func(branch string) string {
+		parent, _ := metadata.GetParent(branch)
+		for deleting[parent] {
+			parent, _ = metadata.GetParent(parent)
+		}
+		return parent
+	}
+	orphans := []string{}
+	for _, branch := range toDelete {
+		for _, child := range s.GetNode(branch).SortedChildren() {
+			if !deleting[child.Name] {
+				orphans = append(orphans, child.Name)
+			}
+		}
+	}
+
 	// Confirm with user (unless --force)
 	if !deleteForce {
 		message := fmt.Sprintf("Delete branch '%s'?", branchToDelete)
-		if len(deleteNode.Children) > 0 {
-			message = fmt.Sprintf("Delete branch '%s' and restack %d child branch(es) onto '%s'?",
-				branchToDelete, len(deleteNode.Children), parentBranch)
+		if len(toDelete) > 1 {
+			message = fmt.Sprintf("Delete %d branches (%s)?", len(toDelete), strings.Join(toDelete, ", "))
+		}
+		if len(orphans) > 0 {
+			message = strings.TrimSuffix(message, "?") + fmt.Sprintf(" and restack %d child branch(es) onto '%s'?",
+				len(orphans), survivingParent(branchToDelete))
 		}
 
 		confirm := false
@@ -167,55 +201,65 @@ func runDelete(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// If deleting current branch, checkout parent first
-	needToCheckout := (branchToDelete == currentBranch)
+	// If deleting current branch, checkout its surviving ancestor first
+	needToCheckout := deleting[currentBranch]
 	if needToCheckout {
-		fmt.Printf("Checking out '%s'...\n", parentBranch)
-		if err := repo.CheckoutBranch(parentBranch); err != nil {
+		target := survivingParent(currentBranch)
+		fmt.Printf("Checking out '%s'...\n", target)
+		if err := repo.CheckoutBranch(target); err != nil {
 			return fmt.Errorf("failed to checkout parent: %w", err)
 		}
 	}
 
-	// Update children to point to parent
-	if len(deleteNode.Children) > 0 {
-		fmt.Printf("\nUpdating %d child branch(es)...\n", len(deleteNode.Children))
-		for _, child := range deleteNode.Children {
-			if err := metadata.UpdateParent(child.Name, parentBranch); err != nil {
-				return fmt.Errorf("failed to update child '%s': %w", child.Name, err)
+	// Update children to point to their new parent
+	if len(orphans) > 0 {
+		fmt.Printf("\nUpdating %d child branch(es)...\n", len(orphans))
+		for _, child := range orphans {
+			newParent := survivingParent(child)
+			if err := metadata.UpdateParent(child, newParent); err != nil {
+				return fmt.Errorf("failed to update child '%s': %w", child, err)
 			}
-			fmt.Printf("  ✓ Updated '%s' parent to '%s'\n", child.Name, parentBranch)
+			fmt.Printf("  ✓ Updated '%s' parent to '%s'\n", child, newParent)
 		}
 
-		if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+		if err := config.SaveRepoMetadata(repo, metadata); err != nil {
 			return fmt.Errorf("failed to save metadata: %w", err)
 		}
 	}
 
-	// Delete the branch
-	fmt.Printf("\nDeleting branch '%s'...\n", branchToDelete)
-	if _, err := repo.RunGitCommand("branch", "-D", branchToDelete); err != nil {
-		return fmt.Errorf("failed to delete branch: %w", err)
-	}
+	// Delete the branches
+	for _, branch := range toDelete {
+		fmt.Printf("\nDeleting branch '%s'...\n", branch)
+		if _, err := repo.RunGitCommand("branch", "-D", branch); err != nil {
+			return fmt.Errorf("failed to delete branch: %w", err)
+		}
 
-	// Remove from metadata
-	metadata.UntrackBranch(branchToDelete)
-	if err := metadata.Save(repo.GetMetadataPath()); err != nil {
-		return fmt.Errorf("failed to save metadata: %w", err)
-	}
+		// Remove from metadata
+		metadata.UntrackBranch(branch)
+		if err := config.SaveRepoMetadata(repo, metadata); err != nil {
+			return fmt.Errorf("failed to save metadata: %w", err)
+		}
 
-	fmt.Printf("✓ Deleted branch '%s'\n", branchToDelete)
+		fmt.Printf("✓ Deleted branch '%s'\n", branch)
+	}
 
-	// Rebuild stack and restack children
-	if len(deleteNode.Children) > 0 {
+	// Rebuild stack and restack the moved children
+	if len(orphans) > 0 {
 		s, err := stack.BuildStack(repo, cfg, metadata)
 		if err != nil {
 			return fmt.Errorf("failed to rebuild stack: %w", err)
 		}
 
-		parentNode := s.GetNode(parentBranch)
-		if parentNode != nil && len(parentNode.Children) > 0 {
+		branches := []string{}
+		for _, child := range orphans {
+			if node := s.GetNode(child); node != nil {
+				branches = append(branches, child)
+				branches = append(branches, descendantNames(node)...)
+			}
+		}
+		if len(branches) > 0 {
 			fmt.Println("\nRestacking children...")
-			if err := restackChildren(repo, s, parentNode); err != nil {
+			if err := startRestack(repo, metadata, branches); err != nil {
 				return fmt.Errorf("failed to restack children: %w", err)
 			}
 			fmt.Println("✓ Children restacked")
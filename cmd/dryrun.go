@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/forge"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+)
+
+var (
+	// dryRun is set by the root --dry-run flag
+	dryRun bool
+	// dryRunOut receives the planned changes while a dry run is active
+	dryRunOut io.Writer
+)
+
+// startDryRun makes commands print the git commands that would change the
+// repository instead of running them, and keep metadata changes in memory.
+// The returned function reports the metadata changes and ends the dry run.
+func startDryRun(out io.Writer) func() {
+	prevRunner := newGitRunner
+	newGitRunner = func() git.Runner {
+		return &git.DryRunRunner{Runner: prevRunner(), Out: out}
+	}
+	dryRunOut = out
+
+	stop := func() {
+		newGitRunner = prevRunner
+		dryRunOut = nil
+	}
+
+	// Outside a repository there is no metadata; the command reports that itself
+	repo, err := openRepo()
+	if err != nil {
+		return stop
+	}
+	base, err := config.OpenMetadataStore(repo)
+	if err != nil {
+		return stop
+	}
+	store := &config.DryRunStore{Base: base}
+	restoreStore := config.OverrideMetadataStore(repo, store)
+
+	return func() {
+		restoreStore()
+		stop()
+		printDryRunChanges(out, store)
+	}
+}
+
+// printDryRunChanges reports the metadata a dry run would have saved
+func printDryRunChanges(out io.Writer, store *config.DryRunStore) {
+	changes, err := store.Changes()
+	if err != nil {
+		fmt.Fprintf(out, "%s Could not compare metadata: %v\n", colors.Warning("⚠"), err)
+		return
+	}
+
+	fmt.Fprintln(out)
+	if len(changes) == 0 {
+		fmt.Fprintln(out, colors.Muted("Dry run: no metadata changes."))
+		return
+	}
+	fmt.Fprintln(out, colors.BoldText("Dry run: metadata changes"))
+	for _, change := range changes {
+		fmt.Fprintf(out, "  %s\n", change)
+	}
+}
+
+// noDryRun fails commands whose effects a dry run cannot hold back
+func noDryRun(command string) error {
+	if dryRun {
+		return fmt.Errorf("gw %s does not support --dry-run", command)
+	}
+	return nil
+}
+
+// openForge opens the repository's forge, printing pull request changes
+// instead of making them during a dry run
+func openForge(repo *git.Repo, cfg *config.Config) (forge.Provider, error) {
+	provider, err := forge.Open(repo, cfg)
+	if err != nil || dryRunOut == nil {
+		return provider, err
+	}
+	return &forge.DryRunProvider{Provider: provider, Out: dryRunOut}, nil
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDryRunMove(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.commitFile(t, "a.txt", "a", "a commit")
+	repo.createBranch(t, "feat-b", "feat-a")
+	repo.commitFile(t, "b.txt", "b", "b commit")
+
+	tipB, _ := repo.repo.GetBranchCommit("feat-b")
+	metadataBefore, err := os.ReadFile(repo.repo.GetMetadataPath())
+	if err != nil {
+		t.Fatalf("failed to read metadata: %v", err)
+	}
+
+	prevDryRun, prevSource, prevOnto := dryRun, moveSource, moveOnto
+	defer func() { dryRun, moveSource, moveOnto = prevDryRun, prevSource, prevOnto }()
+	dryRun, moveSource, moveOnto = true, "feat-b", "main"
+
+	var out bytes.Buffer
+	finish := startDryRun(&out)
+	err = runMove(nil, nil)
+	finish()
+	if err != nil {
+		t.Fatalf("runMove failed: %v", err)
+	}
+
+	got := out.String()
+	for _, want := range []string{"dry-run: git rebase --onto main", "~ feat-b: parent feat-a → main"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in output:\n%s", want, got)
+		}
+	}
+
+	// Nothing changed, and there is nothing to undo
+	if tip, _ := repo.repo.GetBranchCommit("feat-b"); tip != tipB {
+		t.Fatalf("expected feat-b to stay at %s, got %s", tipB, tip)
+	}
+	if data, _ := os.ReadFile(repo.repo.GetMetadataPath()); !bytes.Equal(data, metadataBefore) {
+		t.Fatalf("expected metadata to be unchanged, got:\n%s", data)
+	}
+	if _, err := os.Stat(repo.repo.GetOperationLogPath()); !os.IsNotExist(err) {
+		t.Fatalf("expected no operation log, got %v", err)
+	}
+
+	// The runner is restored afterwards
+	if err := repo.repo.CheckoutBranch("feat-a"); err != nil {
+		t.Fatalf("failed to checkout feat-a: %v", err)
+	}
+	if current, _ := repo.repo.GetCurrentBranch(); current != "feat-a" {
+		t.Fatalf("expected feat-a, got %s", current)
+	}
+}
+
+func TestDryRunUnsupported(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	prevDryRun := dryRun
+	defer func() { dryRun = prevDryRun }()
+	dryRun = true
+
+	for name, run := range map[string]func() error{
+		"foreach": func() error { return runForeach(nil, []string{"true"}) },
+		"undo":    func() error { return runUndo(nil, nil) },
+	} {
+		if err := run(); err == nil || !strings.Contains(err.Error(), "does not support --dry-run") {
+			t.Errorf("expected %s to refuse --dry-run, got %v", name, err)
+		}
+	}
+}
+
+func TestDryRunSyncLeavesNoRestackState(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.commitFile(t, "shared.txt", "feat", "feat commit")
+	if err := repo.repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	repo.commitFile(t, "shared.txt", "main", "main commit")
+
+	prevDryRun, prevForce, prevRestack := dryRun, syncForce, syncRestack
+	defer func() { dryRun, syncForce, syncRestack = prevDryRun, prevForce, prevRestack }()
+	dryRun, syncForce, syncRestack = true, true, true
+
+	var out bytes.Buffer
+	finish := startDryRun(&out)
+	err := runSync(nil, nil)
+	finish()
+	if err != nil {
+		t.Fatalf("runSync failed: %v", err)
+	}
+
+	if _, err := os.Stat(repo.repo.GetRestackStatePath()); !os.IsNotExist(err) {
+		t.Fatalf("expected no restack state after a dry run, got %v", err)
+	}
+}
+
+func TestDryRunSubmitLeavesPRCache(t *testing.T) {
+	repo, server := setupSubmitRepo(t)
+
+	if err := runCreate(nil, []string{"feat-a"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if _, err := repo.RunGitCommand("commit", "--allow-empty", "-m", "Add feat-a"); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	prevDryRun, prevScope := dryRun, submitScope
+	defer func() { dryRun, submitScope = prevDryRun, prevScope }()
+	dryRun, submitScope = true, scopeFlags{}
+
+	var out bytes.Buffer
+	finish := startDryRun(&out)
+	err := runSubmit(nil, nil)
+	finish()
+	if err != nil {
+		t.Fatalf("runSubmit failed: %v", err)
+	}
+
+	if len(server.Pulls()) != 0 {
+		t.Fatalf("expected no pull requests, got %+v", server.Pulls())
+	}
+	if _, err := os.Stat(repo.GetPRCachePath()); !os.IsNotExist(err) {
+		t.Fatalf("expected no pull request cache after a dry run, got %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+)
+
+// openEditor opens path in the user's editor and waits for it to exit. It is
+// a test seam, like askOne.
+var openEditor = func(repo *git.Repo, path string) error {
+	// git var resolves GIT_EDITOR, core.editor, VISUAL and EDITOR the way git does
+	editor, err := repo.RunGitCommand("var", "GIT_EDITOR")
+	if err != nil {
+		return fmt.Errorf("failed to find an editor: %w", err)
+	}
+
+	// The editor itself is not git, so it runs here the way git would start it
+	cmd := exec.CommandContext(repo.Context(), "sh", "-c", editor+` "$@"`, "editor", path)
+	cmd.Dir = repo.GetWorkDir()
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor failed: %w", err)
+	}
+	return nil
+}
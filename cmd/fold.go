@@ -5,7 +5,6 @@ import (
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/israelmalagutti/git-wrapper/internal/config"
-	"github.com/israelmalagutti/git-wrapper/internal/git"
 	"github.com/israelmalagutti/git-wrapper/internal/stack"
 	"github.com/spf13/cobra"
 )
@@ -31,7 +30,7 @@ This is useful when you realize a branch should have been part of its parent.
 Example:
   gw fold           # Fold into parent, delete current branch
   gw fold --keep    # Fold into parent, keep current branch name`,
-	RunE: runFold,
+	RunE: withOperationLog("fold", runFold),
 }
 
 func init() {
@@ -42,7 +41,7 @@ func init() {
 
 func runFold(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -54,7 +53,7 @@ func runFold(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load metadata
-	metadata, err := config.LoadMetadata(repo.GetMetadataPath())
+	metadata, err := config.LoadRepoMetadata(repo)
 	if err != nil {
 		return fmt.Errorf("failed to load metadata: %w", err)
 	}
@@ -153,7 +152,7 @@ func runFold(cmd *cobra.Command, args []string) error {
 			fmt.Printf("  ✓ Updated '%s' parent to '%s'\n", child.Name, parentBranch)
 		}
 
-		if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+		if err := config.SaveRepoMetadata(repo, metadata); err != nil {
 			return fmt.Errorf("failed to save metadata: %w", err)
 		}
 	}
@@ -161,7 +160,7 @@ func runFold(cmd *cobra.Command, args []string) error {
 	// Remove current branch from metadata (unless --keep)
 	if !foldKeep {
 		metadata.UntrackBranch(currentBranch)
-		if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+		if err := config.SaveRepoMetadata(repo, metadata); err != nil {
 			return fmt.Errorf("failed to save metadata: %w", err)
 		}
 
@@ -185,7 +184,7 @@ func runFold(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+		if err := config.SaveRepoMetadata(repo, metadata); err != nil {
 			return fmt.Errorf("failed to save metadata: %w", err)
 		}
 
@@ -202,7 +201,7 @@ func runFold(cmd *cobra.Command, args []string) error {
 	parentNode := s.GetNode(parentBranch)
 	if parentNode != nil && len(parentNode.Children) > 0 {
 		fmt.Println("\nRestacking children...")
-		if err := restackChildren(repo, s, parentNode); err != nil {
+		if err := restackChildren(repo, s, metadata, parentNode); err != nil {
 			return fmt.Errorf("failed to restack children: %w", err)
 		}
 		fmt.Println("✓ Children restacked")
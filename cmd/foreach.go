@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/executor"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/runcache"
+	"github.com/israelmalagutti/git-wrapper/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+var (
+	foreachScope    scopeFlags
+	foreachFailFast bool
+	foreachNoCache  bool
+	foreachJobs     int
+)
+
+var foreachCmd = &cobra.Command{
+	Use:     "foreach [flags] [--] <command> [args...]",
+	Aliases: []string{"test"},
+	Short:   "Run a command on every branch of the stack",
+	Long: `Check out each tracked branch in turn, parents first, and run a command
+on it, e.g. the test suite before submitting. A summary of which branches
+passed and failed is shown on the stack tree afterwards, and the original
+branch is checked out again.
+
+A single argument is run by the shell, so it can hold pipes and &&; several
+arguments are run as they are. The branch name is in $GW_BRANCH.
+
+Passing results are cached by command and commit, so branches whose commit
+already passed are not run again; use --no-cache to run them anyway. Failures
+are never cached, so a flaky or environmental failure is always retried.
+
+With --jobs N, each branch is checked out in its own temporary worktree
+instead and N branches run at once, with every line of output prefixed by
+its branch. The worktrees are created in the repository's git directory and
+removed afterwards; local changes in the working tree are left alone.
+
+Use --only, --upstack, --downstack or --stack to run on part of the tree
+around the current branch. Exits with an error if any branch failed.
+
+Examples:
+  gw foreach -- go test ./...
+  gw test --stack --fail-fast -- "make lint && make test"
+  gw foreach -j 4 -- go test ./...`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runForeach,
+}
+
+func init() {
+	rootCmd.AddCommand(foreachCmd)
+	// Flags after the command belong to it, not to gw
+	foreachCmd.Flags().SetInterspersed(false)
+	addScopeFlags(foreachCmd, &foreachScope, "Run on", allScopes...)
+	foreachCmd.Flags().BoolVar(&foreachFailFast, "fail-fast", false, "Stop at the first branch that fails")
+	foreachCmd.Flags().BoolVar(&foreachNoCache, "no-cache", false, "Run on every branch, even if its commit already passed")
+	foreachCmd.Flags().IntVarP(&foreachJobs, "jobs", "j", 0, "Run on N branches at once, each in a temporary worktree")
+}
+
+// foreachResult is the outcome of the command on one branch
+type foreachResult struct {
+	exitCode int
+	cached   bool
+	skipped  bool
+	err      error // the command could not be started
+}
+
+func (r *foreachResult) passed() bool {
+	return !r.skipped && r.err == nil && r.exitCode == 0
+}
+
+func runForeach(cmd *cobra.Command, args []string) error {
+	if err := noDryRun("foreach"); err != nil {
+		return err
+	}
+
+	if foreachJobs < 0 {
+		return fmt.Errorf("--jobs must be at least 1")
+	}
+
+	// Initialize repository
+	repo, err := openRepo()
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	// Load config
+	cfg, err := config.Load(repo.GetConfigPath())
+	if err != nil {
+		return err
+	}
+
+	// Load metadata
+	metadata, err := config.LoadRepoMetadata(repo)
+	if err != nil {
+		return fmt.Errorf("failed to load metadata: %w", err)
+	}
+
+	// Build stack
+	s, err := stack.BuildStack(repo, cfg, metadata)
+	if err != nil {
+		return fmt.Errorf("failed to build stack: %w", err)
+	}
+
+	currentBranch, err := repo.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	var branches []string
+	if foreachScope.isSet() {
+		branches = scopeBranchNames(s, currentBranch, foreachScope.resolve(stack.ScopeStack))
+	} else {
+		branches = nodeNames(s.GetTopologicalOrder())
+	}
+	if len(branches) == 0 {
+		return fmt.Errorf("no branches to run on")
+	}
+
+	cachePath := repo.GetRunCachePath()
+	cache, err := runcache.Load(cachePath)
+	if err != nil {
+		return err
+	}
+	command := runcache.Key(args)
+
+	// Checking out branches would carry local changes along or fail; worktrees
+	// start from the commits, so they are not affected
+	if foreachJobs == 0 && hasTrackedChanges(repo) {
+		return fmt.Errorf("you have uncommitted changes; commit or stash them before running gw foreach, or use --jobs")
+	}
+
+	results := map[string]*foreachResult{}
+	var jobs []executor.Job
+	for _, branch := range branches {
+		sha, err := repo.GetBranchCommit(branch)
+		if err != nil {
+			return err
+		}
+
+		if !foreachNoCache {
+			// Failures recorded by older versions are run again
+			if cached := cache.Get(command, sha); cached != nil && cached.Passed() {
+				results[branch] = &foreachResult{exitCode: cached.ExitCode, cached: true}
+				fmt.Printf("%s %s %s\n", colors.Muted("•"), colors.BranchCurrent(branch), formatForeachResult(results[branch]))
+				continue
+			}
+		}
+		jobs = append(jobs, executor.Job{Branch: branch, Commit: sha})
+	}
+
+	// Record each result as it comes in, so an interrupted run keeps what finished
+	onDone := func(r executor.Result) {
+		result := &foreachResult{exitCode: r.ExitCode, skipped: r.Skipped, err: r.Err}
+		results[r.Job.Branch] = result
+		if r.Skipped {
+			return
+		}
+		fmt.Printf("%s %s %s\n", colors.Muted("•"), colors.BranchCurrent(r.Job.Branch), formatForeachResult(result))
+
+		// Only passes are cached: a failure may be flaky or come from the environment
+		if result.passed() {
+			cache.Put(command, r.Job.Commit, &runcache.Result{ExitCode: r.ExitCode, Ran: time.Now()})
+			if err := cache.Save(cachePath); err != nil {
+				fmt.Printf("%s Could not save results: %v\n", colors.Warning("⚠"), err)
+			}
+		}
+	}
+
+	if foreachJobs > 0 && len(jobs) > 0 {
+		fmt.Printf("%s Running on %d branch(es) in temporary worktrees, %d at a time\n", colors.Info("▶"), len(jobs), foreachJobs)
+		e := &executor.Executor{
+			Repo:     repo,
+			Command:  args,
+			Jobs:     foreachJobs,
+			FailFast: foreachFailFast,
+			Out:      os.Stdout,
+			OnDone:   onDone,
+		}
+		if _, err := e.Run(jobs); err != nil {
+			return err
+		}
+		fmt.Println()
+	} else {
+		err := runForeachInPlace(repo, jobs, args, onDone)
+		restoreForeachBranch(repo, currentBranch)
+		if err != nil {
+			return err
+		}
+	}
+
+	return printForeachSummary(repo, s, branches, results)
+}
+
+// runForeachInPlace checks out each job's branch in the working tree and runs args on it
+func runForeachInPlace(repo *git.Repo, jobs []executor.Job, args []string, onDone func(executor.Result)) error {
+	failed := false
+	for _, job := range jobs {
+		if (failed && foreachFailFast) || repo.Context().Err() != nil {
+			onDone(executor.Result{Job: job, Skipped: true})
+			continue
+		}
+
+		fmt.Printf("%s Running on %s %s\n", colors.Info("▶"), colors.BranchCurrent(job.Branch), colors.CommitSHA(job.Commit[:7]))
+		if err := repo.CheckoutBranch(job.Branch); err != nil {
+			return err
+		}
+
+		code, err := executor.RunCommand(repo.Context(), repo.GetWorkDir(), job.Branch, args, os.Stdout, os.Stderr)
+		result := executor.Result{Job: job, ExitCode: code, Err: err}
+		onDone(result)
+		fmt.Println()
+		failed = failed || !result.Passed()
+	}
+	return nil
+}
+
+// restoreForeachBranch checks out the branch gw foreach started on
+func restoreForeachBranch(repo *git.Repo, branch string) {
+	if current, err := repo.GetCurrentBranch(); err == nil && current == branch {
+		return
+	}
+	if err := repo.CheckoutBranch(branch); err != nil {
+		fmt.Printf("%s Could not return to %s: %v\n", colors.Warning("⚠"), branch, err)
+	}
+}
+
+// formatForeachResult renders a result as "✓ passed", "✗ failed (exit 1)" or "skipped"
+func formatForeachResult(r *foreachResult) string {
+	var text string
+	switch {
+	case r.skipped:
+		return colors.Muted("skipped")
+	case r.err != nil:
+		text = colors.Error(fmt.Sprintf("✗ could not run: %v", r.err))
+	case r.exitCode == 0:
+		text = colors.Success("✓ passed")
+	default:
+		text = colors.Error(fmt.Sprintf("✗ failed (exit %d)", r.exitCode))
+	}
+	if r.cached {
+		text += colors.Muted(" (cached)")
+	}
+	return text
+}
+
+// printForeachSummary shows the results on the stack tree and fails if any branch failed
+func printForeachSummary(repo *git.Repo, s *stack.Stack, branches []string, results map[string]*foreachResult) error {
+	passed, failed, skipped := 0, 0, 0
+	s.Annotations = map[string]string{}
+	for _, branch := range branches {
+		result := results[branch]
+		s.Annotations[branch] = formatForeachResult(result)
+		switch {
+		case result.skipped:
+			skipped++
+		case result.passed():
+			passed++
+		default:
+			failed++
+		}
+	}
+
+	fmt.Println(colors.BoldText("Results:"))
+	fmt.Print(s.RenderTree(repo, stack.TreeOptions{ShowCommitSHA: true}))
+
+	summary := fmt.Sprintf("%d passed, %d failed", passed, failed)
+	if skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped", skipped)
+	}
+	fmt.Println(summary)
+
+	if failed > 0 {
+		return fmt.Errorf("%d branch(es) failed", failed)
+	}
+	return nil
+}
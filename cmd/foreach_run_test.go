@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+	"github.com/israelmalagutti/git-wrapper/internal/runcache"
+)
+
+// foreachLog returns a command that appends $GW_BRANCH to a log outside the
+// repository before running check, and a function reading the log back
+func foreachLog(t *testing.T, check string) (string, func() string) {
+	t.Helper()
+	log := filepath.Join(t.TempDir(), "ran")
+	read := func() string {
+		data, _ := os.ReadFile(log)
+		return strings.TrimSpace(string(data))
+	}
+	return `echo "$GW_BRANCH" >> "` + log + `" && ` + check, read
+}
+
+func TestRunForeach(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.commitFile(t, "a.txt", "a", "a commit")
+	repo.createBranch(t, "feat-b", "feat-a")
+	repo.commitFile(t, "b.txt", "b", "b commit")
+	repo.createBranch(t, "feat-c", "main")
+	repo.commitFile(t, "c.txt", "c", "c commit")
+	if err := repo.repo.CheckoutBranch("feat-b"); err != nil {
+		t.Fatalf("failed to checkout feat-b: %v", err)
+	}
+
+	prevFailFast, prevNoCache := foreachFailFast, foreachNoCache
+	defer func() { foreachFailFast, foreachNoCache = prevFailFast, prevNoCache }()
+	foreachFailFast, foreachNoCache = false, false
+
+	command, ran := foreachLog(t, "test -f a.txt")
+	err := runForeach(nil, []string{command})
+	if err == nil || !strings.Contains(err.Error(), "1 branch(es) failed") {
+		t.Fatalf("expected feat-c to fail, got %v", err)
+	}
+	if got := ran(); got != "feat-a\nfeat-b\nfeat-c" {
+		t.Fatalf("expected every branch to run parents first, got %q", got)
+	}
+	if current, _ := repo.repo.GetCurrentBranch(); current != "feat-b" {
+		t.Fatalf("expected to return to feat-b, got %s", current)
+	}
+
+	cache, err := runcache.Load(repo.repo.GetRunCachePath())
+	if err != nil {
+		t.Fatalf("failed to load cache: %v", err)
+	}
+	shaA, _ := repo.repo.GetBranchCommit("feat-a")
+	shaC, _ := repo.repo.GetBranchCommit("feat-c")
+	if r := cache.Get(runcache.Key([]string{command}), shaA); r == nil || !r.Passed() {
+		t.Fatalf("expected feat-a to be cached as passed, got %+v", r)
+	}
+	// Failures are not cached, since they may be flaky
+	if r := cache.Get(runcache.Key([]string{command}), shaC); r != nil {
+		t.Fatalf("expected feat-c's failure not to be cached, got %+v", r)
+	}
+
+	// Passing commits are not run again; the failing one is retried
+	if err := runForeach(nil, []string{command}); err == nil {
+		t.Fatalf("expected feat-c to fail again")
+	}
+	if got := ran(); got != "feat-a\nfeat-b\nfeat-c\nfeat-c" {
+		t.Fatalf("expected only feat-c to run again, got %q", got)
+	}
+
+	// A new commit runs again
+	repo.commitFile(t, "b2.txt", "b2", "b2 commit")
+	_ = runForeach(nil, []string{command})
+	if got := ran(); got != "feat-a\nfeat-b\nfeat-c\nfeat-c\nfeat-b\nfeat-c" {
+		t.Fatalf("expected feat-b and feat-c to run again, got %q", got)
+	}
+}
+
+func TestRunForeachFailFastAndScope(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.commitFile(t, "a.txt", "a", "a commit")
+	repo.createBranch(t, "feat-b", "feat-a")
+	repo.commitFile(t, "b.txt", "b", "b commit")
+	repo.createBranch(t, "feat-c", "main")
+	repo.commitFile(t, "c.txt", "c", "c commit")
+
+	prevFailFast, prevNoCache, prevScope := foreachFailFast, foreachNoCache, foreachScope
+	defer func() { foreachFailFast, foreachNoCache, foreachScope = prevFailFast, prevNoCache, prevScope }()
+	foreachFailFast, foreachNoCache = true, true
+
+	command, ran := foreachLog(t, `test "$GW_BRANCH" != feat-a`)
+	if err := runForeach(nil, []string{command}); err == nil {
+		t.Fatalf("expected feat-a to fail")
+	}
+	if got := ran(); got != "feat-a" {
+		t.Fatalf("expected to stop after feat-a, got %q", got)
+	}
+
+	// Only the current branch's stack
+	foreachFailFast = false
+	foreachScope = scopeFlags{stack: true}
+	command, ran = foreachLog(t, "true")
+	if err := runForeach(nil, []string{command}); err != nil {
+		t.Fatalf("runForeach failed: %v", err)
+	}
+	if got := ran(); got != "feat-c" {
+		t.Fatalf("expected only feat-c to run, got %q", got)
+	}
+}
+
+func TestRunForeachDirtyWorktree(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.commitFile(t, "a.txt", "a", "a commit")
+	if err := os.WriteFile(filepath.Join(repo.dir, "a.txt"), []byte("changed"), 0644); err != nil {
+		t.Fatalf("failed to write a.txt: %v", err)
+	}
+
+	if err := runForeach(nil, []string{"true"}); err == nil || !strings.Contains(err.Error(), "uncommitted changes") {
+		t.Fatalf("expected uncommitted changes error, got %v", err)
+	}
+}
+
+func TestRunForeachJobs(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.commitFile(t, "a.txt", "a", "a commit")
+	repo.createBranch(t, "feat-b", "feat-a")
+	repo.commitFile(t, "b.txt", "b", "b commit")
+	repo.createBranch(t, "feat-c", "main")
+	repo.commitFile(t, "c.txt", "c", "c commit")
+
+	// Local changes stay where they are while the worktrees run
+	if err := os.WriteFile(filepath.Join(repo.dir, "c.txt"), []byte("changed"), 0644); err != nil {
+		t.Fatalf("failed to write c.txt: %v", err)
+	}
+
+	prevFailFast, prevNoCache, prevJobs := foreachFailFast, foreachNoCache, foreachJobs
+	defer func() { foreachFailFast, foreachNoCache, foreachJobs = prevFailFast, prevNoCache, prevJobs }()
+	foreachFailFast, foreachNoCache, foreachJobs = false, false, 2
+
+	command, ran := foreachLog(t, `test "$(pwd)" != "`+repo.dir+`" && test -f a.txt`)
+	err := runForeach(nil, []string{command})
+	if err == nil || !strings.Contains(err.Error(), "1 branch(es) failed") {
+		t.Fatalf("expected feat-c to fail, got %v", err)
+	}
+	branches := strings.Split(ran(), "\n")
+	sort.Strings(branches)
+	if strings.Join(branches, " ") != "feat-a feat-b feat-c" {
+		t.Fatalf("expected every branch to run, got %v", branches)
+	}
+
+	if current, _ := repo.repo.GetCurrentBranch(); current != "feat-c" {
+		t.Fatalf("expected feat-c to stay checked out, got %s", current)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repo.dir, "c.txt")); string(data) != "changed" {
+		t.Fatalf("expected local changes to be kept, got %q", data)
+	}
+	if list, _ := repo.repo.RunGitCommand("worktree", "list"); strings.Count(list, "\n") != 0 {
+		t.Fatalf("expected the worktrees to be removed, got:\n%s", list)
+	}
+
+	// Results are cached like a sequential run
+	cache, err := runcache.Load(repo.repo.GetRunCachePath())
+	if err != nil {
+		t.Fatalf("failed to load cache: %v", err)
+	}
+	shaB, _ := repo.repo.GetBranchCommit("feat-b")
+	if result := cache.Get(runcache.Key([]string{command}), shaB); result == nil || !result.Passed() {
+		t.Fatalf("expected a passing cached result for feat-b, got %+v", result)
+	}
+
+	foreachJobs = -1
+	if err := runForeach(nil, []string{"true"}); err == nil || !strings.Contains(err.Error(), "--jobs") {
+		t.Fatalf("expected --jobs error, got %v", err)
+	}
+}
+
+func TestFormatForeachResult(t *testing.T) {
+	prevColors := colors.IsEnabled()
+	defer colors.SetEnabled(prevColors)
+	colors.SetEnabled(false)
+
+	for _, tc := range []struct {
+		result *foreachResult
+		want   string
+	}{
+		{&foreachResult{}, "✓ passed"},
+		{&foreachResult{cached: true}, "✓ passed (cached)"},
+		{&foreachResult{exitCode: 2}, "✗ failed (exit 2)"},
+		{&foreachResult{skipped: true}, "skipped"},
+	} {
+		if got := formatForeachResult(tc.result); got != tc.want {
+			t.Errorf("formatForeachResult(%+v) = %q, want %q", tc.result, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/metasync"
+	"github.com/spf13/cobra"
+)
+
+var getForce bool
+
+var getCmd = &cobra.Command{
+	Use:   "get <branch>",
+	Short: "Check out a branch and its downstack from the remote",
+	Long: `Fetch a remote branch together with every ancestor between it and trunk,
+create or fast-forward the local branches and track them with gw.
+
+Parents are read from the stack metadata published with 'gw metadata push'.
+Branches without published metadata get the closest remote branch they are
+built on as parent.
+
+Local branches that have diverged from the remote are left alone and the
+command fails, unless --force resets them to the remote.
+
+Example:
+  gw get feat-auth-ui       # Check out feat-auth-ui and its downstack
+  gw get feat-auth-ui -f    # Overwrite diverged local branches`,
+	Args: cobra.ExactArgs(1),
+	RunE: withOperationLog("get", runGet),
+}
+
+func init() {
+	getCmd.Flags().BoolVarP(&getForce, "force", "f", false, "Reset diverged local branches to the remote")
+	rootCmd.AddCommand(getCmd)
+}
+
+// getAction describes what gw get does to a local branch
+type getAction int
+
+const (
+	getCreate getAction = iota
+	getUpToDate
+	getFastForward
+	getAhead
+	getReset
+)
+
+// getBranch is one branch of the stack being fetched
+type getBranch struct {
+	name   string
+	parent string
+	remote string
+	action getAction
+}
+
+func runGet(cmd *cobra.Command, args []string) error {
+	target := args[0]
+	remote := "origin"
+
+	// Initialize repository
+	repo, err := openRepo()
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	// Load config
+	cfg, err := config.Load(repo.GetConfigPath())
+	if err != nil {
+		return err
+	}
+
+	if target == cfg.Trunk {
+		return fmt.Errorf("'%s' is the trunk branch; use 'gw sync' to update it", target)
+	}
+
+	fmt.Printf("Fetching from %s...\n", remote)
+	if err := repo.Fetch(); err != nil {
+		return fmt.Errorf("failed to fetch: %w", err)
+	}
+	if !repo.HasRemoteBranch(target, remote) {
+		return fmt.Errorf("branch '%s' does not exist on %s", target, remote)
+	}
+
+	// Merge published metadata so the stack's parents are known locally
+	if err := fetchMetadata(repo, cfg, remote, metasync.StrategyOurs); err != nil {
+		return err
+	}
+	published, err := (&config.RefStore{Repo: repo, Prefix: metasync.TrackingPrefix(remote)}).Load()
+	if err != nil {
+		return err
+	}
+
+	chain, err := resolveRemoteStack(repo, cfg.Trunk, remote, target, published)
+	if err != nil {
+		return err
+	}
+
+	// Check every branch before touching any of them
+	diverged := []string{}
+	for _, b := range chain {
+		if err := planGetBranch(repo, b); err != nil {
+			return err
+		}
+		if b.action == getReset {
+			diverged = append(diverged, b.name)
+		}
+	}
+	if len(diverged) > 0 && !getForce {
+		return fmt.Errorf("local branches have diverged from %s: %s\nUse --force to reset them to the remote",
+			remote, strings.Join(diverged, ", "))
+	}
+
+	// Resetting the checked-out branch must not discard uncommitted work
+	if current, _ := repo.GetCurrentBranch(); slices.Contains(diverged, current) {
+		changed, err := repo.ChangedFiles()
+		if err != nil {
+			return err
+		}
+		if len(changed) > 0 {
+			return fmt.Errorf("cannot reset %s: it has uncommitted changes in:\n  %s\ncommit or stash them and try again",
+				current, strings.Join(changed, "\n  "))
+		}
+	}
+
+	metadata, err := config.LoadRepoMetadata(repo)
+	if err != nil {
+		return fmt.Errorf("failed to load metadata: %w", err)
+	}
+
+	fmt.Println()
+	for _, b := range chain {
+		if err := applyGetBranch(repo, b); err != nil {
+			return err
+		}
+		if err := trackGetBranch(repo, metadata, b, published); err != nil {
+			return err
+		}
+		printGetBranch(b, remote)
+	}
+
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
+		return fmt.Errorf("failed to save metadata: %w", err)
+	}
+
+	if err := repo.CheckoutBranch(target); err != nil {
+		return err
+	}
+	colors.PrintCheckout(target)
+	return nil
+}
+
+// resolveRemoteStack returns target and its remote ancestors down to trunk, bottom first
+func resolveRemoteStack(repo *git.Repo, trunk, remote, target string, published *config.Metadata) ([]*getBranch, error) {
+	chain := []*getBranch{}
+	seen := map[string]bool{}
+
+	for branch := target; branch != trunk; {
+		if seen[branch] {
+			return nil, fmt.Errorf("stack metadata for '%s' contains a cycle", target)
+		}
+		seen[branch] = true
+
+		parent, ok := published.GetParent(branch)
+		if !ok || (parent != trunk && !repo.HasRemoteBranch(parent, remote)) {
+			inferred, err := inferRemoteParent(repo, trunk, remote, branch)
+			if err != nil {
+				return nil, err
+			}
+			parent = inferred
+		}
+
+		chain = append([]*getBranch{{name: branch, parent: parent, remote: remote + "/" + branch}}, chain...)
+		branch = parent
+	}
+
+	return chain, nil
+}
+
+// inferRemoteParent picks the remote branch (or trunk) that branch is built on most closely
+func inferRemoteParent(repo *git.Repo, trunk, remote, branch string) (string, error) {
+	remoteRef := remote + "/" + branch
+
+	trunkRef := remote + "/" + trunk
+	if !repo.HasRemoteBranch(trunk, remote) {
+		trunkRef = trunk
+	}
+	best := trunk
+	bestDistance, err := commitDistance(repo, trunkRef, remoteRef)
+	if err != nil {
+		return "", err
+	}
+
+	refs, err := repo.ListRefs("refs/remotes/" + remote + "/")
+	if err != nil {
+		return "", err
+	}
+	tip := refs["refs/remotes/"+remoteRef]
+
+	for ref, sha := range refs {
+		name := strings.TrimPrefix(ref, "refs/remotes/"+remote+"/")
+		if name == branch || name == trunk || name == "HEAD" || sha == tip {
+			continue
+		}
+		// Skip branches already merged into trunk and branches not below this one
+		if repo.IsAncestor(sha, trunkRef) || !repo.IsAncestor(sha, remoteRef) {
+			continue
+		}
+
+		distance, err := commitDistance(repo, sha, remoteRef)
+		if err != nil {
+			return "", err
+		}
+		if distance < bestDistance || (distance == bestDistance && name < best && best != trunk) {
+			best, bestDistance = name, distance
+		}
+	}
+
+	return best, nil
+}
+
+// commitDistance counts the commits on to that are not on from
+func commitDistance(repo *git.Repo, from, to string) (int, error) {
+	output, err := repo.RunGitCommand("rev-list", "--count", from+".."+to)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits: %w", err)
+	}
+	return strconv.Atoi(output)
+}
+
+// planGetBranch decides how the local branch relates to the remote one
+func planGetBranch(repo *git.Repo, b *getBranch) error {
+	if !repo.BranchExists(b.name) {
+		b.action = getCreate
+		return nil
+	}
+
+	local, err := repo.GetBranchCommit(b.name)
+	if err != nil {
+		return err
+	}
+	remote, err := repo.GetBranchCommit(b.remote)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case local == remote:
+		b.action = getUpToDate
+	case repo.IsAncestor(local, remote):
+		b.action = getFastForward
+	case repo.IsAncestor(remote, local):
+		b.action = getAhead
+	default:
+		b.action = getReset
+	}
+	return nil
+}
+
+// applyGetBranch creates or moves the local branch to match the plan
+func applyGetBranch(repo *git.Repo, b *getBranch) error {
+	switch b.action {
+	case getCreate:
+		if _, err := repo.RunGitCommand("branch", "--track", b.name, b.remote); err != nil {
+			return fmt.Errorf("failed to create %s: %w", b.name, err)
+		}
+	case getFastForward, getReset:
+		current, _ := repo.GetCurrentBranch()
+		if current == b.name {
+			if b.action == getFastForward {
+				_, err := repo.RunGitCommand("merge", "--ff-only", b.remote)
+				return err
+			}
+			return resetKeep(repo, b.name, b.remote)
+		}
+
+		local, err := repo.GetBranchCommit(b.name)
+		if err != nil {
+			return err
+		}
+		remote, err := repo.GetBranchCommit(b.remote)
+		if err != nil {
+			return err
+		}
+		return repo.UpdateRefs([]git.RefUpdate{{Ref: "refs/heads/" + b.name, OldSHA: local, NewSHA: remote}})
+	}
+	return nil
+}
+
+// trackGetBranch records the branch's parent, preferring the published record
+func trackGetBranch(repo *git.Repo, metadata *config.Metadata, b *getBranch, published *config.Metadata) error {
+	if parent, ok := metadata.GetParent(b.name); ok && parent == b.parent {
+		return nil
+	}
+
+	created := metadata.Branches[b.name]
+	metadata.TrackBranch(b.name, b.parent)
+	if created != nil {
+		metadata.Branches[b.name].Created = created.Created
+	}
+
+	base := ""
+	if record, ok := published.Branches[b.name]; ok && record.Parent == b.parent {
+		base = record.ParentSHA
+	}
+	if base == "" || !repo.IsAncestor(base, b.name) {
+		mergeBase, err := repo.GetMergeBase(b.name, b.parent)
+		if err != nil {
+			return err
+		}
+		base = mergeBase
+	}
+	return metadata.SetParentSHA(b.name, base)
+}
+
+// printGetBranch reports what happened to one branch
+func printGetBranch(b *getBranch, remote string) {
+	name := colors.BranchCurrent(b.name)
+	parent := colors.BranchParent(b.parent)
+	switch b.action {
+	case getCreate:
+		fmt.Printf("%s Created %s from %s (parent %s)\n", colors.Success("✓"), name, remote, parent)
+	case getFastForward:
+		fmt.Printf("%s Fast-forwarded %s (parent %s)\n", colors.Success("✓"), name, parent)
+	case getReset:
+		fmt.Printf("%s Reset %s to %s (parent %s)\n", colors.Warning("⚠"), name, b.remote, parent)
+	case getAhead:
+		fmt.Printf("%s %s is ahead of %s, keeping local commits (parent %s)\n", colors.Info("•"), name, remote, parent)
+	default:
+		fmt.Printf("%s %s is up to date (parent %s)\n", colors.Success("✓"), name, parent)
+	}
+}
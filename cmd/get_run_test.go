@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/metasync"
+)
+
+func TestRunGet(t *testing.T) {
+	localDir, otherDir, cleanup := setupRepoWithRemote(t)
+	defer cleanup()
+
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get cwd: %v", err)
+	}
+	defer os.Chdir(origDir)
+
+	prevForce := getForce
+	prevRemote, prevStrategy := metadataRemote, metadataStrategy
+	defer func() {
+		getForce = prevForce
+		metadataRemote, metadataStrategy = prevRemote, prevStrategy
+	}()
+	getForce = false
+	metadataRemote, metadataStrategy = "origin", metasync.StrategyOurs
+
+	openGw := func(dir string) *git.Repo {
+		t.Helper()
+		if err := os.Chdir(dir); err != nil {
+			t.Fatalf("failed to chdir: %v", err)
+		}
+		repo, err := git.NewRepo()
+		if err != nil {
+			t.Fatalf("failed to open repo: %v", err)
+		}
+		if !config.IsInitialized(repo.GetConfigPath()) {
+			if err := config.NewConfig("main").Save(repo.GetConfigPath()); err != nil {
+				t.Fatalf("failed to save config: %v", err)
+			}
+		}
+		return repo
+	}
+	commit := func(repo *git.Repo, message string) {
+		t.Helper()
+		if _, err := repo.RunGitCommand("commit", "--allow-empty", "-m", message); err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+	}
+
+	// The author publishes feat-a -> feat-b with metadata, and feat-c without it
+	local := openGw(localDir)
+	for _, branch := range []string{"feat-a", "feat-b"} {
+		if err := runCreate(nil, []string{branch}); err != nil {
+			t.Fatalf("create %s failed: %v", branch, err)
+		}
+		commit(local, branch)
+	}
+	if _, err := local.RunGitCommand("push", "origin", "feat-a", "feat-b"); err != nil {
+		t.Fatalf("failed to push branches: %v", err)
+	}
+	if err := runMetadataPush(nil, nil); err != nil {
+		t.Fatalf("metadata push failed: %v", err)
+	}
+	if _, err := local.RunGitCommand("checkout", "-b", "feat-c"); err != nil {
+		t.Fatalf("failed to create feat-c: %v", err)
+	}
+	commit(local, "feat-c")
+	if _, err := local.RunGitCommand("push", "origin", "feat-c"); err != nil {
+		t.Fatalf("failed to push feat-c: %v", err)
+	}
+
+	other := openGw(otherDir)
+	if err := runGet(nil, []string{"missing"}); err == nil {
+		t.Fatalf("expected error for missing remote branch")
+	}
+	if err := runGet(nil, []string{"main"}); err == nil {
+		t.Fatalf("expected error for trunk")
+	}
+
+	if err := runGet(nil, []string{"feat-c"}); err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if current, _ := other.GetCurrentBranch(); current != "feat-c" {
+		t.Fatalf("expected feat-c checked out, got %s", current)
+	}
+	metadata, err := config.LoadRepoMetadata(other)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	for branch, parent := range map[string]string{"feat-a": "main", "feat-b": "feat-a", "feat-c": "feat-b"} {
+		if got, _ := metadata.GetParent(branch); got != parent {
+			t.Fatalf("expected %s parent %s, got %q", branch, parent, got)
+		}
+		if !other.BranchExists(branch) {
+			t.Fatalf("expected local branch %s", branch)
+		}
+	}
+
+	// Diverge feat-a on both sides
+	if err := other.CheckoutBranch("feat-a"); err != nil {
+		t.Fatalf("failed to checkout feat-a: %v", err)
+	}
+	commit(other, "local change")
+	if err := os.Chdir(localDir); err != nil {
+		t.Fatalf("failed to chdir: %v", err)
+	}
+	if err := local.CheckoutBranch("feat-a"); err != nil {
+		t.Fatalf("failed to checkout feat-a: %v", err)
+	}
+	commit(local, "remote change")
+	if _, err := local.RunGitCommand("push", "origin", "feat-a"); err != nil {
+		t.Fatalf("failed to push feat-a: %v", err)
+	}
+
+	openGw(otherDir)
+	if err := runGet(nil, []string{"feat-b"}); err == nil {
+		t.Fatalf("expected get to refuse diverged feat-a")
+	}
+
+	// Uncommitted work on the checked-out feat-a stops the reset
+	getForce = true
+	if err := os.WriteFile(filepath.Join(otherDir, "wip.txt"), []byte("wip"), 0644); err != nil {
+		t.Fatalf("failed to write wip.txt: %v", err)
+	}
+	if _, err := other.RunGitCommand("add", "wip.txt"); err != nil {
+		t.Fatalf("failed to stage wip.txt: %v", err)
+	}
+	if err := runGet(nil, []string{"feat-b"}); err == nil || !strings.Contains(err.Error(), "wip.txt") {
+		t.Fatalf("expected get --force to refuse and name wip.txt, got %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(otherDir, "wip.txt")); string(data) != "wip" {
+		t.Fatalf("expected wip.txt to survive")
+	}
+	if _, err := other.RunGitCommand("rm", "-f", "wip.txt"); err != nil {
+		t.Fatalf("failed to drop wip.txt: %v", err)
+	}
+
+	if err := runGet(nil, []string{"feat-b"}); err != nil {
+		t.Fatalf("get --force failed: %v", err)
+	}
+	localTip, err := other.GetBranchCommit("feat-a")
+	if err != nil {
+		t.Fatalf("failed to get feat-a: %v", err)
+	}
+	remoteTip, err := other.GetBranchCommit("origin/feat-a")
+	if err != nil {
+		t.Fatalf("failed to get origin/feat-a: %v", err)
+	}
+	if localTip != remoteTip {
+		t.Fatalf("expected feat-a reset to origin/feat-a")
+	}
+}
@@ -67,7 +67,7 @@ func TestNeedsRebase(t *testing.T) {
 	defer repo.cleanup()
 
 	repo.createBranch(t, "feat-rebase", "main")
-	needs, err := needsRebase(repo.repo, "feat-rebase", "main")
+	needs, err := needsRebase(repo.repo, "feat-rebase", "main", "")
 	if err != nil {
 		t.Fatalf("needsRebase failed: %v", err)
 	}
@@ -78,7 +78,7 @@ func TestNeedsRebase(t *testing.T) {
 	repo.repo.CheckoutBranch("main")
 	repo.commitFile(t, "parent.txt", "parent", "parent commit")
 
-	needs, err = needsRebase(repo.repo, "feat-rebase", "main")
+	needs, err = needsRebase(repo.repo, "feat-rebase", "main", "")
 	if err != nil {
 		t.Fatalf("needsRebase failed: %v", err)
 	}
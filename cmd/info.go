@@ -4,11 +4,12 @@ import (
 	"fmt"
 
 	"github.com/israelmalagutti/git-wrapper/internal/config"
-	"github.com/israelmalagutti/git-wrapper/internal/git"
 	"github.com/israelmalagutti/git-wrapper/internal/stack"
 	"github.com/spf13/cobra"
 )
 
+var infoJSON bool
+
 var infoCmd = &cobra.Command{
 	Use:   "info [branch]",
 	Short: "Display information about a branch",
@@ -21,17 +22,21 @@ Information includes:
   - Children branches
   - Stack path from trunk
   - Commit SHA
-  - Stack depth`,
+  - Stack depth
+
+Use --json to print the same information in the versioned format
+described in USAGE.md.`,
 	RunE: runInfo,
 }
 
 func init() {
 	rootCmd.AddCommand(infoCmd)
+	infoCmd.Flags().BoolVar(&infoJSON, "json", false, "Print the branch as JSON")
 }
 
 func runInfo(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -43,7 +48,7 @@ func runInfo(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load metadata
-	metadata, err := config.LoadMetadata(repo.GetMetadataPath())
+	metadata, err := config.LoadRepoMetadata(repo)
 	if err != nil {
 		return fmt.Errorf("failed to load metadata: %w", err)
 	}
@@ -71,6 +76,10 @@ func runInfo(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to build stack: %w", err)
 	}
 
+	if infoJSON {
+		return printStackJSON(repo, s, metadata, []string{branchName})
+	}
+
 	// Get node
 	node := s.GetNode(branchName)
 	if node == nil {
@@ -7,7 +7,6 @@ import (
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/israelmalagutti/git-wrapper/internal/config"
-	"github.com/israelmalagutti/git-wrapper/internal/git"
 	"github.com/spf13/cobra"
 )
 
@@ -26,8 +25,12 @@ func init() {
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
+	if err := noDryRun("init"); err != nil {
+		return err
+	}
+
 	// Check if we're in a git repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -98,7 +101,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 	metadata := &config.Metadata{
 		Branches: make(map[string]*config.BranchMetadata),
 	}
-	if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
 		return fmt.Errorf("failed to save metadata: %w", err)
 	}
 
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+var insertCmd = &cobra.Command{
+	Use:   "insert [name]",
+	Short: "Create a new branch between the current branch and its children",
+	Long: `Create a new branch on top of the current branch and move the current
+branch's children onto it, restacking them.
+
+This is 'gw create --insert': use it to slide a prerequisite change in
+below work that is already stacked. When the current branch has several
+children you choose which ones to move.
+
+Examples:
+  gw insert feat-prereq                  # Insert an empty branch
+  gw insert feat-prereq -am "Add helper" # Insert and commit all changes`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: withOperationLog("insert", runInsert),
+}
+
+func init() {
+	rootCmd.AddCommand(insertCmd)
+	insertCmd.Flags().StringVarP(&createMessage, "message", "m", "", "Commit staged changes with this message")
+	insertCmd.Flags().BoolVarP(&createAll, "all", "a", false, "Stage all unstaged changes before committing")
+	insertCmd.Flags().BoolVarP(&createPatch, "patch", "p", false, "Interactively select hunks to stage")
+}
+
+func runInsert(cmd *cobra.Command, args []string) error {
+	prevInsert := createInsert
+	defer func() { createInsert = prevInsert }()
+	createInsert = true
+
+	return runCreate(cmd, args)
+}
+
+// selectInsertChildren returns the children of parent to move onto the new branch,
+// asking which ones when there are several
+func selectInsertChildren(repo *git.Repo, parent, branchName string) ([]string, error) {
+	metadata, err := config.LoadRepoMetadata(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load metadata: %w", err)
+	}
+
+	children := metadata.GetChildren(parent)
+	sort.Strings(children)
+	if len(children) <= 1 {
+		return children, nil
+	}
+
+	var selected []string
+	prompt := &survey.MultiSelect{
+		Message: fmt.Sprintf("Select branches to move onto %s:", branchName),
+		Options: children,
+		Default: children,
+	}
+	if err := askOne(prompt, &selected); err != nil {
+		return nil, err
+	}
+	return selected, nil
+}
+
+// insertBranch reparents children onto branchName and restacks them with their descendants
+func insertBranch(repo *git.Repo, metadata *config.Metadata, branchName string, children []string) error {
+	fmt.Printf("\nMoving %d branch(es) onto %s...\n", len(children), colors.BranchCurrent(branchName))
+	for _, child := range children {
+		if err := metadata.UpdateParent(child, branchName); err != nil {
+			return fmt.Errorf("failed to update child '%s': %w", child, err)
+		}
+		fmt.Printf("  ✓ Updated '%s' parent to '%s'\n", child, branchName)
+	}
+
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
+		return fmt.Errorf("failed to save metadata: %w", err)
+	}
+
+	cfg, err := config.Load(repo.GetConfigPath())
+	if err != nil {
+		return err
+	}
+
+	s, err := stack.BuildStack(repo, cfg, metadata)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild stack: %w", err)
+	}
+
+	branches := []string{}
+	for _, child := range children {
+		if node := s.GetNode(child); node != nil {
+			branches = append(branches, child)
+			branches = append(branches, descendantNames(node)...)
+		}
+	}
+
+	fmt.Println("\nRestacking children...")
+	if err := startRestack(repo, metadata, branches); err != nil {
+		return fmt.Errorf("failed to restack children: %w", err)
+	}
+
+	// A rebase in the working tree may have left another branch checked out
+	if err := repo.CheckoutBranch(branchName); err != nil {
+		return err
+	}
+	fmt.Println("✓ Children restacked")
+	return nil
+}
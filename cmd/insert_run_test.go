@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+)
+
+func TestRunInsert(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.commitFile(t, "a.txt", "a", "a commit")
+	repo.createBranch(t, "feat-b", "feat-a")
+	repo.commitFile(t, "b.txt", "b", "b commit")
+	repo.createBranch(t, "feat-c", "feat-b")
+	repo.commitFile(t, "c.txt", "c", "c commit")
+	if err := repo.repo.CheckoutBranch("feat-a"); err != nil {
+		t.Fatalf("failed to checkout feat-a: %v", err)
+	}
+
+	prevMessage, prevAll := createMessage, createAll
+	defer func() { createMessage, createAll = prevMessage, prevAll }()
+	createMessage, createAll = "add prereq", false
+
+	if err := os.WriteFile(filepath.Join(repo.dir, "prereq.txt"), []byte("prereq"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := repo.repo.RunGitCommand("add", "prereq.txt"); err != nil {
+		t.Fatalf("failed to stage file: %v", err)
+	}
+
+	if err := runInsert(nil, []string{"feat-prereq"}); err != nil {
+		t.Fatalf("runInsert failed: %v", err)
+	}
+	if createInsert {
+		t.Fatalf("expected runInsert to restore the insert flag")
+	}
+
+	metadata, err := config.LoadRepoMetadata(repo.repo)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if parent, _ := metadata.GetParent("feat-prereq"); parent != "feat-a" {
+		t.Fatalf("expected feat-prereq on feat-a, got %s", parent)
+	}
+	if parent, _ := metadata.GetParent("feat-b"); parent != "feat-prereq" {
+		t.Fatalf("expected feat-b on feat-prereq, got %s", parent)
+	}
+	if parent, _ := metadata.GetParent("feat-c"); parent != "feat-b" {
+		t.Fatalf("expected feat-c to stay on feat-b, got %s", parent)
+	}
+
+	// The children are restacked on top of the new commit
+	for _, branch := range []string{"feat-b", "feat-c"} {
+		if !repo.repo.IsAncestor("feat-prereq", branch) {
+			t.Fatalf("expected %s to be restacked onto feat-prereq", branch)
+		}
+	}
+	if current, _ := repo.repo.GetCurrentBranch(); current != "feat-prereq" {
+		t.Fatalf("expected to be on feat-prereq, got %s", current)
+	}
+}
+
+func TestRunCreateInsertChoosesChildren(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.commitFile(t, "a.txt", "a", "a commit")
+	repo.createBranch(t, "feat-b", "feat-a")
+	repo.createBranch(t, "feat-c", "feat-a")
+	if err := repo.repo.CheckoutBranch("feat-a"); err != nil {
+		t.Fatalf("failed to checkout feat-a: %v", err)
+	}
+
+	prevMessage, prevInsert := createMessage, createInsert
+	defer func() { createMessage, createInsert = prevMessage, prevInsert }()
+	createMessage, createInsert = "", true
+
+	withAskOne(t, []interface{}{[]string{"feat-c"}}, func() {
+		if err := runCreate(nil, []string{"feat-mid"}); err != nil {
+			t.Fatalf("runCreate --insert failed: %v", err)
+		}
+	})
+
+	metadata, err := config.LoadRepoMetadata(repo.repo)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if parent, _ := metadata.GetParent("feat-b"); parent != "feat-a" {
+		t.Fatalf("expected feat-b to stay on feat-a, got %s", parent)
+	}
+	if parent, _ := metadata.GetParent("feat-c"); parent != "feat-mid" {
+		t.Fatalf("expected feat-c on feat-mid, got %s", parent)
+	}
+}
+
+func TestRunInsertWithoutChildren(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+
+	prevMessage := createMessage
+	defer func() { createMessage = prevMessage }()
+	createMessage = ""
+
+	if err := runInsert(nil, []string{"feat-leaf"}); err != nil {
+		t.Fatalf("runInsert failed: %v", err)
+	}
+
+	metadata, err := config.LoadRepoMetadata(repo.repo)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if parent, _ := metadata.GetParent("feat-leaf"); parent != "feat-a" {
+		t.Fatalf("expected feat-leaf on feat-a, got %s", parent)
+	}
+}
+
+func TestRunCreateInsertCancelledCommitReportsChildren(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.commitFile(t, "a.txt", "a", "a commit")
+	repo.createBranch(t, "feat-b", "feat-a")
+	if err := repo.repo.CheckoutBranch("feat-a"); err != nil {
+		t.Fatalf("failed to checkout feat-a: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo.dir, "a.txt"), []byte("changed"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	prevMessage, prevInsert := createMessage, createInsert
+	defer func() { createMessage, createInsert = prevMessage, prevInsert }()
+	createMessage, createInsert = "", true
+
+	// The commit prompt is cancelled after feat-mid exists
+	withAskOneError(t, terminal.InterruptErr, func() {
+		err := runCreate(nil, []string{"feat-mid"})
+		if err == nil || !strings.Contains(err.Error(), "feat-b stayed on feat-a") {
+			t.Fatalf("expected an error naming the children left behind, got %v", err)
+		}
+	})
+
+	metadata, err := config.LoadRepoMetadata(repo.repo)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if parent, _ := metadata.GetParent("feat-b"); parent != "feat-a" {
+		t.Fatalf("expected feat-b to stay on feat-a, got %s", parent)
+	}
+}
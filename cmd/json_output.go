@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/stack"
+)
+
+// printStackJSON prints the --json document describing branches
+func printStackJSON(repo *git.Repo, s *stack.Stack, metadata *config.Metadata, branches []string) error {
+	data, err := json.MarshalIndent(s.NewDocument(repo, metadata, branches), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// nodeNames returns the names of nodes, in order
+func nodeNames(nodes []*stack.Node) []string {
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	return names
+}
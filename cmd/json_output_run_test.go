@@ -0,0 +1,40 @@
+package cmd
+
+import "testing"
+
+func TestRunJSONOutput(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.createBranch(t, "feat-b", "feat-a")
+	repo.createBranch(t, "feat-c", "feat-a")
+	if err := repo.repo.CheckoutBranch("feat-a"); err != nil {
+		t.Fatalf("failed to checkout feat-a: %v", err)
+	}
+
+	prev := []bool{logJSON, infoJSON, parentJSON, childrenJSON, topJSON, bottomJSON}
+	defer func() {
+		logJSON, infoJSON, parentJSON, childrenJSON, topJSON, bottomJSON = prev[0], prev[1], prev[2], prev[3], prev[4], prev[5]
+	}()
+	logJSON, infoJSON, parentJSON, childrenJSON, topJSON, bottomJSON = true, true, true, true, true, true
+
+	runs := map[string]func() error{
+		"log":      func() error { return runLog(nil, nil) },
+		"info":     func() error { return runInfo(nil, []string{"feat-b"}) },
+		"parent":   func() error { return runParent(nil, nil) },
+		"children": func() error { return runChildren(nil, nil) },
+		"top":      func() error { return runTop(nil, nil) },
+		"bottom":   func() error { return runBottom(nil, nil) },
+	}
+	for name, run := range runs {
+		if err := run(); err != nil {
+			t.Fatalf("%s --json failed: %v", name, err)
+		}
+	}
+
+	// Queries never switch branches, even when top has several tips
+	if current, _ := repo.repo.GetCurrentBranch(); current != "feat-a" {
+		t.Fatalf("expected to stay on feat-a, got %s", current)
+	}
+}
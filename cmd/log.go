@@ -4,14 +4,17 @@ import (
 	"fmt"
 
 	"github.com/israelmalagutti/git-wrapper/internal/config"
-	"github.com/israelmalagutti/git-wrapper/internal/git"
 	"github.com/israelmalagutti/git-wrapper/internal/stack"
 	"github.com/spf13/cobra"
 )
 
 var (
-	logShort bool
-	logLong  bool
+	logShort   bool
+	logLong    bool
+	logRefresh bool
+	logScope   scopeFlags
+	logJSON    bool
+	logFormat  string
 )
 
 var logCmd = &cobra.Command{
@@ -25,7 +28,21 @@ the current branch highlighted.
 Modes:
   gw log         - Standard tree view (*branch = current)
   gw log --short - Compact indented view (● = current, ○ = other)
-  gw log --long  - Detailed view with commit messages`,
+  gw log --long  - Detailed view with commit messages
+
+Branches with a pull request show its state, review decision and CI
+result from the last fetch. Use --refresh to ask the forge again.
+
+Use --only, --upstack, --downstack or --stack to show just part of the
+tree around the current branch. Trunk is always shown.
+
+Use --json to print trunk and every branch shown, parents first, in the
+versioned format described in USAGE.md.
+
+Use --format dot or --format mermaid to export the tree as a Graphviz or
+Mermaid graph, e.g. to embed a stack diagram in a pull request or design
+doc. Trunk, the current branch and branches that need a restack are
+styled, and pull request status is part of each label.`,
 	RunE: runLog,
 }
 
@@ -33,11 +50,16 @@ func init() {
 	rootCmd.AddCommand(logCmd)
 	logCmd.Flags().BoolVar(&logShort, "short", false, "Show compact view")
 	logCmd.Flags().BoolVar(&logLong, "long", false, "Show detailed view with commit messages")
+	logCmd.Flags().BoolVar(&logRefresh, "refresh", false, "Fetch pull request status from the forge")
+	addScopeFlags(logCmd, &logScope, "Show", allScopes...)
+	logCmd.Flags().BoolVar(&logJSON, "json", false, "Print the stack as JSON")
+	logCmd.Flags().StringVar(&logFormat, "format", "", "Export the stack as a graph: dot or mermaid")
+	logCmd.MarkFlagsMutuallyExclusive("json", "format")
 }
 
 func runLog(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -49,7 +71,7 @@ func runLog(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load metadata
-	metadata, err := config.LoadMetadata(repo.GetMetadataPath())
+	metadata, err := config.LoadRepoMetadata(repo)
 	if err != nil {
 		return fmt.Errorf("failed to load metadata: %w", err)
 	}
@@ -65,11 +87,46 @@ func runLog(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid stack structure: %w", err)
 	}
 
+	if logFormat != "" && logFormat != "dot" && logFormat != "mermaid" {
+		return fmt.Errorf("unknown format '%s' (expected dot or mermaid)", logFormat)
+	}
+
+	// Narrow the tree to the requested scope around the current branch
+	if logScope.isSet() {
+		s = s.Subset(s.SelectScope(s.Current, logScope.resolve(stack.ScopeStack)))
+	}
+
+	if logJSON {
+		branches := append([]string{s.TrunkName}, nodeNames(s.GetTopologicalOrder())...)
+		return printStackJSON(repo, s, metadata, branches)
+	}
+
+	// Annotate branches with their pull request status
+	statuses, err := loadPRStatuses(repo, cfg, metadata, logRefresh)
+	if err != nil {
+		return err
+	}
+	s.Annotations = prAnnotations(statuses)
+
 	// Render based on flags
 	var output string
-	if logShort {
+	switch {
+	case logFormat != "":
+		opts := stack.GraphOptions{NeedsRestack: map[string]bool{}}
+		for _, node := range s.GetTopologicalOrder() {
+			behind, err := repo.IsBehind(node.Name, node.Parent.Name, metadata.GetParentSHA(node.Name))
+			if err == nil && behind {
+				opts.NeedsRestack[node.Name] = true
+			}
+		}
+		if logFormat == "dot" {
+			output = s.RenderDOT(opts)
+		} else {
+			output = s.RenderMermaid(opts)
+		}
+	case logShort:
 		output = s.RenderShort(repo)
-	} else {
+	default:
 		opts := stack.TreeOptions{
 			ShowCommitSHA: true,
 			ShowCommitMsg: logLong,
@@ -28,3 +28,25 @@ func TestRunLogMissingConfig(t *testing.T) {
 		t.Fatalf("expected runLog config error")
 	}
 }
+
+func TestRunLogFormat(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+
+	prevFormat := logFormat
+	defer func() { logFormat = prevFormat }()
+
+	for _, format := range []string{"dot", "mermaid"} {
+		logFormat = format
+		if err := runLog(nil, nil); err != nil {
+			t.Fatalf("runLog --format %s failed: %v", format, err)
+		}
+	}
+
+	logFormat = "svg"
+	if err := runLog(nil, nil); err == nil {
+		t.Fatalf("expected unknown format error")
+	}
+}
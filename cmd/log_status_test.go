@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/forge"
+	"github.com/israelmalagutti/git-wrapper/internal/forge/forgetest"
+)
+
+func TestRunLogRefreshPRStatus(t *testing.T) {
+	repo, server := setupSubmitRepo(t)
+
+	prevShort, prevLong, prevRefresh := logShort, logLong, logRefresh
+	defer func() { logShort, logLong, logRefresh = prevShort, prevLong, prevRefresh }()
+	logShort, logLong = false, false
+
+	prevColors := colors.IsEnabled()
+	defer colors.SetEnabled(prevColors)
+	colors.SetEnabled(false)
+
+	for _, branch := range []string{"feat-a", "feat-b"} {
+		if err := runCreate(nil, []string{branch}); err != nil {
+			t.Fatalf("create %s failed: %v", branch, err)
+		}
+	}
+
+	// feat-a's PR is recorded in metadata, feat-b's has to be looked up by head
+	numberA := server.AddPull(forgetest.PullRequest{Head: "feat-a", Base: "main", State: "open", HeadSHA: "aaa"})
+	numberB := server.AddPull(forgetest.PullRequest{Head: "feat-b", Base: "feat-a", State: "open", Draft: true, HeadSHA: "bbb"})
+	server.AddReview(numberA, forgetest.Review{User: "alice", State: "APPROVED"})
+	server.AddStatus("aaa", "success")
+
+	metadata, err := config.LoadRepoMetadata(repo)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if err := metadata.SetPRNumber("feat-a", numberA); err != nil {
+		t.Fatalf("failed to set PR number: %v", err)
+	}
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
+		t.Fatalf("failed to save metadata: %v", err)
+	}
+
+	logRefresh = true
+	if err := runLog(nil, nil); err != nil {
+		t.Fatalf("runLog --refresh failed: %v", err)
+	}
+
+	cache, err := forge.LoadCache(repo.GetPRCachePath())
+	if err != nil {
+		t.Fatalf("failed to load cache: %v", err)
+	}
+	annotations := prAnnotations(cache)
+	if got := annotations["feat-a"]; got != "#1 open · approved · CI passing" {
+		t.Fatalf("unexpected feat-a annotation %q", got)
+	}
+	if got := annotations["feat-b"]; got != "#2 draft · review required" || cache.Branches["feat-b"].Number != numberB {
+		t.Fatalf("unexpected feat-b annotation %q", got)
+	}
+
+	// Without --refresh the cache is used and the forge is not contacted
+	requests := len(server.Requests())
+	logRefresh, logShort = false, true
+	if err := runLog(nil, nil); err != nil {
+		t.Fatalf("runLog failed: %v", err)
+	}
+	if len(server.Requests()) != requests {
+		t.Fatalf("expected no forge requests without --refresh")
+	}
+
+	// A failed refresh falls back to the cache and leaves it intact
+	server.Close()
+	logRefresh = true
+	if err := runLog(nil, nil); err != nil {
+		t.Fatalf("runLog with unreachable forge failed: %v", err)
+	}
+	cache, err = forge.LoadCache(repo.GetPRCachePath())
+	if err != nil || len(cache.Branches) != 2 {
+		t.Fatalf("expected cache to survive a failed refresh, got %+v (%v)", cache, err)
+	}
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/metasync"
+	"github.com/spf13/cobra"
+)
+
+var metadataCmd = &cobra.Command{
+	Use:   "metadata <command>",
+	Short: "Manage how stack metadata is stored",
+	Long: `Manage how stack metadata is stored.
+
+gw can keep stack metadata in a JSON file in the git directory (the default)
+or as one blob per branch under refs/gw/meta/<branch>. The ref backend can be
+shared and backed up like any other git ref.
+
+Available commands:
+  migrate    Convert metadata between the json and refs backends
+  fetch      Merge stack metadata from a remote
+  push       Publish stack metadata to a remote
+
+Example:
+  gw metadata migrate refs    # Move metadata into refs/gw/meta/
+  gw metadata migrate json    # Move metadata back into the JSON file
+  gw metadata fetch           # Pick up a teammate's stack from origin
+  gw metadata push            # Share your stack on origin`,
+}
+
+var metadataMigrateCmd = &cobra.Command{
+	Use:   "migrate <json|refs>",
+	Short: "Convert metadata to another storage backend",
+	Long: `Copy all stack metadata into the given backend, select it in .gw_config
+and remove the copy held by the previous backend.
+
+Example:
+  gw metadata migrate refs
+  gw metadata migrate json`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{config.MetadataBackendJSON, config.MetadataBackendRefs},
+	RunE:      withOperationLog("metadata migrate", runMetadataMigrate),
+}
+
+var (
+	metadataRemote   string
+	metadataStrategy string
+)
+
+var metadataFetchCmd = &cobra.Command{
+	Use:   "fetch",
+	Short: "Merge stack metadata from a remote",
+	Long: `Fetch the remote's refs/gw/meta/* and merge the parent records into the
+local metadata, whichever backend it uses.
+
+Records are only merged for branches that exist locally. A parent changed on
+only one side since the last fetch wins; when both sides changed it, the
+--strategy flag decides (ours keeps the local parent, theirs takes the remote).
+Branches whose parent changed are reported.
+
+Example:
+  gw metadata fetch
+  gw metadata fetch --remote upstream --strategy theirs`,
+	Args: cobra.NoArgs,
+	RunE: withOperationLog("metadata fetch", runMetadataFetch),
+}
+
+var metadataPushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Publish stack metadata to a remote",
+	Long: `Fetch and merge the remote's metadata, then push every local branch record
+that differs to refs/gw/meta/<branch> on the remote.
+
+Each ref is pushed with a lease on the fetched value, so a concurrent push by
+a teammate is never overwritten.
+
+Example:
+  gw metadata push
+  gw metadata push --remote upstream`,
+	Args: cobra.NoArgs,
+	RunE: withOperationLog("metadata push", runMetadataPush),
+}
+
+func init() {
+	for _, c := range []*cobra.Command{metadataFetchCmd, metadataPushCmd} {
+		c.Flags().StringVar(&metadataRemote, "remote", "origin", "Remote to exchange metadata with")
+		c.Flags().StringVar(&metadataStrategy, "strategy", metasync.StrategyOurs, "Parent conflict resolution: ours or theirs")
+	}
+	metadataCmd.AddCommand(metadataMigrateCmd)
+	metadataCmd.AddCommand(metadataFetchCmd)
+	metadataCmd.AddCommand(metadataPushCmd)
+	rootCmd.AddCommand(metadataCmd)
+}
+
+func runMetadataMigrate(cmd *cobra.Command, args []string) error {
+	if err := noDryRun("metadata migrate"); err != nil {
+		return err
+	}
+
+	target := args[0]
+	if !config.IsValidMetadataBackend(target) {
+		return fmt.Errorf("unknown metadata backend %q (expected %q or %q)", target, config.MetadataBackendJSON, config.MetadataBackendRefs)
+	}
+
+	// Initialize repository
+	repo, err := openRepo()
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	// Load config
+	cfg, err := config.Load(repo.GetConfigPath())
+	if err != nil {
+		return err
+	}
+
+	source := cfg.GetMetadataBackend()
+	if source == target {
+		fmt.Printf("Metadata already uses the %s backend\n", colors.Info(target))
+		return nil
+	}
+
+	return migrateMetadata(repo, cfg, source, target)
+}
+
+// migrateMetadata copies metadata from source to target and switches the config over
+func migrateMetadata(repo *git.Repo, cfg *config.Config, source, target string) error {
+	from, err := config.NewMetadataStore(repo, source)
+	if err != nil {
+		return err
+	}
+	to, err := config.NewMetadataStore(repo, target)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := from.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load metadata: %w", err)
+	}
+
+	if err := to.Save(metadata); err != nil {
+		return fmt.Errorf("failed to save metadata: %w", err)
+	}
+
+	cfg.MetadataBackend = target
+	if err := cfg.Save(repo.GetConfigPath()); err != nil {
+		_ = to.Clear()
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	if err := from.Clear(); err != nil {
+		fmt.Printf("%s Could not remove %s metadata: %v\n", colors.Warning("⚠"), source, err)
+	}
+
+	fmt.Printf("%s Migrated %d branches from %s to %s\n", colors.Success("✓"), len(metadata.Branches),
+		colors.Info(source), colors.Info(target))
+	return nil
+}
+
+func runMetadataFetch(cmd *cobra.Command, args []string) error {
+	if !metasync.IsValidStrategy(metadataStrategy) {
+		return fmt.Errorf("unknown strategy %q (expected %q or %q)", metadataStrategy, metasync.StrategyOurs, metasync.StrategyTheirs)
+	}
+
+	// Initialize repository
+	repo, err := openRepo()
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	// Load config
+	cfg, err := config.Load(repo.GetConfigPath())
+	if err != nil {
+		return err
+	}
+
+	return fetchMetadata(repo, cfg, metadataRemote, metadataStrategy)
+}
+
+func runMetadataPush(cmd *cobra.Command, args []string) error {
+	if !metasync.IsValidStrategy(metadataStrategy) {
+		return fmt.Errorf("unknown strategy %q (expected %q or %q)", metadataStrategy, metasync.StrategyOurs, metasync.StrategyTheirs)
+	}
+
+	// Initialize repository
+	repo, err := openRepo()
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	// Load config
+	cfg, err := config.Load(repo.GetConfigPath())
+	if err != nil {
+		return err
+	}
+
+	if err := fetchMetadata(repo, cfg, metadataRemote, metadataStrategy); err != nil {
+		return err
+	}
+	return pushMetadata(repo, metadataRemote)
+}
+
+// fetchMetadata merges the remote's metadata and reports branches whose parent changed
+func fetchMetadata(repo *git.Repo, cfg *config.Config, remote, strategy string) error {
+	changes, err := metasync.Fetch(repo, remote, strategy, cfg.Trunk)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Printf("✓ Stack metadata is up to date with %s\n", remote)
+		return nil
+	}
+
+	fmt.Printf("Stack metadata from %s:\n", remote)
+	for _, change := range changes {
+		fmt.Printf("  %s\n", describeMetadataChange(change))
+	}
+	return nil
+}
+
+// pushMetadata publishes local records and reports which branches were pushed
+func pushMetadata(repo *git.Repo, remote string) error {
+	pushed, err := metasync.Push(repo, remote)
+	if err != nil {
+		return fmt.Errorf("%w\nRun 'gw metadata fetch' and try again", err)
+	}
+
+	if len(pushed) == 0 {
+		fmt.Printf("✓ %s already has the local stack metadata\n", remote)
+		return nil
+	}
+
+	fmt.Printf("%s Pushed metadata for %d branch(es) to %s\n", colors.Success("✓"), len(pushed), remote)
+	for _, branch := range pushed {
+		fmt.Printf("    %s\n", branch)
+	}
+	return nil
+}
+
+// describeMetadataChange returns a one-line report for a merged branch
+func describeMetadataChange(change metasync.Change) string {
+	name := colors.BranchCurrent(change.Branch)
+	switch {
+	case change.Applied && change.LocalParent == "":
+		return fmt.Sprintf("%s tracked with parent %s", name, colors.BranchParent(change.RemoteParent))
+	case change.Applied && change.Conflict:
+		return fmt.Sprintf("%s parent %s → %s %s", name, change.LocalParent, colors.BranchParent(change.RemoteParent),
+			colors.Warning("(conflict, took remote)"))
+	case change.Applied:
+		return fmt.Sprintf("%s parent %s → %s", name, change.LocalParent, colors.BranchParent(change.RemoteParent))
+	default:
+		return fmt.Sprintf("%s kept parent %s, remote has %s %s", name, colors.BranchParent(change.LocalParent),
+			change.RemoteParent, colors.Warning("(conflict)"))
+	}
+}
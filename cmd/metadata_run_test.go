@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+)
+
+func TestRunMetadataMigrateRoundTrip(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	if err := runCreate(nil, []string{"feat-migrate"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	if err := runMetadataMigrate(nil, []string{"refs"}); err != nil {
+		t.Fatalf("migrate to refs failed: %v", err)
+	}
+
+	cfg, err := config.Load(repo.repo.GetConfigPath())
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if cfg.GetMetadataBackend() != config.MetadataBackendRefs {
+		t.Fatalf("expected refs backend, got %s", cfg.GetMetadataBackend())
+	}
+	if _, err := os.Stat(repo.repo.GetMetadataPath()); !os.IsNotExist(err) {
+		t.Fatalf("expected JSON metadata to be removed")
+	}
+	if _, err := repo.repo.RunGitCommand("rev-parse", "--verify", config.MetadataRefPrefix+"feat-migrate"); err != nil {
+		t.Fatalf("expected metadata ref: %v", err)
+	}
+
+	// Commands keep working against the ref backend
+	if err := runCreate(nil, []string{"feat-on-refs"}); err != nil {
+		t.Fatalf("create on refs backend failed: %v", err)
+	}
+	metadata, err := config.LoadRepoMetadata(repo.repo)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if parent, _ := metadata.GetParent("feat-on-refs"); parent != "feat-migrate" {
+		t.Fatalf("expected parent feat-migrate, got %q", parent)
+	}
+
+	if err := runMetadataMigrate(nil, []string{"refs"}); err != nil {
+		t.Fatalf("migrate to same backend failed: %v", err)
+	}
+
+	if err := runMetadataMigrate(nil, []string{"json"}); err != nil {
+		t.Fatalf("migrate to json failed: %v", err)
+	}
+	metadata, err = config.LoadMetadata(repo.repo.GetMetadataPath())
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if !metadata.IsTracked("feat-migrate") || !metadata.IsTracked("feat-on-refs") {
+		t.Fatalf("expected both branches in JSON metadata, got %v", metadata.Branches)
+	}
+	refs, err := repo.repo.ListRefs(config.MetadataRefPrefix)
+	if err != nil {
+		t.Fatalf("failed to list refs: %v", err)
+	}
+	if len(refs) != 0 {
+		t.Fatalf("expected metadata refs to be removed, got %v", refs)
+	}
+}
+
+func TestRunMetadataMigrateInvalidBackend(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	if err := runMetadataMigrate(nil, []string{"sqlite"}); err == nil {
+		t.Fatalf("expected error for unknown backend")
+	}
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/metasync"
+)
+
+func TestRunMetadataPushFetch(t *testing.T) {
+	localDir, otherDir, cleanup := setupRepoWithRemote(t)
+	defer cleanup()
+
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get cwd: %v", err)
+	}
+	defer os.Chdir(origDir)
+
+	prevRemote, prevStrategy := metadataRemote, metadataStrategy
+	defer func() { metadataRemote, metadataStrategy = prevRemote, prevStrategy }()
+	metadataRemote, metadataStrategy = "origin", metasync.StrategyOurs
+
+	initGw := func(dir string) *git.Repo {
+		t.Helper()
+		if err := os.Chdir(dir); err != nil {
+			t.Fatalf("failed to chdir: %v", err)
+		}
+		repo, err := git.NewRepo()
+		if err != nil {
+			t.Fatalf("failed to open repo: %v", err)
+		}
+		if err := config.NewConfig("main").Save(repo.GetConfigPath()); err != nil {
+			t.Fatalf("failed to save config: %v", err)
+		}
+		return repo
+	}
+
+	// Build and publish a stack from the first clone
+	local := initGw(localDir)
+	if err := runCreate(nil, []string{"feat-a"}); err != nil {
+		t.Fatalf("create feat-a failed: %v", err)
+	}
+	if err := runCreate(nil, []string{"feat-b"}); err != nil {
+		t.Fatalf("create feat-b failed: %v", err)
+	}
+	if _, err := local.RunGitCommand("push", "origin", "feat-a", "feat-b"); err != nil {
+		t.Fatalf("failed to push branches: %v", err)
+	}
+	if err := runMetadataPush(nil, nil); err != nil {
+		t.Fatalf("metadata push failed: %v", err)
+	}
+	if _, err := local.RunGitCommand("ls-remote", "--exit-code", "origin", config.MetadataRefPrefix+"feat-b"); err != nil {
+		t.Fatalf("expected metadata ref on remote: %v", err)
+	}
+
+	// A teammate picks up the stack
+	other := initGw(otherDir)
+	if _, err := other.RunGitCommand("fetch", "origin"); err != nil {
+		t.Fatalf("failed to fetch: %v", err)
+	}
+	for _, branch := range []string{"feat-a", "feat-b"} {
+		if _, err := other.RunGitCommand("branch", branch, "origin/"+branch); err != nil {
+			t.Fatalf("failed to create %s: %v", branch, err)
+		}
+	}
+	if err := runMetadataFetch(nil, nil); err != nil {
+		t.Fatalf("metadata fetch failed: %v", err)
+	}
+	metadata, err := config.LoadRepoMetadata(other)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if parent, _ := metadata.GetParent("feat-b"); parent != "feat-a" {
+		t.Fatalf("expected feat-b on feat-a, got %q", parent)
+	}
+
+	// The teammate reparents feat-b and publishes it
+	if err := metadata.UpdateParent("feat-b", "main"); err != nil {
+		t.Fatalf("UpdateParent failed: %v", err)
+	}
+	if err := config.SaveRepoMetadata(other, metadata); err != nil {
+		t.Fatalf("failed to save metadata: %v", err)
+	}
+	if err := runMetadataPush(nil, nil); err != nil {
+		t.Fatalf("metadata push from teammate failed: %v", err)
+	}
+
+	// The original author sees the new parent
+	if err := os.Chdir(localDir); err != nil {
+		t.Fatalf("failed to chdir: %v", err)
+	}
+	changes, err := metasync.Fetch(local, "origin", metasync.StrategyOurs, "main")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Branch != "feat-b" || !changes[0].Applied || changes[0].LocalParent != "feat-a" {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+	metadata, err = config.LoadRepoMetadata(local)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if parent, _ := metadata.GetParent("feat-b"); parent != "main" {
+		t.Fatalf("expected feat-b on main, got %q", parent)
+	}
+
+	metadataStrategy = "mine"
+	if err := runMetadataFetch(nil, nil); err == nil {
+		t.Fatalf("expected error for unknown strategy")
+	}
+}
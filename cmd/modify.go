@@ -32,7 +32,7 @@ Example:
   gw modify -m "msg"     # Amend with message
   gw modify -c -m "msg"  # Create new commit with message`,
 	Aliases: []string{"m"},
-	RunE:    runModify,
+	RunE:    withOperationLog("modify", runModify),
 }
 
 func init() {
@@ -45,7 +45,7 @@ func init() {
 
 func runModify(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -57,7 +57,7 @@ func runModify(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load metadata
-	metadata, err := config.LoadMetadata(repo.GetMetadataPath())
+	metadata, err := config.LoadRepoMetadata(repo)
 	if err != nil {
 		return fmt.Errorf("failed to load metadata: %w", err)
 	}
@@ -168,7 +168,7 @@ func runModify(cmd *cobra.Command, args []string) error {
 	// Restack children if any
 	if len(node.Children) > 0 {
 		fmt.Println("\nRestacking children...")
-		if err := restackChildren(repo, s, node); err != nil {
+		if err := restackChildren(repo, s, metadata, node); err != nil {
 			return fmt.Errorf("failed to restack children: %w", err)
 		}
 		fmt.Println("✓ Children restacked")
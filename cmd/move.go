@@ -7,7 +7,6 @@ import (
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/israelmalagutti/git-wrapper/internal/config"
-	"github.com/israelmalagutti/git-wrapper/internal/git"
 	"github.com/israelmalagutti/git-wrapper/internal/stack"
 	"github.com/spf13/cobra"
 )
@@ -34,7 +33,7 @@ Example:
   gw move -s feat-2 -o main            # Move feat-2 onto main
   gw mv --source feat-3 feat-1         # Move feat-3 onto feat-1`,
 	Aliases: []string{"mv"},
-	RunE:    runMove,
+	RunE:    withOperationLog("move", runMove),
 }
 
 func init() {
@@ -46,7 +45,7 @@ func init() {
 
 func runMove(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -58,7 +57,7 @@ func runMove(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load metadata
-	metadata, err := config.LoadMetadata(repo.GetMetadataPath())
+	metadata, err := config.LoadRepoMetadata(repo)
 	if err != nil {
 		return fmt.Errorf("failed to load metadata: %w", err)
 	}
@@ -173,6 +172,15 @@ func runMove(cmd *cobra.Command, args []string) error {
 	// Get old parent
 	oldParent, _ := metadata.GetParent(sourceBranch)
 
+	// Find where the branch's own commits start on the old parent
+	base, err := repo.GetRestackBase(sourceBranch, oldParent, metadata.GetParentSHA(sourceBranch))
+	if err != nil {
+		base, err = repo.GetMergeBase(sourceBranch, targetBranch)
+		if err != nil {
+			return err
+		}
+	}
+
 	fmt.Printf("Moving '%s' from '%s' to '%s'...\n", sourceBranch, oldParent, targetBranch)
 
 	// Update metadata with new parent
@@ -181,7 +189,7 @@ func runMove(cmd *cobra.Command, args []string) error {
 	}
 
 	// Save metadata
-	if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
 		return fmt.Errorf("failed to save metadata: %w", err)
 	}
 
@@ -194,7 +202,7 @@ func runMove(cmd *cobra.Command, args []string) error {
 			if restoreErr := metadata.UpdateParent(sourceBranch, oldParent); restoreErr != nil {
 				return fmt.Errorf("failed to restore metadata after checkout error: %v (original error: %w)", restoreErr, err)
 			}
-			if saveErr := metadata.Save(repo.GetMetadataPath()); saveErr != nil {
+			if saveErr := config.SaveRepoMetadata(repo, metadata); saveErr != nil {
 				return fmt.Errorf("failed to save restored metadata after checkout error: %v (original error: %w)", saveErr, err)
 			}
 			return fmt.Errorf("failed to checkout '%s': %w", sourceBranch, err)
@@ -203,12 +211,12 @@ func runMove(cmd *cobra.Command, args []string) error {
 
 	// Rebase onto new parent
 	fmt.Printf("Rebasing onto '%s'...\n", targetBranch)
-	if _, err := repo.RunGitCommand("rebase", targetBranch); err != nil {
+	if err := repo.RebaseOnto(sourceBranch, targetBranch, base); err != nil {
 		// Rebase failed, restore old parent
 		if restoreErr := metadata.UpdateParent(sourceBranch, oldParent); restoreErr != nil {
 			return fmt.Errorf("rebase failed: %w\nFailed to restore metadata: %v", err, restoreErr)
 		}
-		if saveErr := metadata.Save(repo.GetMetadataPath()); saveErr != nil {
+		if saveErr := config.SaveRepoMetadata(repo, metadata); saveErr != nil {
 			return fmt.Errorf("rebase failed: %w\nFailed to save restored metadata: %v", err, saveErr)
 		}
 
@@ -224,6 +232,10 @@ func runMove(cmd *cobra.Command, args []string) error {
 
 	fmt.Println("✓ Rebased successfully")
 
+	if err := recordParentSHA(repo, metadata, sourceBranch, targetBranch); err != nil {
+		return err
+	}
+
 	// Rebuild stack with new structure
 	s, err = stack.BuildStack(repo, cfg, metadata)
 	if err != nil {
@@ -238,7 +250,7 @@ func runMove(cmd *cobra.Command, args []string) error {
 	// Restack children if any
 	if len(sourceNode.Children) > 0 {
 		fmt.Println("\nRestacking children...")
-		if err := restackChildren(repo, s, sourceNode); err != nil {
+		if err := restackChildren(repo, s, metadata, sourceNode); err != nil {
 			return fmt.Errorf("failed to restack children: %w", err)
 		}
 		fmt.Println("✓ Children restacked")
@@ -4,26 +4,30 @@ import (
 	"fmt"
 
 	"github.com/israelmalagutti/git-wrapper/internal/config"
-	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/stack"
 	"github.com/spf13/cobra"
 )
 
+var parentJSON bool
+
 var parentCmd = &cobra.Command{
 	Use:   "parent [branch]",
 	Short: "Show the parent branch",
 	Long: `Show the parent branch of the specified branch.
 
-If no branch is specified, shows the parent of the current branch.`,
+If no branch is specified, shows the parent of the current branch.
+Use --json to describe the parent in the format described in USAGE.md.`,
 	RunE: runParent,
 }
 
 func init() {
 	rootCmd.AddCommand(parentCmd)
+	parentCmd.Flags().BoolVar(&parentJSON, "json", false, "Print the parent as JSON")
 }
 
 func runParent(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -35,7 +39,7 @@ func runParent(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load metadata
-	metadata, err := config.LoadMetadata(repo.GetMetadataPath())
+	metadata, err := config.LoadRepoMetadata(repo)
 	if err != nil {
 		return fmt.Errorf("failed to load metadata: %w", err)
 	}
@@ -68,6 +72,14 @@ func runParent(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("branch '%s' has no parent", branchName)
 	}
 
+	if parentJSON {
+		s, err := stack.BuildStack(repo, cfg, metadata)
+		if err != nil {
+			return fmt.Errorf("failed to build stack: %w", err)
+		}
+		return printStackJSON(repo, s, metadata, []string{parent})
+	}
+
 	fmt.Println(parent)
 	return nil
 }
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/forge"
+	"github.com/israelmalagutti/git-wrapper/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+var prAll bool
+
+var prCmd = &cobra.Command{
+	Use:   "pr <command>",
+	Short: "Manage the pull requests of a stack",
+	Long: `Manage the pull requests of a stack.
+
+Available commands:
+  sync-descriptions    Update the stack table in each pull request description
+
+Example:
+  gw pr sync-descriptions`,
+}
+
+var prSyncDescriptionsCmd = &cobra.Command{
+	Use:   "sync-descriptions",
+	Short: "Update the stack table in each pull request description",
+	Long: `Write a table listing every pull request of the stack into the description
+of each open pull request, with that pull request highlighted.
+
+The table lives between <!-- gw:stack:start --> and <!-- gw:stack:end -->
+markers. Only that block is rewritten; the rest of the description is left
+as the author wrote it. Descriptions without a block get one appended.
+
+By default the stack containing the current branch is updated.
+
+Example:
+  gw pr sync-descriptions          # Update the current stack's pull requests
+  gw pr sync-descriptions --all    # Update every stack`,
+	Args: cobra.NoArgs,
+	RunE: withOperationLog("pr sync-descriptions", runPRSyncDescriptions),
+}
+
+func init() {
+	prSyncDescriptionsCmd.Flags().BoolVarP(&prAll, "all", "a", false, "Update the pull requests of every stack")
+	prCmd.AddCommand(prSyncDescriptionsCmd)
+	rootCmd.AddCommand(prCmd)
+}
+
+// stackPullRequest pairs a branch with its pull request
+type stackPullRequest struct {
+	node *stack.Node
+	pr   *forge.PullRequest
+}
+
+func runPRSyncDescriptions(cmd *cobra.Command, args []string) error {
+	// Initialize repository
+	repo, err := openRepo()
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	// Load config
+	cfg, err := config.Load(repo.GetConfigPath())
+	if err != nil {
+		return err
+	}
+
+	// Load metadata
+	metadata, err := config.LoadRepoMetadata(repo)
+	if err != nil {
+		return fmt.Errorf("failed to load metadata: %w", err)
+	}
+
+	s, err := stack.BuildStack(repo, cfg, metadata)
+	if err != nil {
+		return fmt.Errorf("failed to build stack: %w", err)
+	}
+
+	bottom := ""
+	if !prAll {
+		currentBranch, err := repo.GetCurrentBranch()
+		if err != nil {
+			return fmt.Errorf("failed to get current branch: %w", err)
+		}
+		if currentBranch == cfg.Trunk || !metadata.IsTracked(currentBranch) {
+			return fmt.Errorf("branch '%s' is not part of a stack\nCheck out a stacked branch or use --all", currentBranch)
+		}
+		bottom = stackBottom(s, currentBranch)
+	}
+
+	provider, err := openForge(repo, cfg)
+	if err != nil {
+		return err
+	}
+
+	// Group the stacks' pull requests, keeping topological order within each
+	stacks := map[string][]stackPullRequest{}
+	bottoms := []string{}
+	found := false
+	for _, node := range s.GetTopologicalOrder() {
+		nodeBottom := stackBottom(s, node.Name)
+		if bottom != "" && nodeBottom != bottom {
+			continue
+		}
+
+		pr, err := branchPullRequest(provider, metadata, node.Name)
+		if err != nil {
+			return err
+		}
+		if pr == nil {
+			continue
+		}
+		if metadata.GetPRNumber(node.Name) != pr.Number {
+			if err := metadata.SetPRNumber(node.Name, pr.Number); err != nil {
+				return err
+			}
+			found = true
+		}
+
+		if _, ok := stacks[nodeBottom]; !ok {
+			bottoms = append(bottoms, nodeBottom)
+		}
+		stacks[nodeBottom] = append(stacks[nodeBottom], stackPullRequest{node: node, pr: pr})
+	}
+
+	if found {
+		if err := config.SaveRepoMetadata(repo, metadata); err != nil {
+			return fmt.Errorf("failed to save metadata: %w", err)
+		}
+	}
+
+	if len(bottoms) == 0 {
+		fmt.Println("No pull requests found. Run 'gw submit' first.")
+		return nil
+	}
+
+	for _, b := range bottoms {
+		entries := stacks[b]
+		for _, entry := range entries {
+			if entry.pr.State != forge.StateOpen {
+				continue
+			}
+
+			body := forge.ReplaceStackBlock(entry.pr.Body, renderStackTable(s, entries, entry.node.Name))
+			if body == entry.pr.Body {
+				fmt.Printf("%s PR #%d (%s) is up to date\n", colors.Muted("•"), entry.pr.Number, entry.node.Name)
+				continue
+			}
+
+			if _, err := provider.UpdatePullRequest(entry.pr.Number, forge.UpdateOptions{Body: &body}); err != nil {
+				return fmt.Errorf("failed to update PR #%d: %w", entry.pr.Number, err)
+			}
+			fmt.Printf("%s Updated description of PR #%d (%s)\n", colors.Success("✓"), entry.pr.Number, colors.BranchCurrent(entry.node.Name))
+		}
+	}
+
+	return nil
+}
+
+// stackBottom returns the branch directly on trunk that branch is stacked on
+func stackBottom(s *stack.Stack, branch string) string {
+	downstack := s.GetDownstack(branch)
+	if len(downstack) == 0 {
+		return branch
+	}
+	return downstack[0].Name
+}
+
+// branchPullRequest returns the branch's pull request, or nil if it has none
+func branchPullRequest(provider forge.Provider, metadata *config.Metadata, branch string) (*forge.PullRequest, error) {
+	if number := metadata.GetPRNumber(branch); number != 0 {
+		pr, err := provider.GetPullRequest(number)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get PR #%d: %w", number, err)
+		}
+		return pr, nil
+	}
+
+	pr, err := provider.FindPullRequest(branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find PR for %s: %w", branch, err)
+	}
+	return pr, nil
+}
+
+// renderStackTable lists the stack's pull requests as a nested markdown list, highlighting current
+func renderStackTable(s *stack.Stack, entries []stackPullRequest, current string) string {
+	listed := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		listed[entry.node.Name] = true
+	}
+
+	var table strings.Builder
+	table.WriteString("**Stack**\n\n")
+	table.WriteString(fmt.Sprintf("- `%s`\n", s.TrunkName))
+	for _, entry := range entries {
+		// Indent under the closest ancestor that is listed
+		depth := 1
+		for parent := entry.node.Parent; parent != nil && !parent.IsTrunk; parent = parent.Parent {
+			if listed[parent.Name] {
+				depth++
+			}
+		}
+
+		line := fmt.Sprintf("#%d `%s`", entry.pr.Number, entry.node.Name)
+		if entry.pr.State == forge.StateMerged {
+			line += " (merged)"
+		}
+		if entry.node.Name == current {
+			line = "**" + line + "** ← this PR"
+		}
+		table.WriteString(strings.Repeat("  ", depth) + "- " + line + "\n")
+	}
+	return table.String()
+}
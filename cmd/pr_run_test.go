@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/forge"
+	"github.com/israelmalagutti/git-wrapper/internal/forge/forgetest"
+	"github.com/israelmalagutti/git-wrapper/internal/oplog"
+)
+
+func TestRunPRSyncDescriptions(t *testing.T) {
+	repo, server := setupSubmitRepo(t)
+
+	prevAll := prAll
+	defer func() { prAll = prevAll }()
+	prAll = false
+
+	for _, branch := range []string{"feat-a", "feat-b"} {
+		if err := runCreate(nil, []string{branch}); err != nil {
+			t.Fatalf("create %s failed: %v", branch, err)
+		}
+	}
+	if err := repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	if err := runCreate(nil, []string{"other"}); err != nil {
+		t.Fatalf("create other failed: %v", err)
+	}
+
+	numberA := server.AddPull(forgetest.PullRequest{Head: "feat-a", Base: "main", State: "open", Body: "Adds the API."})
+	numberB := server.AddPull(forgetest.PullRequest{Head: "feat-b", Base: "feat-a", State: "open"})
+	numberOther := server.AddPull(forgetest.PullRequest{Head: "other", Base: "main", State: "open", Body: "Unrelated"})
+
+	if err := runPRSyncDescriptions(nil, nil); err != nil {
+		t.Fatalf("sync-descriptions on other failed: %v", err)
+	}
+	if body := server.Pull(numberA).Body; body != "Adds the API." {
+		t.Fatalf("expected feat-a's PR to be untouched from another stack, got %q", body)
+	}
+
+	if err := repo.CheckoutBranch("feat-b"); err != nil {
+		t.Fatalf("failed to checkout feat-b: %v", err)
+	}
+	if err := runPRSyncDescriptions(nil, nil); err != nil {
+		t.Fatalf("sync-descriptions failed: %v", err)
+	}
+
+	bodyA := server.Pull(numberA).Body
+	wantA := "Adds the API.\n\n" + forge.StackBlockStart + "\n**Stack**\n\n- `main`\n  - **#1 `feat-a`** ← this PR\n    - #2 `feat-b`\n" + forge.StackBlockEnd
+	if bodyA != wantA {
+		t.Fatalf("unexpected feat-a body:\n%s", bodyA)
+	}
+	if body := server.Pull(numberB).Body; !strings.Contains(body, "- #1 `feat-a`\n    - **#2 `feat-b`** ← this PR") {
+		t.Fatalf("unexpected feat-b body:\n%s", body)
+	}
+	if body := server.Pull(numberOther).Body; !strings.HasPrefix(body, "Unrelated\n\n") || strings.Contains(body, "feat-a") {
+		t.Fatalf("unexpected body for other stack:\n%s", body)
+	}
+
+	// Edits outside the block survive, and the block follows the stack
+	server.UpdatePull(numberA, func(pr *forgetest.PullRequest) {
+		pr.Body = strings.Replace(pr.Body, "Adds the API.", "Adds the API, now with docs.", 1)
+	})
+	server.UpdatePull(numberB, func(pr *forgetest.PullRequest) { pr.State = "closed"; pr.Merged = true })
+	prAll = true
+	if err := runPRSyncDescriptions(nil, nil); err != nil {
+		t.Fatalf("sync-descriptions --all failed: %v", err)
+	}
+	bodyA = server.Pull(numberA).Body
+	if !strings.HasPrefix(bodyA, "Adds the API, now with docs.\n\n") || !strings.Contains(bodyA, "#2 `feat-b` (merged)") {
+		t.Fatalf("unexpected feat-a body after edit:\n%s", bodyA)
+	}
+	if strings.Count(bodyA, forge.StackBlockStart) != 1 {
+		t.Fatalf("expected a single managed block:\n%s", bodyA)
+	}
+}
+
+func TestRunPRSyncDescriptionsIsRecorded(t *testing.T) {
+	repo, server := setupSubmitRepo(t)
+
+	prevAll := prAll
+	defer func() { prAll = prevAll }()
+	prAll = false
+
+	if err := runCreate(nil, []string{"feat-a"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	number := server.AddPull(forgetest.PullRequest{Head: "feat-a", Base: "main", State: "open"})
+
+	if err := withOperationLog("pr sync-descriptions", runPRSyncDescriptions)(nil, nil); err != nil {
+		t.Fatalf("sync-descriptions failed: %v", err)
+	}
+
+	metadata, err := config.LoadRepoMetadata(repo)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if metadata.GetPRNumber("feat-a") != number {
+		t.Fatalf("expected PR #%d to be recorded, got %d", number, metadata.GetPRNumber("feat-a"))
+	}
+
+	// The recorded number is part of the log, so undoing an earlier operation keeps it
+	log, err := oplog.Load(repo.GetOperationLogPath())
+	if err != nil {
+		t.Fatalf("failed to load log: %v", err)
+	}
+	last := log.LastApplied()
+	if last == nil || last.Command != "pr sync-descriptions" || last.MetadataAfter.GetPRNumber("feat-a") != number {
+		t.Fatalf("expected sync-descriptions to be recorded, got %+v", last)
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/forge"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+)
+
+// loadPRStatuses returns cached pull request statuses, fetching fresh ones first when refresh is set.
+// A failed refresh is reported and the cached statuses are used instead.
+func loadPRStatuses(repo *git.Repo, cfg *config.Config, metadata *config.Metadata, refresh bool) (*forge.Cache, error) {
+	cache, err := forge.LoadCache(repo.GetPRCachePath())
+	if err != nil {
+		return nil, err
+	}
+	if !refresh {
+		return cache, nil
+	}
+
+	refreshed, err := refreshPRStatuses(repo, cfg, metadata)
+	if err != nil {
+		fmt.Printf("%s Could not refresh pull request status: %v\n", colors.Warning("⚠"), err)
+		fmt.Println(colors.Muted("  Showing cached status"))
+		return cache, nil
+	}
+	return refreshed, nil
+}
+
+// refreshPRStatuses asks the forge about every tracked branch and rewrites the cache,
+// which a dry run leaves alone
+func refreshPRStatuses(repo *git.Repo, cfg *config.Config, metadata *config.Metadata) (*forge.Cache, error) {
+	provider, err := forge.Open(repo, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := &forge.Cache{Fetched: time.Now(), Branches: make(map[string]*forge.Status)}
+	branches := make([]string, 0, len(metadata.Branches))
+	for branch := range metadata.Branches {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+
+	for _, branch := range branches {
+		number := metadata.GetPRNumber(branch)
+		if number == 0 {
+			pr, err := provider.FindPullRequest(branch)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find PR for %s: %w", branch, err)
+			}
+			if pr == nil {
+				continue
+			}
+			number = pr.Number
+		}
+
+		status, err := provider.GetStatus(number)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get status of PR #%d: %w", number, err)
+		}
+		cache.Branches[branch] = status
+	}
+
+	if dryRun {
+		return cache, nil
+	}
+	if err := cache.Save(repo.GetPRCachePath()); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+// rememberPRStatus records a just-submitted pull request in the status cache.
+// Nothing is recorded during a dry run, where the pull request was never opened.
+func rememberPRStatus(repo *git.Repo, branch string, pr *forge.PullRequest) error {
+	if dryRun {
+		return nil
+	}
+
+	cache, err := forge.LoadCache(repo.GetPRCachePath())
+	if err != nil {
+		return err
+	}
+
+	status := &forge.Status{Number: pr.Number, URL: pr.URL, State: pr.State, Draft: pr.Draft}
+	if previous := cache.Branches[branch]; previous != nil && previous.Number == pr.Number {
+		status.ReviewDecision = previous.ReviewDecision
+		status.CIStatus = previous.CIStatus
+	}
+	cache.Branches[branch] = status
+	return cache.Save(repo.GetPRCachePath())
+}
+
+// prAnnotations formats the cached statuses for display next to branch names
+func prAnnotations(cache *forge.Cache) map[string]string {
+	annotations := make(map[string]string, len(cache.Branches))
+	for branch, status := range cache.Branches {
+		annotations[branch] = formatPRStatus(status)
+	}
+	return annotations
+}
+
+// formatPRStatus renders a status as "#12 open · approved · CI passing"
+func formatPRStatus(status *forge.Status) string {
+	parts := []string{}
+
+	number := fmt.Sprintf("#%d", status.Number)
+	switch {
+	case status.State == forge.StateMerged:
+		parts = append(parts, colors.Success(number+" merged"))
+	case status.State == forge.StateClosed:
+		parts = append(parts, colors.Muted(number+" closed"))
+	case status.Draft:
+		parts = append(parts, colors.StatusDraft(number+" draft"))
+	default:
+		parts = append(parts, colors.Info(number+" open"))
+	}
+
+	switch status.ReviewDecision {
+	case forge.ReviewApproved:
+		parts = append(parts, colors.StatusApproved("approved"))
+	case forge.ReviewChangesRequested:
+		parts = append(parts, colors.StatusChangesRequested("changes requested"))
+	case forge.ReviewRequired:
+		parts = append(parts, colors.StatusPending("review required"))
+	}
+
+	switch status.CIStatus {
+	case forge.CISuccess:
+		parts = append(parts, colors.StatusApproved("CI passing"))
+	case forge.CIFailure:
+		parts = append(parts, colors.Error("CI failing"))
+	case forge.CIPending:
+		parts = append(parts, colors.StatusPending("CI pending"))
+	}
+
+	return strings.Join(parts, colors.Muted(" · "))
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/israelmalagutti/git-wrapper/internal/colors"
 	"github.com/israelmalagutti/git-wrapper/internal/config"
-	"github.com/israelmalagutti/git-wrapper/internal/git"
 	"github.com/spf13/cobra"
 )
 
@@ -23,7 +22,7 @@ This updates both the git branch name and gw metadata.
 Example:
   gw rename feat-new-name    # Rename current branch
   gw rename                  # Prompt for new name`,
-	RunE: runRename,
+	RunE: withOperationLog("rename", runRename),
 }
 
 func init() {
@@ -32,7 +31,7 @@ func init() {
 
 func runRename(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -89,7 +88,7 @@ func runRename(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load metadata
-	metadata, err := config.LoadMetadata(repo.GetMetadataPath())
+	metadata, err := config.LoadRepoMetadata(repo)
 	if err != nil {
 		return fmt.Errorf("failed to load metadata: %w", err)
 	}
@@ -102,6 +101,7 @@ func runRename(cmd *cobra.Command, args []string) error {
 	// Update metadata if branch is tracked
 	if metadata.IsTracked(currentBranch) {
 		parent, _ := metadata.GetParent(currentBranch)
+		parentSHA := metadata.GetParentSHA(currentBranch)
 		children := metadata.GetChildren(currentBranch)
 
 		// Untrack old name
@@ -109,17 +109,20 @@ func runRename(cmd *cobra.Command, args []string) error {
 
 		// Track with new name
 		metadata.TrackBranch(newName, parent)
+		_ = metadata.SetParentSHA(newName, parentSHA)
 
 		// Update children to point to new parent name
 		for _, child := range children {
 			childParent, _ := metadata.GetParent(child)
 			if childParent == currentBranch {
-				metadata.TrackBranch(child, newName)
+				if err := metadata.UpdateParent(child, newName); err != nil {
+					return fmt.Errorf("failed to update child '%s': %w", child, err)
+				}
 			}
 		}
 
 		// Save metadata
-		if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+		if err := config.SaveRepoMetadata(repo, metadata); err != nil {
 			// Try to rollback git rename
 			_, _ = repo.RunGitCommand("branch", "-m", newName, currentBranch)
 			return fmt.Errorf("failed to save metadata: %w", err)
@@ -0,0 +1,338 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+var reorderCmd = &cobra.Command{
+	Use:   "reorder",
+	Short: "Reorder the branches between trunk and the current branch",
+	Long: `Reorder the branches between trunk and the current branch in your editor.
+
+The editor opens with one line per branch, the one on trunk first, like the
+todo list of an interactive rebase. Move the lines into the new order, save
+and quit. Each branch is then stacked on the line above it and its commits
+are replayed there; branches stacked beside the reordered ones follow their
+parents.
+
+All commits are replayed in memory first. If any of them would conflict,
+nothing is changed. Save the list unchanged or empty it to cancel.`,
+	Args: cobra.NoArgs,
+	RunE: withOperationLog("reorder", runReorder),
+}
+
+func init() {
+	rootCmd.AddCommand(reorderCmd)
+}
+
+// reorderBranch is a branch to replay: onto the new tip of parent, from base
+type reorderBranch struct {
+	name   string
+	parent string
+	base   string
+	tip    string
+}
+
+func runReorder(cmd *cobra.Command, args []string) error {
+	// Initialize repository
+	repo, err := openRepo()
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	// Load config
+	cfg, err := config.Load(repo.GetConfigPath())
+	if err != nil {
+		return err
+	}
+
+	// Load metadata
+	metadata, err := config.LoadRepoMetadata(repo)
+	if err != nil {
+		return fmt.Errorf("failed to load metadata: %w", err)
+	}
+
+	// Get current branch
+	currentBranch, err := repo.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	if currentBranch == cfg.Trunk {
+		return fmt.Errorf("cannot reorder from trunk; check out the top of the stack to reorder")
+	}
+
+	if !metadata.IsTracked(currentBranch) {
+		return fmt.Errorf("branch '%s' is not tracked by gw", currentBranch)
+	}
+
+	// Build stack
+	s, err := stack.BuildStack(repo, cfg, metadata)
+	if err != nil {
+		return fmt.Errorf("failed to build stack: %w", err)
+	}
+
+	current := nodeNames(s.FindPath(currentBranch)[1:])
+	if len(current) < 2 {
+		return fmt.Errorf("nothing to reorder: '%s' is the only branch on %s", currentBranch, cfg.Trunk)
+	}
+
+	order, err := editReorder(repo, s, current)
+	if err != nil {
+		return err
+	}
+	if order == nil || strings.Join(order, " ") == strings.Join(current, " ") {
+		fmt.Println("Order unchanged.")
+		return nil
+	}
+
+	branches, err := planReorder(repo, metadata, s, order)
+	if err != nil {
+		return err
+	}
+
+	newTips, err := replayReorder(repo, branches)
+	if err != nil {
+		return err
+	}
+
+	if err := applyReorder(repo, metadata, currentBranch, branches, newTips); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Reordered stack: %s\n", colors.Success("✓"),
+		strings.Join(append([]string{cfg.Trunk}, order...), colors.Muted(" → ")))
+	return nil
+}
+
+// editReorder opens the todo list for branches in the editor and returns the
+// new order, or nil if the list was emptied
+func editReorder(repo *git.Repo, s *stack.Stack, branches []string) ([]string, error) {
+	var todo strings.Builder
+	for _, name := range branches {
+		todo.WriteString(name)
+		if subject, err := repo.RunGitCommand("log", "-1", "--format=%s", name, "--"); err == nil && subject != "" {
+			todo.WriteString("  # " + subject)
+		}
+		todo.WriteString("\n")
+	}
+	fmt.Fprintf(&todo, `
+# Reorder the branches between %s and %s.
+#
+# The first line is stacked on %s and each line after it on the one above.
+# Move lines to change the order. Lines cannot be added or removed; remove
+# every line to cancel.
+`, s.TrunkName, branches[len(branches)-1], s.TrunkName)
+
+	tmpDir, err := os.MkdirTemp("", "gw-reorder-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create todo file: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "gw-reorder-todo")
+	if err := os.WriteFile(path, []byte(todo.String()), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write todo file: %w", err)
+	}
+	if err := openEditor(repo, path); err != nil {
+		return nil, err
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read todo file: %w", err)
+	}
+	return parseReorder(string(edited), branches)
+}
+
+// parseReorder reads the edited todo list, which must name each of branches once
+func parseReorder(todo string, branches []string) ([]string, error) {
+	expected := map[string]bool{}
+	for _, name := range branches {
+		expected[name] = true
+	}
+
+	order := []string{}
+	seen := map[string]bool{}
+	for _, line := range strings.Split(todo, "\n") {
+		// '#' may appear in branch names, so only a leading '#' starts a comment;
+		// notes after a branch name are separated by whitespace
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		name := fields[0]
+		switch {
+		case !expected[name]:
+			return nil, fmt.Errorf("'%s' is not one of the branches being reordered", name)
+		case seen[name]:
+			return nil, fmt.Errorf("'%s' is listed more than once", name)
+		}
+		seen[name] = true
+		order = append(order, name)
+	}
+
+	if len(order) == 0 {
+		return nil, nil
+	}
+	for _, name := range branches {
+		if !seen[name] {
+			return nil, fmt.Errorf("'%s' was removed from the list; use 'gw delete' or 'gw move' to take a branch out of the stack", name)
+		}
+	}
+	return order, nil
+}
+
+// planReorder lists every branch to replay, parents first: the reordered branches
+// stacked on each other, then the branches beside them on their existing parents
+func planReorder(repo *git.Repo, metadata *config.Metadata, s *stack.Stack, order []string) ([]reorderBranch, error) {
+	reordered := map[string]bool{}
+	for _, name := range order {
+		reordered[name] = true
+	}
+
+	var branches []reorderBranch
+	add := func(name, parent string) error {
+		oldParent, _ := metadata.GetParent(name)
+		base, err := repo.GetRestackBase(name, oldParent, metadata.GetParentSHA(name))
+		if err != nil {
+			return fmt.Errorf("failed to find where '%s' starts: %w", name, err)
+		}
+		tip, err := repo.GetBranchCommit(name)
+		if err != nil {
+			return err
+		}
+		branches = append(branches, reorderBranch{name: name, parent: parent, base: base, tip: tip})
+		return nil
+	}
+
+	parent := s.TrunkName
+	for _, name := range order {
+		if err := add(name, parent); err != nil {
+			return nil, err
+		}
+		parent = name
+	}
+
+	for _, name := range order {
+		for _, child := range s.GetNode(name).SortedChildren() {
+			if reordered[child.Name] {
+				continue
+			}
+			for _, branch := range append([]string{child.Name}, descendantNames(child)...) {
+				branchParent, _ := metadata.GetParent(branch)
+				if err := add(branch, branchParent); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return branches, nil
+}
+
+// replayReorder replays each branch onto its parent's new tip without moving
+// any ref, and returns every branch's new tip
+func replayReorder(repo *git.Repo, branches []reorderBranch) (map[string]string, error) {
+	newTips := map[string]string{}
+	for _, b := range branches {
+		onto, ok := newTips[b.parent]
+		if !ok {
+			var err error
+			if onto, err = repo.GetBranchCommit(b.parent); err != nil {
+				return nil, err
+			}
+		}
+
+		tip, conflicts, err := repo.ReplayCommits(b.tip, onto, b.base)
+		if errors.Is(err, git.ErrCannotReplay) {
+			return nil, fmt.Errorf("cannot reorder '%s' because it contains merge commits", b.name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(conflicts) > 0 {
+			return nil, fmt.Errorf("'%s' would conflict on %s in %s; nothing was changed", b.name, b.parent, strings.Join(conflicts, ", "))
+		}
+		newTips[b.name] = tip
+	}
+	return newTips, nil
+}
+
+// applyReorder moves the branch refs to their new tips, updating the working
+// tree for the current branch, and records the new parents
+func applyReorder(repo *git.Repo, metadata *config.Metadata, currentBranch string, branches []reorderBranch, newTips map[string]string) error {
+	checkedOut, err := repo.CheckedOutBranches()
+	if err != nil {
+		return err
+	}
+
+	var updates []git.RefUpdate
+	moveCurrent := false
+	for _, b := range branches {
+		if newTips[b.name] == b.tip {
+			continue
+		}
+		if b.name == currentBranch {
+			moveCurrent = true
+		} else if checkedOut[b.name] {
+			return fmt.Errorf("branch '%s' is checked out in another worktree; nothing was changed", b.name)
+		}
+		updates = append(updates, git.RefUpdate{Ref: "refs/heads/" + b.name, OldSHA: b.tip, NewSHA: newTips[b.name]})
+	}
+
+	// The working tree follows the current branch, so it has to be clean
+	if moveCurrent {
+		if hasTrackedChanges(repo) {
+			return fmt.Errorf("you have uncommitted changes; commit or stash them before reordering")
+		}
+		if _, err := repo.RunGitCommand("checkout", "--detach"); err != nil {
+			return fmt.Errorf("failed to detach HEAD: %w", err)
+		}
+	}
+
+	if err := repo.UpdateRefs(updates); err != nil {
+		if moveCurrent {
+			_ = repo.CheckoutBranch(currentBranch)
+		}
+		return fmt.Errorf("failed to update branches: %w", err)
+	}
+
+	if moveCurrent {
+		if err := repo.CheckoutBranch(currentBranch); err != nil {
+			return fmt.Errorf("branches were reordered but checking out '%s' failed: %w", currentBranch, err)
+		}
+	}
+
+	for _, b := range branches {
+		if err := metadata.UpdateParent(b.name, b.parent); err != nil {
+			return err
+		}
+		// Only the bottom branch's parent, trunk, is not in newTips
+		parentTip, ok := newTips[b.parent]
+		if !ok {
+			if parentTip, err = repo.GetBranchCommit(b.parent); err != nil {
+				return err
+			}
+		}
+		if err := metadata.SetParentSHA(b.name, parentTip); err != nil {
+			return err
+		}
+	}
+
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
+		return fmt.Errorf("failed to save metadata: %w", err)
+	}
+	return nil
+}
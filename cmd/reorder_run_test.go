@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+)
+
+// withEditor replaces the editor with one that writes contents over the file
+func withEditor(t *testing.T, contents string, fn func()) {
+	t.Helper()
+	prev := openEditor
+	openEditor = func(_ *git.Repo, path string) error {
+		return os.WriteFile(path, []byte(contents), 0644)
+	}
+	defer func() { openEditor = prev }()
+	fn()
+}
+
+func TestRunReorder(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.commitFile(t, "a.txt", "a", "a commit")
+	repo.createBranch(t, "feat-x", "feat-a")
+	repo.commitFile(t, "x.txt", "x", "x commit")
+	repo.createBranch(t, "feat-b", "feat-a")
+	repo.commitFile(t, "b.txt", "b", "b commit")
+	repo.createBranch(t, "feat-c", "feat-b")
+	repo.commitFile(t, "c.txt", "c", "c commit")
+
+	withEditor(t, "feat-b\n# a comment\nfeat-a  # a commit\nfeat-c\n", func() {
+		if err := runReorder(nil, nil); err != nil {
+			t.Fatalf("runReorder failed: %v", err)
+		}
+	})
+
+	metadata, err := config.LoadRepoMetadata(repo.repo)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	for branch, parent := range map[string]string{"feat-b": "main", "feat-a": "feat-b", "feat-c": "feat-a", "feat-x": "feat-a"} {
+		if got, _ := metadata.GetParent(branch); got != parent {
+			t.Fatalf("expected %s on %s, got %s", branch, parent, got)
+		}
+		if !repo.repo.IsAncestor(parent, branch) {
+			t.Fatalf("expected %s to be stacked on %s", branch, parent)
+		}
+		parentSHA, _ := repo.repo.GetBranchCommit(parent)
+		if metadata.GetParentSHA(branch) != parentSHA {
+			t.Fatalf("expected %s to record the tip of %s", branch, parent)
+		}
+	}
+
+	// feat-b no longer carries feat-a's commit
+	if files, _ := repo.repo.RunGitCommand("diff", "--name-only", "main", "feat-b"); files != "b.txt" {
+		t.Fatalf("expected feat-b to change only b.txt, got %q", files)
+	}
+
+	// The current branch moved with its working tree
+	if current, _ := repo.repo.GetCurrentBranch(); current != "feat-c" {
+		t.Fatalf("expected to stay on feat-c, got %s", current)
+	}
+	if status, _ := repo.repo.RunGitCommand("status", "--porcelain"); status != "" {
+		t.Fatalf("expected a clean working tree, got %q", status)
+	}
+}
+
+func TestRunReorderConflict(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.commitFile(t, "shared.txt", "a", "a commit")
+	repo.createBranch(t, "feat-b", "feat-a")
+	repo.commitFile(t, "shared.txt", "b", "b commit")
+
+	before := map[string]string{}
+	for _, branch := range []string{"feat-a", "feat-b"} {
+		before[branch], _ = repo.repo.GetBranchCommit(branch)
+	}
+
+	withEditor(t, "feat-b\nfeat-a\n", func() {
+		err := runReorder(nil, nil)
+		if err == nil || !strings.Contains(err.Error(), "nothing was changed") {
+			t.Fatalf("expected conflict error, got %v", err)
+		}
+	})
+
+	for branch, sha := range before {
+		if after, _ := repo.repo.GetBranchCommit(branch); after != sha {
+			t.Fatalf("expected %s to be unchanged", branch)
+		}
+	}
+	metadata, err := config.LoadRepoMetadata(repo.repo)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if parent, _ := metadata.GetParent("feat-b"); parent != "feat-a" {
+		t.Fatalf("expected feat-b to stay on feat-a, got %s", parent)
+	}
+}
+
+func TestRunReorderUnchanged(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	if err := runReorder(nil, nil); err == nil || !strings.Contains(err.Error(), "nothing to reorder") {
+		t.Fatalf("expected nothing to reorder error, got %v", err)
+	}
+
+	repo.createBranch(t, "feat-b", "feat-a")
+	withEditor(t, "", func() {
+		if err := runReorder(nil, nil); err != nil {
+			t.Fatalf("runReorder failed: %v", err)
+		}
+	})
+	metadata, err := config.LoadRepoMetadata(repo.repo)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if parent, _ := metadata.GetParent("feat-b"); parent != "feat-a" {
+		t.Fatalf("expected feat-b to stay on feat-a, got %s", parent)
+	}
+}
+
+func TestParseReorder(t *testing.T) {
+	branches := []string{"a", "b", "c"}
+
+	order, err := parseReorder("c # top\n\n# comment\nb\na\n", branches)
+	if err != nil || strings.Join(order, " ") != "c b a" {
+		t.Fatalf("unexpected order %v, %v", order, err)
+	}
+
+	for todo, want := range map[string]string{
+		"a\nb\n":    "'c' was removed",
+		"a\nb\nb\n": "listed more than once",
+		"a\nb\nd\n": "'d' is not one of the branches",
+	} {
+		if _, err := parseReorder(todo, branches); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("parseReorder(%q) = %v, want %q", todo, err, want)
+		}
+	}
+
+	if order, err := parseReorder("# all removed\n", branches); order != nil || err != nil {
+		t.Errorf("expected an empty list to cancel, got %v, %v", order, err)
+	}
+
+	// '#' inside a branch name is not a comment
+	order, err = parseReorder("fix#12  # 1 commit\nb\n  # indented comment\n", []string{"b", "fix#12"})
+	if err != nil || strings.Join(order, " ") != "fix#12 b" {
+		t.Errorf("unexpected order for a branch with '#': %v, %v", order, err)
+	}
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+)
+
+// newGitRunner returns what commands run git through; tests can replace it
+// with a scripted runner
+var newGitRunner = git.DefaultRunner
+
+// repoContext is what the repos commands open run git in. Execute replaces
+// it with one that is cancelled on interrupt.
+var repoContext = context.Background()
+
+// openRepo opens the repository the current directory is in
+func openRepo() (*git.Repo, error) {
+	repo, err := git.NewRepoWithRunner(newGitRunner())
+	if err != nil {
+		return nil, err
+	}
+	return repo.WithContext(repoContext), nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/git/gittest"
+)
+
+// withGitRunner makes commands run git through runner until the test ends
+func withGitRunner(t *testing.T, runner git.Runner) {
+	t.Helper()
+	prev := newGitRunner
+	newGitRunner = func() git.Runner { return runner }
+	t.Cleanup(func() { newGitRunner = prev })
+}
+
+func TestRunParentScripted(t *testing.T) {
+	dir := t.TempDir()
+	gitDir := filepath.Join(dir, ".git")
+	if err := os.MkdirAll(gitDir, 0755); err != nil {
+		t.Fatalf("failed to create .git: %v", err)
+	}
+	files := map[string]string{
+		".gw_config":         `{"version":"1","trunk":"main","initialized":"2026-01-01T00:00:00Z"}`,
+		".gw_stack_metadata": `{"branches":{"feat-a":{"parent":"main"},"feat-b":{"parent":"feat-a"}}}`,
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(gitDir, name), []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	runner := gittest.NewRunner(t)
+	withGitRunner(t, runner)
+
+	runner.ExpectRepo(dir)
+	runner.Expect("branch", "--show-current").Returns("feat-b\n")
+	if err := runParent(nil, nil); err != nil {
+		t.Fatalf("runParent failed: %v", err)
+	}
+
+	runner.ExpectRepo(dir)
+	runner.Expect("branch", "--show-current").Fails(128, "fatal: not a git repository\n")
+	if err := runParent(nil, nil); err == nil || !strings.Contains(err.Error(), "failed to get current branch") {
+		t.Fatalf("expected current branch error, got %v", err)
+	}
+}
@@ -18,12 +18,22 @@ This command:
 - Recursively restacks all children branches
 - Handles conflicts interactively
 
+By default the current branch and its descendants are restacked. Use
+--only, --downstack or --stack to restack just the branch, the branch and
+its ancestors, or its whole stack instead.
+
+With --dry-run nothing is changed: each branch that needs a restack is
+replayed in memory and the stack is shown with the predicted outcome.
+
 Example:
   gw restack    # Restack current branch and children
+  gw restack --dry-run  # Predict conflicts without restacking
+  gw restack --stack    # Restack the whole stack
   gw rs         # Short alias`,
-	RunE: runStackRestack,
+	RunE: withOperationLog("restack", runStackRestack),
 }
 
 func init() {
+	addScopeFlags(restackCmd, &restackScope, "Restack", allScopes...)
 	rootCmd.AddCommand(restackCmd)
 }
@@ -3,6 +3,7 @@ package cmd
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -24,7 +25,7 @@ func TestRestackBranchSuccess(t *testing.T) {
 		t.Fatalf("failed to commit main: %v", err)
 	}
 
-	if err := restackBranch(repo.repo, "feat-rebase", "main"); err != nil {
+	if err := restackBranch(repo.repo, repo.metadata, "feat-rebase", "main"); err != nil {
 		t.Fatalf("restackBranch failed: %v", err)
 	}
 }
@@ -60,8 +61,94 @@ func TestRestackBranchConflict(t *testing.T) {
 		t.Fatalf("failed to commit main: %v", err)
 	}
 
-	if err := restackBranch(repo.repo, "feat-conflict", "main"); err == nil {
+	if err := restackBranch(repo.repo, repo.metadata, "feat-conflict", "main"); err == nil {
 		t.Fatalf("expected restack conflict error")
 	}
 	_, _ = repo.repo.RunGitCommand("rebase", "--abort")
 }
+
+func TestRestackBranchAfterParentAmend(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-parent", "main")
+	repo.commitFile(t, "parent.txt", "v1", "parent commit")
+	repo.createBranch(t, "feat-child", "feat-parent")
+	repo.commitFile(t, "child.txt", "child", "child commit")
+
+	parentSHA, err := repo.repo.GetBranchCommit("feat-parent")
+	if err != nil {
+		t.Fatalf("failed to get parent commit: %v", err)
+	}
+	if err := repo.metadata.SetParentSHA("feat-child", parentSHA); err != nil {
+		t.Fatalf("failed to record parent sha: %v", err)
+	}
+
+	// Amend the parent so its old commit would be replayed by a plain rebase
+	if err := repo.repo.CheckoutBranch("feat-parent"); err != nil {
+		t.Fatalf("failed to checkout parent: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo.dir, "parent.txt"), []byte("v2"), 0644); err != nil {
+		t.Fatalf("failed to write parent file: %v", err)
+	}
+	if _, err := repo.repo.RunGitCommand("commit", "-a", "--amend", "--no-edit"); err != nil {
+		t.Fatalf("failed to amend parent: %v", err)
+	}
+
+	if err := repo.repo.CheckoutBranch("feat-child"); err != nil {
+		t.Fatalf("failed to checkout child: %v", err)
+	}
+	if err := restackBranch(repo.repo, repo.metadata, "feat-child", "feat-parent"); err != nil {
+		t.Fatalf("restackBranch failed: %v", err)
+	}
+
+	count, err := countCommits(repo.repo, "feat-child", "feat-parent")
+	if err != nil {
+		t.Fatalf("countCommits failed: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected only the child commit on top of parent, got %d", count)
+	}
+
+	newParentSHA, err := repo.repo.GetBranchCommit("feat-parent")
+	if err != nil {
+		t.Fatalf("failed to get parent commit: %v", err)
+	}
+	if got := repo.metadata.GetParentSHA("feat-child"); got != newParentSHA {
+		t.Fatalf("expected recorded parent sha %s, got %s", newParentSHA, got)
+	}
+}
+
+func TestRestackBranchCurrentInMemory(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-current", "main")
+	repo.commitFile(t, "feat.txt", "feat", "feat commit")
+
+	if err := repo.repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	repo.commitFile(t, "main.txt", "main", "main commit")
+	if err := repo.repo.CheckoutBranch("feat-current"); err != nil {
+		t.Fatalf("failed to checkout feat-current: %v", err)
+	}
+
+	if err := restackBranch(repo.repo, repo.metadata, "feat-current", "main"); err != nil {
+		t.Fatalf("restackBranch failed: %v", err)
+	}
+
+	if !repo.repo.IsAncestor("main", "feat-current") {
+		t.Fatalf("expected feat-current to be restacked on main")
+	}
+	reflog, err := repo.repo.RunGitCommand("reflog", "--format=%gs", "HEAD")
+	if err != nil {
+		t.Fatalf("failed to read reflog: %v", err)
+	}
+	if strings.Contains(reflog, "rebase") {
+		t.Fatalf("expected no rebase in HEAD's reflog, got:\n%s", reflog)
+	}
+	if data, err := os.ReadFile(filepath.Join(repo.dir, "main.txt")); err != nil || string(data) != "main" {
+		t.Fatalf("expected the working tree to follow the restacked branch")
+	}
+}
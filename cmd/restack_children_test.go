@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/israelmalagutti/git-wrapper/internal/stack"
@@ -23,7 +25,78 @@ func TestRestackChildren(t *testing.T) {
 		t.Fatalf("expected trunk node")
 	}
 
-	if err := restackChildren(repo.repo, s, parent); err != nil {
+	if err := restackChildren(repo.repo, s, repo.metadata, parent); err != nil {
 		t.Fatalf("restackChildren failed: %v", err)
 	}
 }
+
+func TestRestackChildrenInMemory(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-child", "main")
+	repo.commitFile(t, "child.txt", "child", "child commit")
+	repo.createBranch(t, "feat-grandchild", "feat-child")
+	repo.commitFile(t, "grandchild.txt", "grandchild", "grandchild commit")
+
+	if err := repo.repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	repo.commitFile(t, "main.txt", "main", "main commit")
+
+	// An untracked file that checking out feat-child would overwrite
+	if err := os.WriteFile(filepath.Join(repo.dir, "child.txt"), []byte("scratch"), 0644); err != nil {
+		t.Fatalf("failed to write scratch file: %v", err)
+	}
+
+	s, err := stack.BuildStack(repo.repo, repo.cfg, repo.metadata)
+	if err != nil {
+		t.Fatalf("BuildStack failed: %v", err)
+	}
+	if err := restackChildren(repo.repo, s, repo.metadata, s.GetNode("main")); err != nil {
+		t.Fatalf("restackChildren failed: %v", err)
+	}
+
+	if current, _ := repo.repo.GetCurrentBranch(); current != "main" {
+		t.Fatalf("expected HEAD to stay on main, got %s", current)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repo.dir, "child.txt")); string(data) != "scratch" {
+		t.Fatalf("expected working tree to be untouched")
+	}
+	if !repo.repo.IsAncestor("main", "feat-child") || !repo.repo.IsAncestor("feat-child", "feat-grandchild") {
+		t.Fatalf("expected both branches to be restacked")
+	}
+	mainSHA, _ := repo.repo.GetBranchCommit("main")
+	if repo.metadata.GetParentSHA("feat-child") != mainSHA {
+		t.Fatalf("expected parent SHA to be recorded")
+	}
+}
+
+func TestRestackChildrenFallsBackOnConflict(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-child", "main")
+	repo.commitFile(t, "shared.txt", "child", "child commit")
+
+	if err := repo.repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	repo.commitFile(t, "shared.txt", "main", "main commit")
+
+	s, err := stack.BuildStack(repo.repo, repo.cfg, repo.metadata)
+	if err != nil {
+		t.Fatalf("BuildStack failed: %v", err)
+	}
+	if err := restackChildren(repo.repo, s, repo.metadata, s.GetNode("main")); err == nil {
+		t.Fatalf("expected restack to stop on the conflict")
+	}
+
+	// Only the conflicting branch is rebased in the working tree
+	if !isRebaseInProgress(repo.repo) {
+		t.Fatalf("expected a working-tree rebase to be stopped on the conflict")
+	}
+	if err := repo.repo.AbortRebase(); err != nil {
+		t.Fatalf("failed to abort rebase: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/stack"
+)
+
+// restackPrediction is the simulated outcome of restacking one branch
+type restackPrediction struct {
+	upToDate  bool
+	unknown   bool   // the commits can only be rebased in the working tree
+	blockedBy string // the ancestor that conflicts or cannot be predicted
+	conflicts []string
+}
+
+// predictRestack replays branches in order without changing any ref. Children are
+// replayed onto the predicted tip of their parent, as the real restack would do.
+// Descendants of a branch that conflicts or cannot be predicted are not replayed.
+func predictRestack(repo *git.Repo, metadata *config.Metadata, branches []string) (map[string]*restackPrediction, error) {
+	predictions := make(map[string]*restackPrediction, len(branches))
+	tips := map[string]string{}
+
+	for _, branch := range branches {
+		parent, ok := metadata.GetParent(branch)
+		if !ok {
+			continue
+		}
+
+		// The real restack stops at a conflict, so nothing above it is reached
+		if p := predictions[parent]; p != nil {
+			switch {
+			case p.blockedBy != "":
+				predictions[branch] = &restackPrediction{unknown: p.unknown, blockedBy: p.blockedBy}
+				continue
+			case len(p.conflicts) > 0 || p.unknown:
+				predictions[branch] = &restackPrediction{unknown: p.unknown, blockedBy: parent}
+				continue
+			}
+		}
+
+		recordedBase := metadata.GetParentSHA(branch)
+		behind, err := repo.IsBehind(branch, parent, recordedBase)
+		if err != nil {
+			return nil, err
+		}
+		parentTip, moved := tips[parent]
+		if !behind && !moved {
+			predictions[branch] = &restackPrediction{upToDate: true}
+			continue
+		}
+		if !moved {
+			if parentTip, err = repo.GetBranchCommit(parent); err != nil {
+				return nil, err
+			}
+		}
+
+		base, err := repo.GetRestackBase(branch, parent, recordedBase)
+		if err != nil {
+			return nil, err
+		}
+
+		newTip, conflicts, err := repo.ReplayCommits(branch, parentTip, base)
+		switch {
+		case errors.Is(err, git.ErrCannotReplay):
+			predictions[branch] = &restackPrediction{unknown: true}
+		case err != nil:
+			return nil, err
+		case len(conflicts) > 0:
+			predictions[branch] = &restackPrediction{conflicts: conflicts}
+		default:
+			predictions[branch] = &restackPrediction{}
+			tips[branch] = newTip
+		}
+	}
+
+	return predictions, nil
+}
+
+// printRestackPrediction shows the stack annotated with what restacking branches would do
+func printRestackPrediction(repo *git.Repo, s *stack.Stack, metadata *config.Metadata, branches []string) error {
+	predictions, err := predictRestack(repo, metadata, branches)
+	if err != nil {
+		return err
+	}
+
+	s.Annotations = make(map[string]string, len(predictions))
+	conflicting, blocked := 0, 0
+	for branch, prediction := range predictions {
+		s.Annotations[branch] = formatRestackPrediction(prediction)
+		if len(prediction.conflicts) > 0 {
+			conflicting++
+		} else if prediction.blockedBy != "" && !prediction.unknown {
+			blocked++
+		}
+	}
+
+	fmt.Print(s.RenderTree(repo, stack.TreeOptions{ShowCommitSHA: true}))
+	fmt.Println()
+	if conflicting > 0 {
+		fmt.Printf("%s %d branch(es) would conflict", colors.Warning("⚠"), conflicting)
+		if blocked > 0 {
+			fmt.Printf(", %d more would wait until they are resolved", blocked)
+		}
+		fmt.Println(". Nothing was changed.")
+	} else {
+		fmt.Printf("%s Restack would complete without conflicts. Nothing was changed.\n", colors.Success("✓"))
+	}
+	return nil
+}
+
+// formatRestackPrediction renders a prediction for display next to the branch name
+func formatRestackPrediction(prediction *restackPrediction) string {
+	switch {
+	case prediction.upToDate:
+		return colors.Muted("already up to date")
+	case prediction.blockedBy != "" && prediction.unknown:
+		return colors.Warning("depends on " + prediction.blockedBy + ", cannot predict")
+	case prediction.blockedBy != "":
+		return colors.Warning("blocked by conflicts in " + prediction.blockedBy)
+	case prediction.unknown:
+		return colors.Warning("contains merges, cannot predict")
+	case len(prediction.conflicts) > 0:
+		return colors.Error("conflicts in " + strings.Join(prediction.conflicts, ", "))
+	default:
+		return colors.Success("clean")
+	}
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/stack"
+)
+
+func TestPredictRestack(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-clean", "main")
+	repo.commitFile(t, "clean.txt", "clean", "clean commit")
+	repo.createBranch(t, "feat-stacked", "feat-clean")
+	repo.commitFile(t, "stacked.txt", "stacked", "stacked commit")
+	repo.createBranch(t, "feat-conflict", "main")
+	repo.commitFile(t, "shared.txt", "branch", "conflicting commit")
+
+	if err := repo.repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	repo.commitFile(t, "shared.txt", "main", "main commit")
+
+	before := map[string]string{}
+	for _, branch := range []string{"feat-clean", "feat-stacked", "feat-conflict"} {
+		before[branch], _ = repo.repo.GetBranchCommit(branch)
+	}
+
+	s, err := stack.BuildStack(repo.repo, repo.cfg, repo.metadata)
+	if err != nil {
+		t.Fatalf("BuildStack failed: %v", err)
+	}
+	predictions, err := predictRestack(repo.repo, repo.metadata, descendantNames(s.GetNode("main")))
+	if err != nil {
+		t.Fatalf("predictRestack failed: %v", err)
+	}
+
+	if p := predictions["feat-clean"]; p == nil || p.upToDate || len(p.conflicts) > 0 {
+		t.Fatalf("expected feat-clean to restack cleanly, got %+v", p)
+	}
+	// feat-stacked is up to date with feat-clean today, but moves with it
+	if p := predictions["feat-stacked"]; p == nil || p.upToDate || len(p.conflicts) > 0 {
+		t.Fatalf("expected feat-stacked to restack cleanly, got %+v", p)
+	}
+	if p := predictions["feat-conflict"]; p == nil || len(p.conflicts) != 1 || p.conflicts[0] != "shared.txt" {
+		t.Fatalf("expected feat-conflict to conflict in shared.txt, got %+v", p)
+	}
+
+	for branch, sha := range before {
+		if after, _ := repo.repo.GetBranchCommit(branch); after != sha {
+			t.Fatalf("expected %s to be unchanged", branch)
+		}
+	}
+	if isRebaseInProgress(repo.repo) {
+		t.Fatalf("expected no rebase to be started")
+	}
+}
+
+func TestPredictRestackUpToDate(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.commitFile(t, "a.txt", "a", "a commit")
+
+	predictions, err := predictRestack(repo.repo, repo.metadata, []string{"feat-a"})
+	if err != nil {
+		t.Fatalf("predictRestack failed: %v", err)
+	}
+	if p := predictions["feat-a"]; p == nil || !p.upToDate {
+		t.Fatalf("expected feat-a to be up to date, got %+v", p)
+	}
+}
+
+func TestPredictRestackBlockedByConflictingParent(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-mid", "main")
+	repo.commitFile(t, "shared.txt", "branch", "conflicting commit")
+	repo.createBranch(t, "feat-top", "feat-mid")
+	repo.commitFile(t, "top.txt", "top", "top commit")
+
+	if err := repo.repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	repo.commitFile(t, "shared.txt", "main", "main commit")
+
+	predictions, err := predictRestack(repo.repo, repo.metadata, []string{"feat-mid", "feat-top"})
+	if err != nil {
+		t.Fatalf("predictRestack failed: %v", err)
+	}
+	if p := predictions["feat-mid"]; p == nil || len(p.conflicts) != 1 {
+		t.Fatalf("expected feat-mid to conflict, got %+v", p)
+	}
+	// Replaying feat-top onto feat-mid's old tip would report it clean
+	if p := predictions["feat-top"]; p == nil || p.blockedBy != "feat-mid" || p.unknown || len(p.conflicts) > 0 {
+		t.Fatalf("expected feat-top to be blocked by feat-mid, got %+v", p)
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/restack"
+	"github.com/israelmalagutti/git-wrapper/internal/stack"
+)
+
+// currentOperation names the running command in restack state; set by withOperationLog
+var currentOperation = "restack"
+
+// startRestack restacks branches in order, recording progress so a conflict can be
+// resumed with 'gw continue' or rolled back with 'gw abort'
+func startRestack(repo *git.Repo, metadata *config.Metadata, branches []string) error {
+	originalBranch, _ := repo.GetCurrentBranch()
+	state := restack.New(currentOperation, originalBranch)
+	state.Queue = branches
+	return runRestackQueue(repo, metadata, state)
+}
+
+// runRestackQueue works through the state's queue, saving it before every rebase.
+// The state is kept when a rebase stops on a conflict and cleared otherwise.
+func runRestackQueue(repo *git.Repo, metadata *config.Metadata, state *restack.State) error {
+	path := repo.GetRestackStatePath()
+
+	for len(state.Queue) > 0 {
+		branch := state.Queue[0]
+
+		sha, err := repo.GetBranchCommit(branch)
+		if err != nil {
+			_ = clearRestackState(path)
+			return fmt.Errorf("failed to restack '%s': %w", branch, err)
+		}
+
+		// Parents are read at each step since earlier steps may have reparented branches
+		parent, ok := metadata.GetParent(branch)
+		if !ok {
+			state.Queue = state.Queue[1:]
+			continue
+		}
+
+		state.Remember(branch, sha, metadata.GetParentSHA(branch))
+		if err := saveRestackState(path, state); err != nil {
+			return err
+		}
+
+		if err := restackBranch(repo, metadata, branch, parent); err != nil {
+			if !isRebaseInProgress(repo) {
+				_ = clearRestackState(path)
+			}
+			return err
+		}
+
+		state.Queue = state.Queue[1:]
+	}
+
+	return clearRestackState(path)
+}
+
+// saveRestackState writes the state file, except during a dry run, which has
+// nothing real for 'gw continue' or 'gw abort' to pick up
+func saveRestackState(path string, state *restack.State) error {
+	if dryRun {
+		return nil
+	}
+	return state.Save(path)
+}
+
+// clearRestackState removes the state file, except during a dry run, which
+// must leave a real stopped operation alone
+func clearRestackState(path string) error {
+	if dryRun {
+		return nil
+	}
+	return restack.Clear(path)
+}
+
+// descendantNames lists the descendants of node, each parent before its children
+func descendantNames(node *stack.Node) []string {
+	names := []string{}
+	for _, child := range node.SortedChildren() {
+		names = append(names, child.Name)
+		names = append(names, descendantNames(child)...)
+	}
+	return names
+}
@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 )
@@ -24,11 +27,34 @@ maintaining parent-child relationships between branches.`,
 	Version:       Version,
 	SilenceUsage:  true,
 	SilenceErrors: true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if dryRun {
+			finishDryRun = startDryRun(os.Stdout)
+		}
+	},
 }
 
+// finishDryRun ends the dry run started for the command, if any
+var finishDryRun func()
+
 // Execute runs the root command
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// The first interrupt cancels running git commands and lets gw clean up;
+	// a second one stops gw the usual way
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	repoContext = ctx
+
+	err := rootCmd.ExecuteContext(ctx)
+	if finishDryRun != nil {
+		finishDryRun()
+	}
+	stop()
+	repoContext = context.Background()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -38,6 +64,7 @@ func init() {
 	// Override default version template to show more info
 	rootCmd.SetVersionTemplate(`gw version {{.Version}}
 `)
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print the git commands and metadata changes instead of making them")
 }
 
 // GetVersionInfo returns detailed version information
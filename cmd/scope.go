@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"github.com/israelmalagutti/git-wrapper/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+// scopeFlags holds the --only, --upstack, --downstack and --stack flags of a command
+type scopeFlags struct {
+	only      bool
+	upstack   bool
+	downstack bool
+	stack     bool
+}
+
+// addScopeFlags registers the scope flags on cmd; verb starts each flag's help text.
+// Scopes other than the ones listed are not registered.
+func addScopeFlags(cmd *cobra.Command, flags *scopeFlags, verb string, scopes ...stack.Scope) {
+	names := []string{}
+	for _, scope := range scopes {
+		switch scope {
+		case stack.ScopeOnly:
+			cmd.Flags().BoolVar(&flags.only, "only", false, verb+" only the branch")
+		case stack.ScopeUpstack:
+			cmd.Flags().BoolVar(&flags.upstack, "upstack", false, verb+" the branch and its descendants")
+		case stack.ScopeDownstack:
+			cmd.Flags().BoolVarP(&flags.downstack, "downstack", "d", false, verb+" the branch and its ancestors")
+		case stack.ScopeStack:
+			cmd.Flags().BoolVarP(&flags.stack, "stack", "s", false, verb+" the branch's whole stack")
+		}
+		names = append(names, string(scope))
+	}
+	cmd.MarkFlagsMutuallyExclusive(names...)
+}
+
+// allScopes lists every scope, for commands that accept all of them
+var allScopes = []stack.Scope{stack.ScopeOnly, stack.ScopeUpstack, stack.ScopeDownstack, stack.ScopeStack}
+
+// resolve returns the selected scope, or def when no scope flag is set
+func (f *scopeFlags) resolve(def stack.Scope) stack.Scope {
+	switch {
+	case f.only:
+		return stack.ScopeOnly
+	case f.upstack:
+		return stack.ScopeUpstack
+	case f.downstack:
+		return stack.ScopeDownstack
+	case f.stack:
+		return stack.ScopeStack
+	}
+	return def
+}
+
+// isSet reports whether any scope flag was given
+func (f *scopeFlags) isSet() bool {
+	return f.only || f.upstack || f.downstack || f.stack
+}
+
+// scopeBranchNames returns the names of the branches in scope around branch, parents first
+func scopeBranchNames(s *stack.Stack, branch string, scope stack.Scope) []string {
+	names := []string{}
+	for _, node := range s.SelectScope(branch, scope) {
+		names = append(names, node.Name)
+	}
+	return names
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func TestRunDeleteUpstack(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.createBranch(t, "feat-b", "feat-a")
+	repo.createBranch(t, "feat-c", "feat-b")
+	repo.createBranch(t, "feat-d", "feat-a")
+
+	prevForce, prevScope := deleteForce, deleteScope
+	defer func() { deleteForce, deleteScope = prevForce, prevScope }()
+	deleteForce = true
+	deleteScope = scopeFlags{upstack: true}
+
+	if err := runDelete(nil, []string{"feat-b"}); err != nil {
+		t.Fatalf("runDelete failed: %v", err)
+	}
+
+	for _, branch := range []string{"feat-b", "feat-c"} {
+		if repo.repo.BranchExists(branch) {
+			t.Fatalf("expected %s to be deleted", branch)
+		}
+	}
+	for _, branch := range []string{"feat-a", "feat-d"} {
+		if !repo.repo.BranchExists(branch) {
+			t.Fatalf("expected %s to be kept", branch)
+		}
+	}
+
+	// feat-d was checked out, so HEAD never had to move
+	if current, _ := repo.repo.GetCurrentBranch(); current != "feat-d" {
+		t.Fatalf("expected to stay on feat-d, got %s", current)
+	}
+}
+
+func TestRunDeleteDownstackMovesChildren(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.commitFile(t, "a.txt", "a", "a commit")
+	repo.createBranch(t, "feat-b", "feat-a")
+	repo.commitFile(t, "b.txt", "b", "b commit")
+	repo.createBranch(t, "feat-c", "feat-b")
+	repo.commitFile(t, "c.txt", "c", "c commit")
+	if err := repo.repo.CheckoutBranch("feat-b"); err != nil {
+		t.Fatalf("failed to checkout feat-b: %v", err)
+	}
+
+	prevForce, prevScope := deleteForce, deleteScope
+	defer func() { deleteForce, deleteScope = prevForce, prevScope }()
+	deleteForce = true
+	deleteScope = scopeFlags{downstack: true}
+
+	if err := runDelete(nil, []string{"feat-b"}); err != nil {
+		t.Fatalf("runDelete failed: %v", err)
+	}
+
+	if repo.repo.BranchExists("feat-a") || repo.repo.BranchExists("feat-b") {
+		t.Fatalf("expected feat-a and feat-b to be deleted")
+	}
+	if current, _ := repo.repo.GetCurrentBranch(); current != "main" {
+		t.Fatalf("expected to be on main, got %s", current)
+	}
+
+	metadata, err := config.LoadRepoMetadata(repo.repo)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if parent, _ := metadata.GetParent("feat-c"); parent != "main" {
+		t.Fatalf("expected feat-c to move onto main, got %s", parent)
+	}
+	if !repo.repo.IsAncestor("main", "feat-c") {
+		t.Fatalf("expected feat-c to be restacked onto main")
+	}
+}
+
+func TestRunStackRestackOnly(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.commitFile(t, "a.txt", "a", "a commit")
+	repo.createBranch(t, "feat-b", "feat-a")
+	repo.commitFile(t, "b.txt", "b", "b commit")
+
+	if err := repo.repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	repo.commitFile(t, "main.txt", "main", "main commit")
+	if err := repo.repo.CheckoutBranch("feat-a"); err != nil {
+		t.Fatalf("failed to checkout feat-a: %v", err)
+	}
+
+	prevScope := restackScope
+	defer func() { restackScope = prevScope }()
+	restackScope = scopeFlags{only: true}
+
+	if err := runStackRestack(nil, nil); err != nil {
+		t.Fatalf("runStackRestack failed: %v", err)
+	}
+
+	if !repo.repo.IsAncestor("main", "feat-a") {
+		t.Fatalf("expected feat-a to be restacked")
+	}
+	if repo.repo.IsAncestor("feat-a", "feat-b") {
+		t.Fatalf("expected feat-b to be left alone")
+	}
+}
+
+func TestScopeFlagShorthands(t *testing.T) {
+	for _, cmd := range []*cobra.Command{submitCmd, deleteCmd, foreachCmd, restackCmd} {
+		if flag := cmd.Flags().Lookup("downstack"); flag == nil || flag.Shorthand != "d" {
+			t.Errorf("expected %s --downstack to have -d, got %+v", cmd.Name(), flag)
+		}
+		if flag := cmd.Flags().Lookup("stack"); flag == nil || flag.Shorthand != "s" {
+			t.Errorf("expected %s --stack to have -s, got %+v", cmd.Name(), flag)
+		}
+	}
+}
@@ -38,7 +38,7 @@ Example:
   gw split -u                    # Interactive hunk selection
   gw split -f "*.json"           # Split JSON files to parent
   gw split -f "src/**" -n base   # Split src/ to branch named 'base'`,
-	RunE: runSplit,
+	RunE: withOperationLog("split", runSplit),
 }
 
 func init() {
@@ -51,7 +51,7 @@ func init() {
 
 func runSplit(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -63,7 +63,7 @@ func runSplit(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load metadata
-	metadata, err := config.LoadMetadata(repo.GetMetadataPath())
+	metadata, err := config.LoadRepoMetadata(repo)
 	if err != nil {
 		return fmt.Errorf("failed to load metadata: %w", err)
 	}
@@ -263,9 +263,12 @@ func splitByCommitMode(repo *git.Repo, cfg *config.Config, metadata *config.Meta
 		return fmt.Errorf("failed to create branch: %w", err)
 	}
 
-	// Track new branch with parent as its parent
+	// Track new branch with parent as its parent, starting where current did
 	metadata.TrackBranch(newBranchName, parentBranch)
-	if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+	if base, err := repo.GetRestackBase(currentBranch, parentBranch, metadata.GetParentSHA(currentBranch)); err == nil {
+		_ = metadata.SetParentSHA(newBranchName, base)
+	}
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
 		return fmt.Errorf("failed to save metadata: %w", err)
 	}
 
@@ -273,15 +276,18 @@ func splitByCommitMode(repo *git.Repo, cfg *config.Config, metadata *config.Meta
 	if err := metadata.UpdateParent(currentBranch, newBranchName); err != nil {
 		return fmt.Errorf("failed to update metadata: %w", err)
 	}
-	if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
 		return fmt.Errorf("failed to save metadata: %w", err)
 	}
 
 	// Rebase current branch onto new branch
 	fmt.Printf("Rebasing '%s' onto '%s'...\n", currentBranch, newBranchName)
-	if _, err := repo.RunGitCommand("rebase", "--onto", newBranchName, splitSHA, currentBranch); err != nil {
+	if err := repo.RebaseOnto(currentBranch, newBranchName, splitSHA); err != nil {
 		return fmt.Errorf("rebase failed: %w\nResolve conflicts and run: gw stack restack", err)
 	}
+	if err := recordParentSHA(repo, metadata, currentBranch, newBranchName); err != nil {
+		return err
+	}
 
 	fmt.Printf("\n✓ Created '%s' with %d commit(s)\n", newBranchName, len(selected))
 	fmt.Printf("✓ '%s' now has %d commit(s) on top of '%s'\n", currentBranch, len(commits)-len(selected), newBranchName)
@@ -297,6 +303,12 @@ func splitByHunkMode(repo *git.Repo, cfg *config.Config, metadata *config.Metada
 	}
 	currentHEAD = strings.TrimSpace(currentHEAD)
 
+	// Find where the current branch's own commits start
+	base, err := repo.GetRestackBase(currentBranch, parentBranch, metadata.GetParentSHA(currentBranch))
+	if err != nil {
+		return err
+	}
+
 	// Create new branch from parent
 	fmt.Printf("Creating '%s' from '%s'...\n", newBranchName, parentBranch)
 	if _, err := repo.RunGitCommand("checkout", "-b", newBranchName, parentBranch); err != nil {
@@ -365,7 +377,10 @@ func splitByHunkMode(repo *git.Repo, cfg *config.Config, metadata *config.Metada
 
 	// Track new branch
 	metadata.TrackBranch(newBranchName, parentBranch)
-	if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+	if parentSHA, err := repo.GetBranchCommit(parentBranch); err == nil {
+		_ = metadata.SetParentSHA(newBranchName, parentSHA)
+	}
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
 		return fmt.Errorf("failed to save metadata: %w", err)
 	}
 
@@ -378,15 +393,18 @@ func splitByHunkMode(repo *git.Repo, cfg *config.Config, metadata *config.Metada
 	if err := metadata.UpdateParent(currentBranch, newBranchName); err != nil {
 		return fmt.Errorf("failed to update metadata: %w", err)
 	}
-	if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
 		return fmt.Errorf("failed to save metadata: %w", err)
 	}
 
 	// Rebase current branch onto new branch
 	fmt.Printf("\nRebasing '%s' onto '%s'...\n", currentBranch, newBranchName)
-	if _, err := repo.RunGitCommand("rebase", newBranchName); err != nil {
+	if err := repo.RebaseOnto(currentBranch, newBranchName, base); err != nil {
 		return fmt.Errorf("rebase failed: %w\nResolve conflicts and run: gw stack restack", err)
 	}
+	if err := recordParentSHA(repo, metadata, currentBranch, newBranchName); err != nil {
+		return err
+	}
 
 	// Build stack to restack children
 	s, err := stack.BuildStack(repo, cfg, metadata)
@@ -397,7 +415,7 @@ func splitByHunkMode(repo *git.Repo, cfg *config.Config, metadata *config.Metada
 	node := s.GetNode(currentBranch)
 	if node != nil && len(node.Children) > 0 {
 		fmt.Println("\nRestacking children...")
-		if err := restackChildren(repo, s, node); err != nil {
+		if err := restackChildren(repo, s, metadata, node); err != nil {
 			return fmt.Errorf("failed to restack children: %w", err)
 		}
 	}
@@ -416,6 +434,12 @@ func splitByFileMode(repo *git.Repo, cfg *config.Config, metadata *config.Metada
 	}
 	currentHEAD = strings.TrimSpace(currentHEAD)
 
+	// Find where the current branch's own commits start
+	base, err := repo.GetRestackBase(currentBranch, parentBranch, metadata.GetParentSHA(currentBranch))
+	if err != nil {
+		return err
+	}
+
 	// Create new branch from parent
 	fmt.Printf("Creating '%s' from '%s'...\n", newBranchName, parentBranch)
 	if _, err := repo.RunGitCommand("checkout", "-b", newBranchName, parentBranch); err != nil {
@@ -479,7 +503,10 @@ func splitByFileMode(repo *git.Repo, cfg *config.Config, metadata *config.Metada
 
 	// Track new branch
 	metadata.TrackBranch(newBranchName, parentBranch)
-	if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+	if parentSHA, err := repo.GetBranchCommit(parentBranch); err == nil {
+		_ = metadata.SetParentSHA(newBranchName, parentSHA)
+	}
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
 		return fmt.Errorf("failed to save metadata: %w", err)
 	}
 
@@ -492,15 +519,18 @@ func splitByFileMode(repo *git.Repo, cfg *config.Config, metadata *config.Metada
 	if err := metadata.UpdateParent(currentBranch, newBranchName); err != nil {
 		return fmt.Errorf("failed to update metadata: %w", err)
 	}
-	if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
 		return fmt.Errorf("failed to save metadata: %w", err)
 	}
 
 	// Rebase onto new branch
 	fmt.Printf("\nRebasing '%s' onto '%s'...\n", currentBranch, newBranchName)
-	if _, err := repo.RunGitCommand("rebase", newBranchName); err != nil {
+	if err := repo.RebaseOnto(currentBranch, newBranchName, base); err != nil {
 		return fmt.Errorf("rebase failed: %w\nResolve conflicts and run: gw stack restack", err)
 	}
+	if err := recordParentSHA(repo, metadata, currentBranch, newBranchName); err != nil {
+		return err
+	}
 
 	// Restack children
 	s, err := stack.BuildStack(repo, cfg, metadata)
@@ -511,7 +541,7 @@ func splitByFileMode(repo *git.Repo, cfg *config.Config, metadata *config.Metada
 	node := s.GetNode(currentBranch)
 	if node != nil && len(node.Children) > 0 {
 		fmt.Println("\nRestacking children...")
-		if err := restackChildren(repo, s, node); err != nil {
+		if err := restackChildren(repo, s, metadata, node); err != nil {
 			return fmt.Errorf("failed to restack children: %w", err)
 		}
 	}
@@ -21,30 +21,6 @@ func TestIsDescendant(t *testing.T) {
 	}
 }
 
-func TestIsInDescendants(t *testing.T) {
-	root := &stack.Node{Name: "main"}
-	a := &stack.Node{Name: "feat-a", Parent: root}
-	b := &stack.Node{Name: "feat-b", Parent: a}
-	root.Children = []*stack.Node{a}
-	a.Children = []*stack.Node{b}
-
-	s := &stack.Stack{
-		Trunk: root,
-		Nodes: map[string]*stack.Node{
-			"main":   root,
-			"feat-a": a,
-			"feat-b": b,
-		},
-	}
-
-	if !isInDescendants(s, root, "feat-b") {
-		t.Fatalf("expected feat-b in descendants of trunk")
-	}
-	if isInDescendants(s, a, "main") {
-		t.Fatalf("did not expect main in descendants of feat-a")
-	}
-}
-
 func TestFindLeaves(t *testing.T) {
 	root := &stack.Node{Name: "main"}
 	a := &stack.Node{Name: "feat-a", Parent: root}
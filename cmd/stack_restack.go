@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/israelmalagutti/git-wrapper/internal/config"
 	"github.com/israelmalagutti/git-wrapper/internal/git"
@@ -17,24 +19,37 @@ var stackRestackCmd = &cobra.Command{
 
 This command:
 - Checks if the current branch needs rebasing onto its parent
-- Performs the rebase if needed
+- Performs the rebase if needed, in memory unless the branch is checked out
+  in another worktree
 - Recursively restacks all children branches
-- Handles conflicts interactively
+- Rebases a conflicting branch in the working tree so it can be resolved
+
+By default the current branch and its descendants are restacked. Use
+--only, --downstack or --stack to restack just the branch, the branch and
+its ancestors, or its whole stack instead.
+
+With --dry-run nothing is changed: each branch that needs a restack is
+replayed in memory and the stack is shown with the predicted outcome.
 
 Example:
   gw stack restack    # Restack current branch and children
+  gw stack restack --dry-run  # Predict conflicts without restacking
+  gw stack restack --stack    # Restack the whole stack
   gw stack r          # Short alias
   gw stack fix        # Alternative alias`,
-	RunE: runStackRestack,
+	RunE: withOperationLog("restack", runStackRestack),
 }
 
+var restackScope scopeFlags
+
 func init() {
+	addScopeFlags(stackRestackCmd, &restackScope, "Restack", allScopes...)
 	stackCmd.AddCommand(stackRestackCmd)
 }
 
 func runStackRestack(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -46,7 +61,7 @@ func runStackRestack(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load metadata
-	metadata, err := config.LoadMetadata(repo.GetMetadataPath())
+	metadata, err := config.LoadRepoMetadata(repo)
 	if err != nil {
 		return fmt.Errorf("failed to load metadata: %w", err)
 	}
@@ -63,128 +78,171 @@ func runStackRestack(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to build stack: %w", err)
 	}
 
-	// Handle trunk specially - restack all children of trunk
-	if currentBranch == cfg.Trunk {
-		trunkNode := s.GetNode(cfg.Trunk)
-		if trunkNode == nil {
-			return fmt.Errorf("trunk '%s' not found in stack", cfg.Trunk)
+	// Trunk itself is never restacked, only the branches above it
+	scope := restackScope.resolve(stack.ScopeUpstack)
+	if currentBranch != cfg.Trunk {
+		// Check if current branch is tracked
+		if !metadata.IsTracked(currentBranch) {
+			return fmt.Errorf("current branch '%s' is not tracked by gw", currentBranch)
 		}
 
-		if len(trunkNode.Children) == 0 {
-			fmt.Println("No branches to restack from trunk.")
-			return nil
+		// Get current branch node
+		node := s.GetNode(currentBranch)
+		if node == nil {
+			return fmt.Errorf("branch '%s' not found in stack", currentBranch)
 		}
 
-		if err := restackChildren(repo, s, trunkNode); err != nil {
-			return err
+		if node.Parent == nil {
+			return fmt.Errorf("branch '%s' has no parent", currentBranch)
 		}
-
-		// Return to trunk
-		if err := repo.CheckoutBranch(cfg.Trunk); err != nil {
-			fmt.Printf("Warning: could not return to trunk: %v\n", err)
-		}
-
-		return nil
+	} else if s.GetNode(cfg.Trunk) == nil {
+		return fmt.Errorf("trunk '%s' not found in stack", cfg.Trunk)
 	}
 
-	// Check if current branch is tracked
-	if !metadata.IsTracked(currentBranch) {
-		return fmt.Errorf("current branch '%s' is not tracked by gw", currentBranch)
-	}
-
-	// Get current branch node
-	node := s.GetNode(currentBranch)
-	if node == nil {
-		return fmt.Errorf("branch '%s' not found in stack", currentBranch)
+	branches := scopeBranchNames(s, currentBranch, scope)
+	if len(branches) == 0 {
+		fmt.Println("No branches to restack from trunk.")
+		return nil
 	}
 
-	if node.Parent == nil {
-		return fmt.Errorf("branch '%s' has no parent", currentBranch)
+	if dryRun {
+		return printRestackPrediction(repo, s, metadata, branches)
 	}
 
-	// Restack current branch
-	if err := restackBranch(repo, currentBranch, node.Parent.Name); err != nil {
+	// Restack parents before children
+	if err := startRestack(repo, metadata, branches); err != nil {
 		return err
 	}
 
-	// Recursively restack children
-	if len(node.Children) > 0 {
-		if err := restackChildren(repo, s, node); err != nil {
-			return err
-		}
+	// Return to the branch the restack started from
+	if err := repo.CheckoutBranch(currentBranch); err != nil {
+		fmt.Printf("Warning: could not return to %s: %v\n", currentBranch, err)
 	}
 
 	return nil
 }
 
 // restackBranch rebases a branch onto its parent
-func restackBranch(repo *git.Repo, branch, parent string) error {
+func restackBranch(repo *git.Repo, metadata *config.Metadata, branch, parent string) error {
+	recordedBase := metadata.GetParentSHA(branch)
+
 	// Check if branch needs rebasing
-	needsRebase, err := needsRebase(repo, branch, parent)
+	needsRebase, err := needsRebase(repo, branch, parent, recordedBase)
 	if err != nil {
 		return err
 	}
 
 	if !needsRebase {
 		fmt.Printf("%s does not need to be restacked on %s.\n", branch, parent)
-		return nil
+		return recordParentSHA(repo, metadata, branch, parent)
 	}
 
-	// Perform rebase
-	_, err = repo.RunGitCommand("rebase", parent, branch)
+	base, err := repo.GetRestackBase(branch, parent, recordedBase)
 	if err != nil {
+		return err
+	}
+
+	// Replay in memory when possible so the working tree is left alone
+	restacked, conflicts, err := restackInMemory(repo, branch, parent, base)
+	if err != nil {
+		return err
+	}
+	if restacked {
+		fmt.Printf("Restacked %s on %s.\n", branch, parent)
+		return recordParentSHA(repo, metadata, branch, parent)
+	}
+	if len(conflicts) > 0 {
+		fmt.Printf("Conflicts in %s; rebasing %s in the working tree.\n", strings.Join(conflicts, ", "), branch)
+	}
+
+	// Perform rebase, replaying only the branch's own commits
+	if err := repo.RebaseOnto(branch, parent, base); err != nil {
 		fmt.Printf("\nHit conflict restacking %s on %s.\n", branch, parent)
 		fmt.Println("\nTo fix and continue:")
 		fmt.Println("  (1) resolve the merge conflicts")
 		fmt.Println("  (2) stage changes with: git add .")
-		fmt.Println("  (3) continue rebase: git rebase --continue")
-		fmt.Println("  (4) restack remaining: gw stack restack")
-		fmt.Println("\nTo abort: git rebase --abort")
+		fmt.Println("  (3) continue the restack: gw continue")
+		fmt.Println("\nTo abort and restore every branch: gw abort")
 		return fmt.Errorf("rebase conflict")
 	}
 
 	fmt.Printf("Restacked %s on %s.\n", branch, parent)
-	return nil
+	return recordParentSHA(repo, metadata, branch, parent)
 }
 
-// restackChildren recursively restacks all children of a node
-func restackChildren(repo *git.Repo, s *stack.Stack, parent *stack.Node) error {
-	for _, child := range parent.Children {
-		// Checkout child branch
-		if err := repo.CheckoutBranch(child.Name); err != nil {
-			return fmt.Errorf("failed to checkout '%s': %w", child.Name, err)
-		}
+// restackInMemory replays the branch's commits onto parent and moves the branch ref.
+// The branch checked out here is moved with 'git reset --keep', which carries the
+// working tree along; other branches are moved without checking anything out. It
+// returns false when the branch has to be rebased in the working tree instead: when
+// it is checked out in another worktree, when its commits cannot be replayed, or when
+// the replay conflicts, in which case the conflicting paths are returned.
+func restackInMemory(repo *git.Repo, branch, parent, base string) (bool, []string, error) {
+	checkedOut, err := repo.CheckedOutBranches()
+	if err != nil {
+		return false, nil, nil
+	}
+	currentBranch, _ := repo.GetCurrentBranch()
+	if checkedOut[branch] && branch != currentBranch {
+		return false, nil, nil
+	}
 
-		// Restack this child
-		if err := restackBranch(repo, child.Name, parent.Name); err != nil {
-			return err
-		}
+	oldTip, err := repo.GetBranchCommit(branch)
+	if err != nil {
+		return false, nil, err
+	}
 
-		// Recursively restack its children
-		if len(child.Children) > 0 {
-			if err := restackChildren(repo, s, child); err != nil {
-				return err
-			}
+	newTip, conflicts, err := repo.ReplayCommits(branch, parent, base)
+	if errors.Is(err, git.ErrCannotReplay) {
+		return false, nil, nil
+	}
+	if err != nil || len(conflicts) > 0 {
+		return false, conflicts, err
+	}
+
+	if branch == currentBranch {
+		if _, err := repo.RunGitCommand("reset", "--keep", newTip); err != nil {
+			return false, nil, fmt.Errorf("failed to move %s: %w", branch, err)
 		}
+		return true, nil, nil
 	}
 
-	return nil
+	if err := repo.UpdateRefs([]git.RefUpdate{{Ref: "refs/heads/" + branch, OldSHA: oldTip, NewSHA: newTip}}); err != nil {
+		return false, nil, err
+	}
+	return true, nil, nil
+}
+
+// restackChildren recursively restacks all children of a node
+func restackChildren(repo *git.Repo, s *stack.Stack, metadata *config.Metadata, parent *stack.Node) error {
+	return startRestack(repo, metadata, descendantNames(parent))
 }
 
 // needsRebase checks if a branch needs to be rebased onto its parent
-func needsRebase(repo *git.Repo, branch, parent string) (bool, error) {
-	// Get merge base between branch and parent
-	mergeBase, err := repo.RunGitCommand("merge-base", branch, parent)
-	if err != nil {
-		return false, fmt.Errorf("failed to get merge base: %w", err)
+func needsRebase(repo *git.Repo, branch, parent, recordedBase string) (bool, error) {
+	return repo.IsBehind(branch, parent, recordedBase)
+}
+
+// recordParentSHA stores the parent's current commit as the branch's base
+func recordParentSHA(repo *git.Repo, metadata *config.Metadata, branch, parent string) error {
+	if !metadata.IsTracked(branch) {
+		return nil
 	}
 
-	// Get parent's current commit
-	parentCommit, err := repo.RunGitCommand("rev-parse", parent)
+	parentSHA, err := repo.GetBranchCommit(parent)
 	if err != nil {
-		return false, fmt.Errorf("failed to get parent commit: %w", err)
+		return err
 	}
 
-	// If merge base != parent commit, branch needs rebasing
-	return mergeBase != parentCommit, nil
+	if metadata.GetParentSHA(branch) == parentSHA {
+		return nil
+	}
+
+	if err := metadata.SetParentSHA(branch, parentSHA); err != nil {
+		return err
+	}
+
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
+		return fmt.Errorf("failed to save metadata: %w", err)
+	}
+	return nil
 }
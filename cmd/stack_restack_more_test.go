@@ -73,7 +73,7 @@ func TestRestackChildrenCheckoutError(t *testing.T) {
 		},
 	}
 
-	if err := restackChildren(repo.repo, s, trunk); err == nil {
+	if err := restackChildren(repo.repo, s, repo.metadata, trunk); err == nil {
 		t.Fatalf("expected restackChildren checkout error")
 	}
 }
@@ -82,7 +82,7 @@ func TestNeedsRebaseError(t *testing.T) {
 	repo := setupCmdTestRepo(t)
 	defer repo.cleanup()
 
-	if _, err := needsRebase(repo.repo, "missing", "main"); err == nil {
+	if _, err := needsRebase(repo.repo, "missing", "main", ""); err == nil {
 		t.Fatalf("expected needsRebase error")
 	}
 }
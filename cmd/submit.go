@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/forge"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+var (
+	submitScope scopeFlags
+	submitDraft bool
+)
+
+var submitCmd = &cobra.Command{
+	Use:   "submit",
+	Short: "Push branches and open or update their pull requests",
+	Long: `Push branches to origin and create or update a pull request for each one,
+targeting its tracked parent as the base branch.
+
+By default only the current branch is submitted. Use --downstack (-d) to
+also submit its ancestors, --upstack its descendants, or --stack (-s) its
+whole stack.
+
+Branches are pushed with --force-with-lease, so commits pushed by someone
+else are never overwritten. The pull request number is stored in the stack
+metadata.
+
+Example:
+  gw submit               # Submit the current branch
+  gw submit -d            # Submit the current branch and its ancestors
+  gw submit --upstack     # Submit the current branch and its descendants
+  gw submit --stack       # Submit the whole stack the current branch is in
+  gw submit --draft       # Open new pull requests as drafts`,
+	Args: cobra.NoArgs,
+	RunE: withOperationLog("submit", runSubmit),
+}
+
+func init() {
+	addScopeFlags(submitCmd, &submitScope, "Submit", allScopes...)
+	submitCmd.Flags().BoolVar(&submitDraft, "draft", false, "Open new pull requests as drafts")
+	rootCmd.AddCommand(submitCmd)
+}
+
+func runSubmit(cmd *cobra.Command, args []string) error {
+	// Initialize repository
+	repo, err := openRepo()
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	// Load config
+	cfg, err := config.Load(repo.GetConfigPath())
+	if err != nil {
+		return err
+	}
+
+	// Load metadata
+	metadata, err := config.LoadRepoMetadata(repo)
+	if err != nil {
+		return fmt.Errorf("failed to load metadata: %w", err)
+	}
+
+	currentBranch, err := repo.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if currentBranch == cfg.Trunk {
+		return fmt.Errorf("cannot submit trunk branch '%s'", cfg.Trunk)
+	}
+	if !metadata.IsTracked(currentBranch) {
+		return fmt.Errorf("branch '%s' is not tracked by gw\nRun 'gw track' first", currentBranch)
+	}
+
+	s, err := stack.BuildStack(repo, cfg, metadata)
+	if err != nil {
+		return fmt.Errorf("failed to build stack: %w", err)
+	}
+
+	branches := submitBranches(s, currentBranch)
+
+	// Refuse to open pull requests with stale diffs
+	for _, node := range branches {
+		behind, err := repo.IsBehind(node.Name, node.Parent.Name, metadata.GetParentSHA(node.Name))
+		if err != nil {
+			return err
+		}
+		if behind {
+			return fmt.Errorf("branch '%s' is behind its parent '%s'\nRun 'gw stack restack' first", node.Name, node.Parent.Name)
+		}
+		if !node.Parent.IsTrunk && !submitIncludes(branches, node.Parent.Name) && !repo.HasRemoteBranch(node.Parent.Name, "origin") {
+			return fmt.Errorf("parent '%s' of '%s' has not been pushed\nUse --downstack to submit it too", node.Parent.Name, node.Name)
+		}
+	}
+
+	provider, err := openForge(repo, cfg)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range branches {
+		if err := pushForSubmit(repo, node.Name); err != nil {
+			return err
+		}
+
+		pr, created, err := submitPullRequest(repo, provider, metadata, node.Name, node.Parent.Name)
+		if err != nil {
+			return err
+		}
+
+		if created {
+			fmt.Printf("%s Created PR #%d for %s → %s: %s\n", colors.Success("✓"), pr.Number,
+				colors.BranchCurrent(node.Name), colors.BranchParent(node.Parent.Name), pr.URL)
+		} else {
+			fmt.Printf("%s Updated PR #%d for %s → %s: %s\n", colors.Success("✓"), pr.Number,
+				colors.BranchCurrent(node.Name), colors.BranchParent(node.Parent.Name), pr.URL)
+		}
+
+		if err := rememberPRStatus(repo, node.Name, pr); err != nil {
+			fmt.Printf("%s %v\n", colors.Warning("⚠"), err)
+		}
+
+		if metadata.GetPRNumber(node.Name) != pr.Number {
+			if err := metadata.SetPRNumber(node.Name, pr.Number); err != nil {
+				return err
+			}
+			if err := config.SaveRepoMetadata(repo, metadata); err != nil {
+				return fmt.Errorf("failed to save metadata: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// submitBranches returns the branches selected by the scope flags, parents first
+func submitBranches(s *stack.Stack, current string) []*stack.Node {
+	return s.SelectScope(current, submitScope.resolve(stack.ScopeOnly))
+}
+
+// submitIncludes reports whether branch is part of the submission
+func submitIncludes(branches []*stack.Node, branch string) bool {
+	for _, node := range branches {
+		if node.Name == branch {
+			return true
+		}
+	}
+	return false
+}
+
+// pushForSubmit pushes a branch unless origin already has its tip
+func pushForSubmit(repo *git.Repo, branch string) error {
+	local, err := repo.GetBranchCommit(branch)
+	if err != nil {
+		return err
+	}
+
+	expected := ""
+	if repo.HasRemoteBranch(branch, "origin") {
+		expected, err = repo.GetBranchCommit("origin/" + branch)
+		if err != nil {
+			return err
+		}
+	}
+	if expected == local {
+		fmt.Printf("%s is already pushed\n", colors.Info(branch))
+		return nil
+	}
+
+	if err := repo.PushBranch("origin", branch, expected); err != nil {
+		return fmt.Errorf("%w\nThe remote branch changed since it was last fetched; run 'gw sync' and retry", err)
+	}
+	fmt.Printf("%s Pushed %s\n", colors.Success("✓"), colors.BranchCurrent(branch))
+	return nil
+}
+
+// submitPullRequest creates the branch's pull request or points the existing one at parent
+func submitPullRequest(repo *git.Repo, provider forge.Provider, metadata *config.Metadata, branch, parent string) (*forge.PullRequest, bool, error) {
+	var pr *forge.PullRequest
+
+	if number := metadata.GetPRNumber(branch); number != 0 {
+		existing, err := provider.GetPullRequest(number)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get PR #%d: %w", number, err)
+		}
+		if existing.State == forge.StateOpen {
+			pr = existing
+		}
+	}
+
+	if pr == nil {
+		existing, err := provider.FindPullRequest(branch)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to find PR for %s: %w", branch, err)
+		}
+		pr = existing
+	}
+
+	if pr == nil {
+		title, body := pullRequestMessage(repo, branch, parent)
+		created, err := provider.CreatePullRequest(forge.CreateOptions{
+			Title: title,
+			Body:  body,
+			Head:  branch,
+			Base:  parent,
+			Draft: submitDraft,
+		})
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to create PR for %s: %w", branch, err)
+		}
+		return created, true, nil
+	}
+
+	if pr.Base != parent {
+		updated, err := provider.UpdatePullRequest(pr.Number, forge.UpdateOptions{Base: &parent})
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to update PR #%d: %w", pr.Number, err)
+		}
+		pr = updated
+	}
+	return pr, false, nil
+}
+
+// pullRequestMessage uses the branch's first commit as the pull request title and body
+func pullRequestMessage(repo *git.Repo, branch, parent string) (string, string) {
+	output, err := repo.RunGitCommand("log", "--reverse", "--format=%H", parent+".."+branch)
+	if err != nil || output == "" {
+		return branch, ""
+	}
+	first := strings.SplitN(output, "\n", 2)[0]
+
+	title, err := repo.RunGitCommand("log", "-1", "--format=%s", first)
+	if err != nil || title == "" {
+		title = branch
+	}
+	body, _ := repo.RunGitCommand("log", "-1", "--format=%b", first)
+	return title, body
+}
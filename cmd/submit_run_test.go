@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/forge/forgetest"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+)
+
+// setupSubmitRepo returns a repo with an origin remote whose config points at a GitHub stand-in
+func setupSubmitRepo(t *testing.T) (*git.Repo, *forgetest.Server) {
+	t.Helper()
+
+	localDir, _, cleanup := setupRepoWithRemote(t)
+	t.Cleanup(cleanup)
+
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get cwd: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+	if err := os.Chdir(localDir); err != nil {
+		t.Fatalf("failed to chdir: %v", err)
+	}
+
+	repo, err := git.NewRepo()
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+
+	server := forgetest.NewServer(t, "acme", "widgets")
+	cfg := config.NewConfig("main")
+	cfg.Forge = &config.Forge{APIURL: server.URL, Repository: "acme/widgets"}
+	if err := cfg.Save(repo.GetConfigPath()); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	return repo, server
+}
+
+func TestRunSubmit(t *testing.T) {
+	repo, server := setupSubmitRepo(t)
+
+	prevScope, prevDraft := submitScope, submitDraft
+	defer func() { submitScope, submitDraft = prevScope, prevDraft }()
+	submitScope, submitDraft = scopeFlags{}, false
+
+	for _, branch := range []string{"feat-a", "feat-b"} {
+		if err := runCreate(nil, []string{branch}); err != nil {
+			t.Fatalf("create %s failed: %v", branch, err)
+		}
+		if _, err := repo.RunGitCommand("commit", "--allow-empty", "-m", "Add "+branch, "-m", "Details for "+branch); err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+	}
+
+	// feat-a has not been pushed, so feat-b alone cannot be submitted
+	if err := runSubmit(nil, nil); err == nil {
+		t.Fatalf("expected error for unpushed parent")
+	}
+
+	submitScope = scopeFlags{downstack: true}
+	if err := runSubmit(nil, nil); err != nil {
+		t.Fatalf("submit --downstack failed: %v", err)
+	}
+
+	pulls := server.Pulls()
+	if len(pulls) != 2 {
+		t.Fatalf("expected 2 pull requests, got %+v", pulls)
+	}
+	if pulls[0].Head != "feat-a" || pulls[0].Base != "main" || pulls[0].Title != "Add feat-a" || pulls[0].Body != "Details for feat-a" {
+		t.Fatalf("unexpected PR for feat-a: %+v", pulls[0])
+	}
+	if pulls[1].Head != "feat-b" || pulls[1].Base != "feat-a" || pulls[1].Draft {
+		t.Fatalf("unexpected PR for feat-b: %+v", pulls[1])
+	}
+	if !repo.HasRemoteBranch("feat-b", "origin") {
+		t.Fatalf("expected feat-b to be pushed")
+	}
+
+	metadata, err := config.LoadRepoMetadata(repo)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if metadata.GetPRNumber("feat-a") != pulls[0].Number || metadata.GetPRNumber("feat-b") != pulls[1].Number {
+		t.Fatalf("expected PR numbers in metadata, got %d and %d", metadata.GetPRNumber("feat-a"), metadata.GetPRNumber("feat-b"))
+	}
+
+	// Resubmitting fixes a wrong base instead of opening a new PR
+	server.UpdatePull(pulls[1].Number, func(pr *forgetest.PullRequest) { pr.Base = "main" })
+	if _, err := repo.RunGitCommand("commit", "--allow-empty", "-m", "More feat-b"); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	submitScope = scopeFlags{}
+	if err := runSubmit(nil, nil); err != nil {
+		t.Fatalf("resubmit failed: %v", err)
+	}
+	if got := server.Pull(pulls[1].Number); got.Base != "feat-a" || len(server.Pulls()) != 2 {
+		t.Fatalf("expected base restored without a new PR, got %+v", server.Pulls())
+	}
+	local, _ := repo.GetBranchCommit("feat-b")
+	remote, _ := repo.GetBranchCommit("origin/feat-b")
+	if local != remote {
+		t.Fatalf("expected new commit to be pushed")
+	}
+
+	// --stack from the bottom picks up new descendants as drafts
+	if err := runCreate(nil, []string{"feat-c"}); err != nil {
+		t.Fatalf("create feat-c failed: %v", err)
+	}
+	if _, err := repo.RunGitCommand("commit", "--allow-empty", "-m", "Add feat-c"); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	if err := repo.CheckoutBranch("feat-a"); err != nil {
+		t.Fatalf("failed to checkout feat-a: %v", err)
+	}
+	submitScope, submitDraft = scopeFlags{stack: true}, true
+	if err := runSubmit(nil, nil); err != nil {
+		t.Fatalf("submit --stack failed: %v", err)
+	}
+	pulls = server.Pulls()
+	if len(pulls) != 3 || pulls[2].Head != "feat-c" || pulls[2].Base != "feat-b" || !pulls[2].Draft {
+		t.Fatalf("unexpected pull requests after --stack: %+v", pulls)
+	}
+}
+
+func TestRunSubmitRequiresRestack(t *testing.T) {
+	repo, _ := setupSubmitRepo(t)
+
+	prevScope := submitScope
+	defer func() { submitScope = prevScope }()
+	submitScope = scopeFlags{downstack: true}
+
+	if err := runCreate(nil, []string{"feat-a"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if err := repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	if _, err := repo.RunGitCommand("commit", "--allow-empty", "-m", "trunk moves"); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	if err := runSubmit(nil, nil); err == nil {
+		t.Fatalf("expected error when submitting trunk")
+	}
+
+	if err := repo.CheckoutBranch("feat-a"); err != nil {
+		t.Fatalf("failed to checkout feat-a: %v", err)
+	}
+	if err := runSubmit(nil, nil); err == nil {
+		t.Fatalf("expected error for branch behind its parent")
+	}
+}
+
+func TestRunSubmitIsUndoable(t *testing.T) {
+	repo, server := setupSubmitRepo(t)
+
+	prevScope, prevDraft, prevForce := submitScope, submitDraft, undoForce
+	defer func() { submitScope, submitDraft, undoForce = prevScope, prevDraft, prevForce }()
+	submitScope, submitDraft, undoForce = scopeFlags{}, false, true
+
+	if err := runCreate(nil, []string{"feat-a"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if _, err := repo.RunGitCommand("commit", "--allow-empty", "-m", "Add feat-a"); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	if err := submitCmd.RunE(submitCmd, nil); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if len(server.Pulls()) != 1 {
+		t.Fatalf("expected 1 pull request, got %+v", server.Pulls())
+	}
+
+	if err := runUndo(nil, nil); err != nil {
+		t.Fatalf("runUndo failed: %v", err)
+	}
+	metadata, err := config.LoadRepoMetadata(repo)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if got := metadata.GetPRNumber("feat-a"); got != 0 {
+		t.Fatalf("expected undo to clear the PR number, got %d", got)
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/israelmalagutti/git-wrapper/internal/config"
 	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/metasync"
+	"github.com/israelmalagutti/git-wrapper/internal/restack"
 	"github.com/israelmalagutti/git-wrapper/internal/stack"
 	"github.com/spf13/cobra"
 )
@@ -15,6 +18,7 @@ import (
 var (
 	syncForce   bool
 	syncRestack bool
+	syncScope   scopeFlags
 )
 
 var syncCmd = &cobra.Command{
@@ -25,25 +29,37 @@ var syncCmd = &cobra.Command{
 This command:
 1. Fetches from all remotes (git fetch --all --prune)
 2. Syncs trunk with remote (fast-forward or reset)
-3. Prompts to delete branches merged into trunk
+3. Prompts to delete branches merged into trunk, including squash and
+   rebase merges whose commits are not ancestors of trunk
+   Children of merged branches are moved onto trunk either way
 4. Restacks all branches that can be rebased without conflicts
+   Branches that conflict are left for 'gw continue' to restack in the
+   working tree, or 'gw abort' to undo the sync's restacks
+
+Use --only, --upstack, --downstack or --stack to restack just that part of
+the tree around the current branch in step 4.
+
+With "syncMetadata": true in .gw_config, stack metadata is also fetched from
+origin after step 1 and pushed back at the end (see 'gw metadata push').
 
 Example:
   gw sync              # Full sync with prompts
   gw sync -f           # Force sync without prompts
-  gw sync --no-restack # Sync without restacking branches`,
-	RunE: runSync,
+  gw sync --no-restack # Sync without restacking branches
+  gw sync --stack      # Sync, restacking only the current stack`,
+	RunE: withOperationLog("sync", runSync),
 }
 
 func init() {
 	syncCmd.Flags().BoolVarP(&syncForce, "force", "f", false, "Don't prompt for confirmation")
 	syncCmd.Flags().BoolVarP(&syncRestack, "restack", "r", true, "Restack branches after syncing")
+	addScopeFlags(syncCmd, &syncScope, "Restack", allScopes...)
 	rootCmd.AddCommand(syncCmd)
 }
 
 func runSync(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -55,7 +71,7 @@ func runSync(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load metadata
-	metadata, err := config.LoadMetadata(repo.GetMetadataPath())
+	metadata, err := config.LoadRepoMetadata(repo)
 	if err != nil {
 		return fmt.Errorf("failed to load metadata: %w", err)
 	}
@@ -70,6 +86,18 @@ func runSync(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Println("✓ Fetched from origin")
 
+	// Merge teammates' stack metadata when enabled in the config
+	if cfg.SyncMetadata {
+		fmt.Println("\nFetching stack metadata...")
+		if err := fetchMetadata(repo, cfg, "origin", metasync.StrategyOurs); err != nil {
+			fmt.Printf("⚠ Could not fetch stack metadata: %v\n", err)
+		}
+		metadata, err = config.LoadRepoMetadata(repo)
+		if err != nil {
+			return fmt.Errorf("failed to load metadata: %w", err)
+		}
+	}
+
 	// 2. Sync trunk with remote
 	fmt.Printf("\nSyncing trunk (%s)...\n", cfg.Trunk)
 	if err := syncTrunkWithRemote(repo, cfg.Trunk, syncForce); err != nil {
@@ -93,9 +121,12 @@ func runSync(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("failed to build stack: %w", err)
 		}
+		if syncScope.isSet() {
+			s = s.Subset(s.SelectScope(originalBranch, syncScope.resolve(stack.ScopeStack)))
+		}
 
 		fmt.Println("\nRestacking branches...")
-		succeeded, failed := restackAllBranches(repo, s)
+		succeeded, failed := restackAllBranches(repo, s, metadata)
 
 		// Report results
 		if len(succeeded) > 0 || len(failed) > 0 {
@@ -111,7 +142,10 @@ func runSync(cmd *cobra.Command, args []string) error {
 			for _, branch := range failed {
 				fmt.Printf("    %s\n", branch)
 			}
-			fmt.Println("\nRun 'gw restack' on each branch to resolve conflicts.")
+			if !dryRun {
+				fmt.Println("\nRun 'gw continue' to restack them in the working tree and resolve the conflicts,")
+				fmt.Println("or 'gw abort' to restore every branch to where it was before the sync.")
+			}
 		}
 
 		if len(succeeded) == 0 && len(failed) == 0 {
@@ -127,6 +161,13 @@ func runSync(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if cfg.SyncMetadata {
+		fmt.Println("\nPushing stack metadata...")
+		if err := pushMetadata(repo, "origin"); err != nil {
+			fmt.Printf("⚠ Could not push stack metadata: %v\n", err)
+		}
+	}
+
 	fmt.Println("\nSync complete.")
 	return nil
 }
@@ -235,7 +276,7 @@ func cleanStaleBranches(repo *git.Repo, metadata *config.Metadata, force bool) e
 		metadata.UntrackBranch(branch)
 	}
 
-	if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
 		return fmt.Errorf("failed to save metadata: %w", err)
 	}
 
@@ -246,30 +287,39 @@ func cleanStaleBranches(repo *git.Repo, metadata *config.Metadata, force bool) e
 // deleteMergedBranches finds branches merged into trunk and prompts to delete them
 func deleteMergedBranches(repo *git.Repo, metadata *config.Metadata, trunk string, force bool) error {
 	var mergedBranches []string
+	methods := map[string]string{}
 
 	for branch := range metadata.Branches {
 		if branch == trunk {
 			continue
 		}
 
-		isMerged, err := repo.IsMergedInto(branch, trunk)
+		// Squash and rebase merges leave the branch tip off trunk, so compare content too
+		method, err := repo.DetectMerge(branch, trunk)
 		if err != nil {
 			continue
 		}
 
-		if isMerged {
+		if method != "" {
 			mergedBranches = append(mergedBranches, branch)
+			methods[branch] = method
 		}
 	}
 
 	if len(mergedBranches) == 0 {
 		return nil
 	}
+	sort.Strings(mergedBranches)
 
 	fmt.Printf("\nFound %d branch(es) merged into %s:\n", len(mergedBranches), trunk)
 
 	for _, branch := range mergedBranches {
-		fmt.Printf("  - %s\n", branch)
+		fmt.Printf("  - %s (%s)\n", branch, describeMergeMethod(methods[branch]))
+	}
+
+	// Children move off merged branches whether or not those get deleted
+	if err := reparentMergedChildren(repo, metadata, trunk, methods); err != nil {
+		return err
 	}
 
 	if force {
@@ -322,6 +372,81 @@ func deleteMergedBranches(repo *git.Repo, metadata *config.Metadata, trunk strin
 	return nil
 }
 
+// reparentMergedChildren moves the children of merged branches onto their closest
+// unmerged ancestor, usually trunk. The merged branch's tip becomes the child's
+// recorded base, so restacking runs "rebase --onto trunk <tip>" and replays only
+// the child's own commits instead of the merged ones already on trunk.
+func reparentMergedChildren(repo *git.Repo, metadata *config.Metadata, trunk string, merged map[string]string) error {
+	var branches []string
+	for branch := range metadata.Branches {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+
+	moved := 0
+	for _, branch := range branches {
+		parent, _ := metadata.GetParent(branch)
+		if merged[branch] != "" || merged[parent] == "" {
+			continue
+		}
+
+		newParent := parent
+		for merged[newParent] != "" && newParent != trunk {
+			next, ok := metadata.GetParent(newParent)
+			if !ok {
+				next = trunk
+			}
+			newParent = next
+		}
+
+		tip, err := repo.GetBranchCommit(parent)
+		if err != nil {
+			return err
+		}
+		// A parent amended after the child forked leaves the recorded base as the
+		// fork point; merge-base would reach back past the parent's old commits
+		base := tip
+		if !repo.IsAncestor(tip, branch) {
+			base = metadata.GetParentSHA(branch)
+			if base == "" || !repo.IsAncestor(base, branch) {
+				base, err = repo.GetMergeBase(branch, parent)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := metadata.UpdateParent(branch, newParent); err != nil {
+			return fmt.Errorf("failed to update parent for '%s': %w", branch, err)
+		}
+		if err := metadata.SetParentSHA(branch, base); err != nil {
+			return err
+		}
+		fmt.Printf("  ↳ Moved %s onto %s (was on %s)\n", branch, newParent, parent)
+		moved++
+	}
+
+	if moved == 0 {
+		return nil
+	}
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
+		return fmt.Errorf("failed to save metadata: %w", err)
+	}
+	return nil
+}
+
+// describeMergeMethod explains how a merged branch was detected
+func describeMergeMethod(method string) string {
+	switch method {
+	case git.MergedByPatchID:
+		return "rebase-merged: all commits found on trunk"
+	case git.MergedByTree:
+		return "squash-merged: changes already on trunk"
+	default:
+		return "merged"
+	}
+}
+
 // deleteBranchAndCleanup deletes a branch and updates metadata
 func deleteBranchAndCleanup(repo *git.Repo, metadata *config.Metadata, branch string) error {
 	// Update children to point to deleted branch's parent
@@ -345,46 +470,99 @@ func deleteBranchAndCleanup(repo *git.Repo, metadata *config.Metadata, branch st
 	metadata.UntrackBranch(branch)
 
 	// Save metadata
-	return metadata.Save(repo.GetMetadataPath())
+	return config.SaveRepoMetadata(repo, metadata)
 }
 
-// restackAllBranches restacks all branches in topological order, skipping those with conflicts
-func restackAllBranches(repo *git.Repo, s *stack.Stack) (succeeded, failed []string) {
+// restackAllBranches restacks all branches in topological order, skipping those with conflicts.
+// When any branch fails, the restack state is kept with the failed branches queued.
+func restackAllBranches(repo *git.Repo, s *stack.Stack, metadata *config.Metadata) (succeeded, failed []string) {
 	branches := s.GetTopologicalOrder()
 
-	for _, node := range branches {
+	// Record progress so an interrupted sync can be rolled back with 'gw abort'
+	statePath := repo.GetRestackStatePath()
+	originalBranch, _ := repo.GetCurrentBranch()
+	state := restack.New(currentOperation, originalBranch)
+
+	for i, node := range branches {
 		if node.Parent == nil {
 			continue
 		}
 
 		// Check if needs rebase
-		needsRebase, err := repo.IsBehind(node.Name, node.Parent.Name)
+		recordedBase := metadata.GetParentSHA(node.Name)
+		needsRebase, err := repo.IsBehind(node.Name, node.Parent.Name, recordedBase)
 		if err != nil {
 			continue
 		}
 
 		if !needsRebase {
+			_ = recordParentSHA(repo, metadata, node.Name, node.Parent.Name)
+			continue
+		}
+
+		base, err := repo.GetRestackBase(node.Name, node.Parent.Name, recordedBase)
+		if err != nil {
 			continue
 		}
 
+		state.Queue = state.Queue[:0]
+		for _, next := range branches[i:] {
+			state.Queue = append(state.Queue, next.Name)
+		}
+		if sha, err := repo.GetBranchCommit(node.Name); err == nil {
+			state.Remember(node.Name, sha, recordedBase)
+		}
+		_ = saveRestackState(statePath, state)
+
 		fmt.Printf("  Rebasing %s onto %s...", node.Name, node.Parent.Name)
 
-		// Try rebase
-		err = repo.Rebase(node.Name, node.Parent.Name)
-		if err != nil {
-			// Abort and record failure
-			_ = repo.AbortRebase()
+		// Replay in memory; conflicting branches are skipped without touching the working tree
+		restacked, conflicts, err := restackInMemory(repo, node.Name, node.Parent.Name, base)
+		if err == nil && !restacked && len(conflicts) == 0 {
+			err = repo.RebaseOnto(node.Name, node.Parent.Name, base)
+			if err != nil {
+				_ = repo.AbortRebase()
+			}
+		}
+		if err != nil || len(conflicts) > 0 {
 			failed = append(failed, node.Name)
 			fmt.Println(" ✗ conflict")
 		} else {
 			succeeded = append(succeeded, node.Name)
+			_ = recordParentSHA(repo, metadata, node.Name, node.Parent.Name)
 			fmt.Println(" ✓")
 		}
 	}
 
+	if len(failed) == 0 {
+		_ = clearRestackState(statePath)
+		return succeeded, failed
+	}
+
+	// Keep the state so 'gw continue' can retry the failed branches and their
+	// descendants in the working tree, or 'gw abort' can undo the whole sync
+	state.Queue = pendingBranches(branches, failed)
+	_ = saveRestackState(statePath, state)
 	return succeeded, failed
 }
 
+// pendingBranches lists the failed branches and their descendants in the given order
+func pendingBranches(branches []*stack.Node, failed []string) []string {
+	pending := make(map[string]bool, len(failed))
+	for _, branch := range failed {
+		pending[branch] = true
+	}
+
+	queue := []string{}
+	for _, node := range branches {
+		if pending[node.Name] || (node.Parent != nil && pending[node.Parent.Name]) {
+			pending[node.Name] = true
+			queue = append(queue, node.Name)
+		}
+	}
+	return queue
+}
+
 // confirm reads a y/n response from stdin
 func confirm() bool {
 	reader := bufio.NewReader(os.Stdin)
@@ -108,7 +108,7 @@ func TestRestackAllBranchesSuccess(t *testing.T) {
 		t.Fatalf("failed to build stack: %v", err)
 	}
 
-	succeeded, failed := restackAllBranches(repo.repo, s)
+	succeeded, failed := restackAllBranches(repo.repo, s, repo.metadata)
 	if len(failed) != 0 {
 		t.Fatalf("expected no restack failures, got %v", failed)
 	}
@@ -284,3 +284,179 @@ func TestRunSyncInteractiveFlow(t *testing.T) {
 		t.Fatalf("runSync interactive failed: %v", err)
 	}
 }
+
+func TestDeleteMergedBranchesSquashAndRebase(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-squashed", "main")
+	repo.commitFile(t, "s1.txt", "one", "squash one")
+	repo.commitFile(t, "s2.txt", "two", "squash two")
+	repo.createBranch(t, "feat-rebased", "main")
+	repo.commitFile(t, "r.txt", "r", "rebase commit")
+	repo.createBranch(t, "feat-open", "main")
+	repo.commitFile(t, "o.txt", "o", "open commit")
+
+	if err := repo.repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	repo.commitFile(t, "main.txt", "main", "trunk moves on")
+	if _, err := repo.repo.RunGitCommand("merge", "--squash", "feat-squashed"); err != nil {
+		t.Fatalf("failed to squash: %v", err)
+	}
+	if _, err := repo.repo.RunGitCommand("commit", "-m", "Squash feat-squashed"); err != nil {
+		t.Fatalf("failed to commit squash: %v", err)
+	}
+	if _, err := repo.repo.RunGitCommand("cherry-pick", "main..feat-rebased"); err != nil {
+		t.Fatalf("failed to cherry-pick: %v", err)
+	}
+
+	if err := deleteMergedBranches(repo.repo, repo.metadata, "main", true); err != nil {
+		t.Fatalf("deleteMergedBranches failed: %v", err)
+	}
+
+	for _, branch := range []string{"feat-squashed", "feat-rebased"} {
+		if repo.repo.BranchExists(branch) || repo.metadata.IsTracked(branch) {
+			t.Fatalf("expected %s to be deleted", branch)
+		}
+	}
+	if !repo.repo.BranchExists("feat-open") || !repo.metadata.IsTracked("feat-open") {
+		t.Fatalf("expected unmerged branch to be kept")
+	}
+}
+
+func TestSyncReparentsChildrenOfSquashedBranch(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	// The parent rewrites the same file twice, so replaying it onto the squash conflicts
+	repo.createBranch(t, "feat-parent", "main")
+	repo.commitFile(t, "shared.txt", "v1", "parent v1")
+	repo.commitFile(t, "shared.txt", "v2", "parent v2")
+	repo.createBranch(t, "feat-child", "feat-parent")
+	repo.commitFile(t, "child.txt", "child", "child commit")
+
+	if err := repo.repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	if _, err := repo.repo.RunGitCommand("merge", "--squash", "feat-parent"); err != nil {
+		t.Fatalf("failed to squash: %v", err)
+	}
+	if _, err := repo.repo.RunGitCommand("commit", "-m", "Squash feat-parent"); err != nil {
+		t.Fatalf("failed to commit squash: %v", err)
+	}
+	parentTip, err := repo.repo.GetBranchCommit("feat-parent")
+	if err != nil {
+		t.Fatalf("failed to get parent tip: %v", err)
+	}
+
+	// Declining the deletion still moves the child onto trunk
+	origStdin := os.Stdin
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	if _, err := w.Write([]byte("n\n")); err != nil {
+		t.Fatalf("failed to write pipe: %v", err)
+	}
+	w.Close()
+	os.Stdin = r
+	defer func() { os.Stdin = origStdin }()
+
+	if err := deleteMergedBranches(repo.repo, repo.metadata, "main", false); err != nil {
+		t.Fatalf("deleteMergedBranches failed: %v", err)
+	}
+	if !repo.repo.BranchExists("feat-parent") {
+		t.Fatalf("expected declined branch to be kept")
+	}
+	if parent, _ := repo.metadata.GetParent("feat-child"); parent != "main" {
+		t.Fatalf("expected feat-child to move onto main, got %q", parent)
+	}
+	if got := repo.metadata.GetParentSHA("feat-child"); got != parentTip {
+		t.Fatalf("expected feat-child base %s, got %s", parentTip, got)
+	}
+
+	s, err := stack.BuildStack(repo.repo, repo.cfg, repo.metadata)
+	if err != nil {
+		t.Fatalf("failed to build stack: %v", err)
+	}
+	_, failed := restackAllBranches(repo.repo, s, repo.metadata)
+	for _, branch := range failed {
+		if branch == "feat-child" {
+			t.Fatalf("expected feat-child to restack without conflicts")
+		}
+	}
+
+	count, err := repo.repo.RunGitCommand("rev-list", "--count", "main..feat-child")
+	if err != nil {
+		t.Fatalf("failed to count commits: %v", err)
+	}
+	if count != "1" {
+		t.Fatalf("expected only the child commit on top of main, got %s", count)
+	}
+}
+
+func TestSyncReparentsChildOfAmendedSquashedBranch(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-parent", "main")
+	repo.commitFile(t, "shared.txt", "v1", "parent v1")
+	repo.commitFile(t, "shared.txt", "v2", "parent v2")
+	repo.createBranch(t, "feat-child", "feat-parent")
+	repo.commitFile(t, "child.txt", "child", "child commit")
+
+	forkPoint, err := repo.repo.GetBranchCommit("feat-parent")
+	if err != nil {
+		t.Fatalf("failed to get parent tip: %v", err)
+	}
+	if err := repo.metadata.SetParentSHA("feat-child", forkPoint); err != nil {
+		t.Fatalf("failed to record parent sha: %v", err)
+	}
+
+	// The parent is amended after the child forked, then squash-merged
+	if err := repo.repo.CheckoutBranch("feat-parent"); err != nil {
+		t.Fatalf("failed to checkout parent: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo.dir, "shared.txt"), []byte("v3"), 0644); err != nil {
+		t.Fatalf("failed to write shared file: %v", err)
+	}
+	if _, err := repo.repo.RunGitCommand("commit", "-a", "--amend", "--no-edit"); err != nil {
+		t.Fatalf("failed to amend parent: %v", err)
+	}
+	if err := repo.repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	if _, err := repo.repo.RunGitCommand("merge", "--squash", "feat-parent"); err != nil {
+		t.Fatalf("failed to squash: %v", err)
+	}
+	if _, err := repo.repo.RunGitCommand("commit", "-m", "Squash feat-parent"); err != nil {
+		t.Fatalf("failed to commit squash: %v", err)
+	}
+
+	if err := reparentMergedChildren(repo.repo, repo.metadata, "main", map[string]string{"feat-parent": git.MergedByTree}); err != nil {
+		t.Fatalf("reparentMergedChildren failed: %v", err)
+	}
+	if got := repo.metadata.GetParentSHA("feat-child"); got != forkPoint {
+		t.Fatalf("expected feat-child base %s, got %s", forkPoint, got)
+	}
+
+	s, err := stack.BuildStack(repo.repo, repo.cfg, repo.metadata)
+	if err != nil {
+		t.Fatalf("failed to build stack: %v", err)
+	}
+	_, failed := restackAllBranches(repo.repo, s, repo.metadata)
+	for _, branch := range failed {
+		if branch == "feat-child" {
+			t.Fatalf("expected feat-child to restack without conflicts")
+		}
+	}
+
+	count, err := repo.repo.RunGitCommand("rev-list", "--count", "main..feat-child")
+	if err != nil {
+		t.Fatalf("failed to count commits: %v", err)
+	}
+	if count != "1" {
+		t.Fatalf("expected only the child commit on top of main, got %s", count)
+	}
+}
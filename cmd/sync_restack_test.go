@@ -3,10 +3,12 @@ package cmd
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/israelmalagutti/git-wrapper/internal/config"
 	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/restack"
 )
 
 func TestRunSyncRestack(t *testing.T) {
@@ -85,3 +87,112 @@ func TestRunSyncRestack(t *testing.T) {
 		t.Fatalf("runSync restack failed: %v", err)
 	}
 }
+
+func TestRunSyncConflictThenAbort(t *testing.T) {
+	localDir, _, cleanup := setupRepoWithRemote(t)
+	defer cleanup()
+
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get cwd: %v", err)
+	}
+	defer os.Chdir(origDir)
+	if err := os.Chdir(localDir); err != nil {
+		t.Fatalf("failed to chdir: %v", err)
+	}
+
+	repo, err := git.NewRepo()
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+
+	cfg := config.NewConfig("main")
+	if err := cfg.Save(repo.GetConfigPath()); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	metadata := &config.Metadata{Branches: map[string]*config.BranchMetadata{}}
+
+	commit := func(file, contents string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(localDir, file), []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", file, err)
+		}
+		if _, err := repo.RunGitCommand("add", file); err != nil {
+			t.Fatalf("failed to add %s: %v", file, err)
+		}
+		if _, err := repo.RunGitCommand("commit", "-m", "update "+file); err != nil {
+			t.Fatalf("failed to commit %s: %v", file, err)
+		}
+	}
+	branch := func(name, parent, file, contents string) {
+		t.Helper()
+		if err := repo.CheckoutBranch(parent); err != nil {
+			t.Fatalf("failed to checkout %s: %v", parent, err)
+		}
+		if err := repo.CreateBranch(name); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+		if err := repo.CheckoutBranch(name); err != nil {
+			t.Fatalf("failed to checkout %s: %v", name, err)
+		}
+		commit(file, contents)
+		metadata.TrackBranch(name, parent)
+	}
+
+	// feat-a restacks cleanly, feat-b conflicts with main and takes feat-c with it
+	branch("feat-a", "main", "a.txt", "a")
+	branch("feat-b", "main", "shared.txt", "feat")
+	branch("feat-c", "feat-b", "c.txt", "c")
+	if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+		t.Fatalf("failed to save metadata: %v", err)
+	}
+
+	if err := repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	commit("shared.txt", "main")
+	if _, err := repo.RunGitCommand("push", "origin", "main"); err != nil {
+		t.Fatalf("failed to push main: %v", err)
+	}
+
+	before := map[string]string{}
+	for _, name := range []string{"feat-a", "feat-b", "feat-c"} {
+		before[name], _ = repo.GetBranchCommit(name)
+	}
+
+	prevForce := syncForce
+	prevRestack := syncRestack
+	defer func() {
+		syncForce = prevForce
+		syncRestack = prevRestack
+	}()
+	syncForce = true
+	syncRestack = true
+
+	if err := runSync(nil, nil); err != nil {
+		t.Fatalf("runSync failed: %v", err)
+	}
+	if after, _ := repo.GetBranchCommit("feat-a"); after == before["feat-a"] {
+		t.Fatalf("expected feat-a to be restacked by sync")
+	}
+
+	state, err := restack.Load(repo.GetRestackStatePath())
+	if err != nil {
+		t.Fatalf("failed to load restack state: %v", err)
+	}
+	if state == nil || strings.Join(state.Queue, ",") != "feat-b,feat-c" {
+		t.Fatalf("expected feat-b and feat-c to be queued, got %+v", state)
+	}
+
+	if err := runAbort(nil, nil); err != nil {
+		t.Fatalf("runAbort failed: %v", err)
+	}
+	for name, sha := range before {
+		if after, _ := repo.GetBranchCommit(name); after != sha {
+			t.Fatalf("expected %s back at %s, got %s", name, sha, after)
+		}
+	}
+	if state, _ := restack.Load(repo.GetRestackStatePath()); state != nil {
+		t.Fatalf("expected abort to clear the restack state")
+	}
+}
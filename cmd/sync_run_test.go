@@ -49,3 +49,51 @@ func TestRunSync(t *testing.T) {
 		t.Fatalf("runSync failed: %v", err)
 	}
 }
+
+func TestRunSyncExchangesMetadata(t *testing.T) {
+	localDir, _, cleanup := setupRepoWithRemote(t)
+	defer cleanup()
+
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get cwd: %v", err)
+	}
+	defer os.Chdir(origDir)
+	if err := os.Chdir(localDir); err != nil {
+		t.Fatalf("failed to chdir: %v", err)
+	}
+
+	repo, err := git.NewRepo()
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+
+	cfg := config.NewConfig("main")
+	cfg.SyncMetadata = true
+	if err := cfg.Save(repo.GetConfigPath()); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	if err := runCreate(nil, []string{"feat-shared"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if _, err := repo.RunGitCommand("push", "origin", "feat-shared"); err != nil {
+		t.Fatalf("failed to push branch: %v", err)
+	}
+
+	prevForce := syncForce
+	prevRestack := syncRestack
+	defer func() {
+		syncForce = prevForce
+		syncRestack = prevRestack
+	}()
+
+	syncForce = true
+	syncRestack = false
+
+	if err := runSync(nil, nil); err != nil {
+		t.Fatalf("runSync failed: %v", err)
+	}
+	if _, err := repo.RunGitCommand("ls-remote", "--exit-code", "origin", config.MetadataRefPrefix+"feat-shared"); err != nil {
+		t.Fatalf("expected sync to push metadata: %v", err)
+	}
+}
@@ -3,15 +3,17 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/israelmalagutti/git-wrapper/internal/config"
-	"github.com/israelmalagutti/git-wrapper/internal/git"
 	"github.com/israelmalagutti/git-wrapper/internal/stack"
 	"github.com/spf13/cobra"
 )
 
+var topJSON bool
+
 var topCmd = &cobra.Command{
 	Use:   "top",
 	Short: "Jump to the top of the stack",
@@ -19,8 +21,12 @@ var topCmd = &cobra.Command{
 
 If multiple leaf branches exist, prompts for selection.
 
+With --json, prints the top branches as JSON instead of switching to one,
+in the format described in USAGE.md.
+
 Example:
-  gw top    # Jump to top of stack`,
+  gw top    # Jump to top of stack
+  gw top --json  # List the tips above the current branch`,
 	Aliases: []string{"t"},
 	Args:    cobra.NoArgs,
 	RunE:    runTop,
@@ -28,11 +34,12 @@ Example:
 
 func init() {
 	rootCmd.AddCommand(topCmd)
+	topCmd.Flags().BoolVar(&topJSON, "json", false, "Print the top branches as JSON without switching")
 }
 
 func runTop(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -44,7 +51,7 @@ func runTop(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load metadata
-	metadata, err := config.LoadMetadata(repo.GetMetadataPath())
+	metadata, err := config.LoadRepoMetadata(repo)
 	if err != nil {
 		return fmt.Errorf("failed to load metadata: %w", err)
 	}
@@ -70,6 +77,15 @@ func runTop(cmd *cobra.Command, args []string) error {
 	// Find all leaf branches reachable from current node
 	leaves := findLeaves(node)
 
+	if topJSON {
+		tips := []string{currentBranch}
+		if len(leaves) > 0 {
+			tips = nodeNames(leaves)
+			sort.Strings(tips)
+		}
+		return printStackJSON(repo, s, metadata, tips)
+	}
+
 	if len(leaves) == 0 {
 		return fmt.Errorf("already at top of stack")
 	}
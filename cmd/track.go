@@ -8,7 +8,6 @@ import (
 	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/israelmalagutti/git-wrapper/internal/colors"
 	"github.com/israelmalagutti/git-wrapper/internal/config"
-	"github.com/israelmalagutti/git-wrapper/internal/git"
 	"github.com/spf13/cobra"
 )
 
@@ -23,7 +22,7 @@ You'll be prompted to select which branch is the parent of this branch.
 Example:
   gw track              # Track current branch
   gw track feature-1    # Track specific branch`,
-	RunE: runTrack,
+	RunE: withOperationLog("track", runTrack),
 }
 
 func init() {
@@ -32,7 +31,7 @@ func init() {
 
 func runTrack(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -62,7 +61,7 @@ func runTrack(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load metadata
-	metadata, err := config.LoadMetadata(repo.GetMetadataPath())
+	metadata, err := config.LoadRepoMetadata(repo)
 	if err != nil {
 		return fmt.Errorf("failed to load metadata: %w", err)
 	}
@@ -131,8 +130,13 @@ func runTrack(cmd *cobra.Command, args []string) error {
 	// Track the branch
 	metadata.TrackBranch(branchToTrack, parent)
 
+	// Record where the branch diverged from its parent
+	if mergeBase, err := repo.GetMergeBase(branchToTrack, parent); err == nil {
+		_ = metadata.SetParentSHA(branchToTrack, mergeBase)
+	}
+
 	// Save metadata
-	if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
 		return fmt.Errorf("failed to save metadata: %w", err)
 	}
 
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/forge"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/stack"
+	"github.com/israelmalagutti/git-wrapper/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+const (
+	tuiHelp       = "↑/k ↓/j move · enter checkout · r restack · m move · n rename · f fold · d delete · v diff · q quit"
+	tuiMovingHelp = "↑/k ↓/j pick the new parent · enter move here · esc cancel"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Browse and edit the stack in a full-screen view",
+	Long: `Open a full-screen view of the stack, as drawn by 'gw log', with a cursor
+over the branches.
+
+Keys act on the branch under the cursor:
+  ↑/k, ↓/j   Move the cursor
+  enter, c   Check out the branch
+  r          Restack the branch and its descendants
+  m          Pick the branch up; move the cursor to the new parent and
+             press enter to move it there, or esc to cancel
+  n          Rename the branch
+  f          Fold the branch into its parent
+  d          Delete the branch
+  v          Show the diff against the parent
+  q, esc     Quit
+
+Restack, rename, fold and delete check the branch out and then run the same
+code as 'gw restack', 'gw rename', 'gw fold' and 'gw delete', so they prompt,
+print and record undo entries as usual.`,
+	Args: cobra.NoArgs,
+	RunE: runTui,
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+}
+
+func runTui(cmd *cobra.Command, args []string) error {
+	// Initialize repository
+	repo, err := openRepo()
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	terminal, err := tui.Open(os.Stdin, os.Stdout)
+	if err != nil {
+		return err
+	}
+	defer terminal.Close()
+
+	selected, status := "", ""
+	for {
+		view, current, err := loadTuiView(repo)
+		if err != nil {
+			return err
+		}
+		if selected == "" || !view.Select(selected) {
+			view.Select(current)
+		}
+		view.Status = status
+
+		// Navigate until a key asks for an action
+		action, moving, err := readTuiAction(terminal, view)
+		if err != nil {
+			return err
+		}
+		if action == "quit" {
+			return nil
+		}
+
+		selected = view.Selected()
+		status, err = runTuiAction(terminal, repo, action, selected, moving)
+		if err != nil {
+			return err
+		}
+		if branch, err := repo.GetCurrentBranch(); err == nil && action != "diff" {
+			selected = branch
+		}
+	}
+}
+
+// loadTuiView renders the stack as 'gw log' does and returns it with the current branch
+func loadTuiView(repo *git.Repo) (*tui.View, string, error) {
+	cfg, err := config.Load(repo.GetConfigPath())
+	if err != nil {
+		return nil, "", err
+	}
+
+	metadata, err := config.LoadRepoMetadata(repo)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load metadata: %w", err)
+	}
+
+	s, err := stack.BuildStack(repo, cfg, metadata)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build stack: %w", err)
+	}
+
+	// Show the cached pull request status without asking the forge
+	if cache, err := forge.LoadCache(repo.GetPRCachePath()); err == nil {
+		s.Annotations = prAnnotations(cache)
+	}
+
+	known := make(map[string]bool, len(s.Nodes))
+	for name := range s.Nodes {
+		known[name] = true
+	}
+
+	view := tui.NewView(s.RenderTree(repo, stack.TreeOptions{ShowCommitSHA: true}), known)
+	view.Help = tuiHelp
+	return view, s.Current, nil
+}
+
+// readTuiAction handles cursor keys until one of the action keys is pressed.
+// For a move it also returns the branch that was picked up.
+func readTuiAction(terminal *tui.Terminal, view *tui.View) (string, string, error) {
+	for {
+		if err := terminal.Draw(view.Render(terminal.Height())); err != nil {
+			return "", "", err
+		}
+
+		event, err := terminal.ReadEvent()
+		if err != nil {
+			return "", "", err
+		}
+
+		switch {
+		case event.Key == tui.KeyUp || event.Rune == 'k':
+			view.Up()
+		case event.Key == tui.KeyDown || event.Rune == 'j':
+			view.Down()
+		case event.Key == tui.KeyInterrupt:
+			return "quit", "", nil
+		case view.Moving != "":
+			// Dropping the picked-up branch, or cancelling
+			switch event.Key {
+			case tui.KeyEnter:
+				return "move", view.Moving, nil
+			case tui.KeyEscape:
+				view.Moving = ""
+				view.Status = ""
+				view.Help = tuiHelp
+			}
+		case event.Key == tui.KeyEscape || event.Rune == 'q':
+			return "quit", "", nil
+		case event.Key == tui.KeyEnter || event.Rune == 'c':
+			return "checkout", "", nil
+		case event.Rune == 'm':
+			view.Moving = view.Selected()
+			view.Status = fmt.Sprintf("Moving %s", colors.BranchCurrent(view.Moving))
+			view.Help = tuiMovingHelp
+		case event.Rune == 'r':
+			return "restack", "", nil
+		case event.Rune == 'n':
+			return "rename", "", nil
+		case event.Rune == 'f':
+			return "fold", "", nil
+		case event.Rune == 'd':
+			return "delete", "", nil
+		case event.Rune == 'v':
+			return "diff", "", nil
+		}
+	}
+}
+
+// tuiDone is the status shown after each action succeeds
+var tuiDone = map[string]string{
+	"checkout": "Checked out",
+	"restack":  "Restacked",
+	"move":     "Moved",
+	"rename":   "Renamed",
+	"fold":     "Folded",
+	"delete":   "Deleted",
+	"diff":     "Showed diff of",
+}
+
+// runTuiAction runs an action on branch with the normal screen restored and
+// returns the status line to show afterwards
+func runTuiAction(terminal *tui.Terminal, repo *git.Repo, action, branch, moving string) (string, error) {
+	if err := terminal.Suspend(); err != nil {
+		return "", err
+	}
+
+	actionErr := tuiAction(repo, action, branch, moving)
+
+	// Checking out is quick and says nothing worth reading, so return right away
+	if action != "checkout" || actionErr != nil {
+		if actionErr != nil {
+			fmt.Printf("%s %v\n", colors.Error("✗"), actionErr)
+		}
+		fmt.Print(colors.Muted("\nPress any key to return to gw tui"))
+		if err := terminal.WaitForKey(); err != nil {
+			return "", err
+		}
+	}
+
+	if err := terminal.Resume(); err != nil {
+		return "", err
+	}
+
+	if actionErr != nil {
+		return fmt.Sprintf("%s Could not %s %s", colors.Error("✗"), action, branch), nil
+	}
+	if action == "move" {
+		branch = moving
+	}
+	return fmt.Sprintf("%s %s %s", colors.Success("✓"), tuiDone[action], colors.BranchCurrent(branch)), nil
+}
+
+// tuiAction performs action on branch using the command implementations
+func tuiAction(repo *git.Repo, action, branch, moving string) error {
+	switch action {
+	case "move":
+		prevSource := moveSource
+		defer func() { moveSource = prevSource }()
+		moveSource = moving
+		return moveCmd.RunE(moveCmd, []string{branch})
+	case "delete":
+		return deleteCmd.RunE(deleteCmd, []string{branch})
+	case "diff":
+		return showBranchDiff(repo, branch)
+	}
+
+	// The remaining actions work on the checked-out branch
+	if err := repo.CheckoutBranch(branch); err != nil {
+		return err
+	}
+
+	switch action {
+	case "restack":
+		return restackCmd.RunE(restackCmd, nil)
+	case "rename":
+		return renameCmd.RunE(renameCmd, nil)
+	case "fold":
+		return foldCmd.RunE(foldCmd, nil)
+	}
+	return nil
+}
+
+// showBranchDiff shows the changes a branch makes on top of its parent, through git's pager
+func showBranchDiff(repo *git.Repo, branch string) error {
+	metadata, err := config.LoadRepoMetadata(repo)
+	if err != nil {
+		return fmt.Errorf("failed to load metadata: %w", err)
+	}
+
+	parent, ok := metadata.GetParent(branch)
+	if !ok {
+		return fmt.Errorf("branch '%s' has no parent", branch)
+	}
+
+	return repo.RunInteractive("diff", parent+"..."+branch)
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+)
+
+func TestTuiActions(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.commitFile(t, "a.txt", "a", "a commit")
+	repo.createBranch(t, "feat-b", "feat-a")
+	repo.commitFile(t, "b.txt", "b", "b commit")
+	repo.createBranch(t, "feat-c", "main")
+
+	if err := tuiAction(repo.repo, "checkout", "feat-a", ""); err != nil {
+		t.Fatalf("checkout failed: %v", err)
+	}
+	if current, _ := repo.repo.GetCurrentBranch(); current != "feat-a" {
+		t.Fatalf("expected feat-a to be checked out, got %s", current)
+	}
+
+	// Drop feat-b onto feat-c
+	if err := tuiAction(repo.repo, "move", "feat-c", "feat-b"); err != nil {
+		t.Fatalf("move failed: %v", err)
+	}
+	if moveSource != "" {
+		t.Fatalf("expected move source to be reset")
+	}
+	metadata, err := config.LoadRepoMetadata(repo.repo)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if parent, _ := metadata.GetParent("feat-b"); parent != "feat-c" {
+		t.Fatalf("expected feat-b to move onto feat-c, got %s", parent)
+	}
+
+	if err := tuiAction(repo.repo, "restack", "feat-c", ""); err != nil {
+		t.Fatalf("restack failed: %v", err)
+	}
+
+	prevForce := deleteForce
+	defer func() { deleteForce = prevForce }()
+	deleteForce = true
+	if err := tuiAction(repo.repo, "delete", "feat-a", ""); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if repo.repo.BranchExists("feat-a") {
+		t.Fatalf("expected feat-a to be deleted")
+	}
+}
+
+func TestLoadTuiView(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+
+	view, current, err := loadTuiView(repo.repo)
+	if err != nil {
+		t.Fatalf("loadTuiView failed: %v", err)
+	}
+	if current != "feat-a" || !view.Select("feat-a") || !view.Select("main") {
+		t.Fatalf("expected both branches to be selectable")
+	}
+}
@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/oplog"
+	"github.com/spf13/cobra"
+)
+
+var (
+	undoList  bool
+	undoForce bool
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Undo the most recent gw operation",
+	Long: `Undo the most recent mutating gw operation.
+
+Every command that changes branches or stack metadata (create, modify, move,
+fold, delete, split, sync, restack, ...) is recorded in an operation log in
+the git directory. Undo restores the branch refs and stack metadata to their
+state before the operation in a single transaction.
+
+Example:
+  gw undo           # Undo the last operation
+  gw undo --list    # Show the operation log
+  gw undo -f        # Undo without confirmation
+  gw redo           # Re-apply the last undone operation`,
+	Args: cobra.NoArgs,
+	RunE: runUndo,
+}
+
+var redoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "Redo the most recently undone gw operation",
+	Long: `Re-apply the most recently undone gw operation.
+
+Running any other mutating command after an undo discards the redo history.
+
+Example:
+  gw redo     # Redo the last undone operation
+  gw redo -f  # Redo without confirmation`,
+	Args: cobra.NoArgs,
+	RunE: runRedo,
+}
+
+func init() {
+	undoCmd.Flags().BoolVarP(&undoList, "list", "l", false, "Show the operation log")
+	undoCmd.Flags().BoolVarP(&undoForce, "force", "f", false, "Skip confirmation prompt")
+	redoCmd.Flags().BoolVarP(&undoForce, "force", "f", false, "Skip confirmation prompt")
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(redoCmd)
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	if err := noDryRun("undo"); err != nil {
+		return err
+	}
+
+	// Initialize repository
+	repo, err := openRepo()
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	log, err := oplog.Load(repo.GetOperationLogPath())
+	if err != nil {
+		return err
+	}
+
+	if undoList {
+		printOperationLog(log)
+		return nil
+	}
+
+	entry := log.LastApplied()
+	if entry == nil {
+		fmt.Println(colors.Muted("Nothing to undo."))
+		return nil
+	}
+
+	if isRebaseInProgress(repo) {
+		return fmt.Errorf("a rebase is in progress; finish it with 'gw continue' or 'git rebase --abort' first")
+	}
+
+	if !undoForce {
+		ok, err := confirmOperation("Undo", entry)
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	if err := oplog.Undo(repo, entry); err != nil {
+		return fmt.Errorf("failed to undo '%s': %w", entry.Command, err)
+	}
+
+	entry.Undone = true
+	if err := log.Save(repo.GetOperationLogPath()); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Undid %s\n", colors.Success("✓"), describeOperation(entry))
+	return nil
+}
+
+func runRedo(cmd *cobra.Command, args []string) error {
+	if err := noDryRun("redo"); err != nil {
+		return err
+	}
+
+	// Initialize repository
+	repo, err := openRepo()
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	log, err := oplog.Load(repo.GetOperationLogPath())
+	if err != nil {
+		return err
+	}
+
+	entry := log.NextRedo()
+	if entry == nil {
+		fmt.Println(colors.Muted("Nothing to redo."))
+		return nil
+	}
+
+	if isRebaseInProgress(repo) {
+		return fmt.Errorf("a rebase is in progress; finish it with 'gw continue' or 'git rebase --abort' first")
+	}
+
+	if !undoForce {
+		ok, err := confirmOperation("Redo", entry)
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	if err := oplog.Redo(repo, entry); err != nil {
+		return fmt.Errorf("failed to redo '%s': %w", entry.Command, err)
+	}
+
+	entry.Undone = false
+	if err := log.Save(repo.GetOperationLogPath()); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Redid %s\n", colors.Success("✓"), describeOperation(entry))
+	return nil
+}
+
+// confirmOperation asks before undoing or redoing an entry
+func confirmOperation(action string, entry *oplog.Entry) (bool, error) {
+	confirm := false
+	prompt := &survey.Confirm{
+		Message: fmt.Sprintf("%s %s?", action, describeOperation(entry)),
+		Default: false,
+	}
+	if err := askOne(prompt, &confirm); err != nil {
+		return false, fmt.Errorf("confirmation cancelled: %w", err)
+	}
+	if !confirm {
+		fmt.Printf("%s cancelled\n", action)
+	}
+	return confirm, nil
+}
+
+// describeOperation returns a one-line summary of an entry
+func describeOperation(entry *oplog.Entry) string {
+	summary := fmt.Sprintf("'gw %s'", entry.Command)
+	if branches := entry.Branches(); len(branches) > 0 {
+		summary += fmt.Sprintf(" (%s)", strings.Join(branches, ", "))
+	}
+	return summary
+}
+
+// printOperationLog prints the recorded operations, newest first
+func printOperationLog(log *oplog.Log) {
+	if len(log.Entries) == 0 {
+		fmt.Println(colors.Muted("No operations recorded."))
+		return
+	}
+
+	next := log.LastApplied()
+	for i := len(log.Entries) - 1; i >= 0; i-- {
+		entry := log.Entries[i]
+
+		marker := " "
+		if entry == next {
+			marker = colors.Success("→")
+		}
+
+		line := fmt.Sprintf("%s #%-3d %-10s %s", marker, entry.ID, entry.Command,
+			colors.Muted(entry.Timestamp.Format("2006-01-02 15:04:05")))
+		if branches := entry.Branches(); len(branches) > 0 {
+			line += "  " + strings.Join(branches, ", ")
+		}
+		if entry.Undone {
+			line += colors.Muted(" (undone)")
+		}
+		fmt.Println(line)
+	}
+}
+
+// withOperationLog wraps a mutating command so its effect is recorded for gw undo
+func withOperationLog(command string, run func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		currentOperation = command
+
+		// A dry run changes nothing, so there is nothing to undo
+		if dryRun {
+			return run(cmd, args)
+		}
+
+		repo, err := openRepo()
+		if err != nil {
+			return run(cmd, args)
+		}
+
+		before, err := oplog.TakeSnapshot(repo)
+		if err != nil {
+			return run(cmd, args)
+		}
+
+		runErr := run(cmd, args)
+
+		if err := recordOperation(repo, command, before); err != nil {
+			fmt.Printf("%s Could not record operation for undo: %v\n", colors.Warning("⚠"), err)
+		}
+
+		return runErr
+	}
+}
+
+// recordOperation appends an entry for the changes made since before
+func recordOperation(repo *git.Repo, command string, before *oplog.Snapshot) error {
+	after, err := oplog.TakeSnapshot(repo)
+	if err != nil {
+		return err
+	}
+
+	entry := oplog.NewEntry(command, before, after)
+	if entry == nil {
+		return nil
+	}
+
+	log, err := oplog.Load(repo.GetOperationLogPath())
+	if err != nil {
+		return err
+	}
+
+	log.Append(entry)
+	return log.Save(repo.GetOperationLogPath())
+}
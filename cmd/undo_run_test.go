@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/oplog"
+)
+
+func TestRunUndoRedoCreate(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	prevForce := undoForce
+	prevList := undoList
+	defer func() {
+		undoForce = prevForce
+		undoList = prevList
+	}()
+	undoForce = true
+	undoList = false
+
+	if err := runUndo(nil, nil); err != nil {
+		t.Fatalf("runUndo with empty log failed: %v", err)
+	}
+
+	if err := withOperationLog("create", runCreate)(nil, []string{"feat-undo"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	log, err := oplog.Load(repo.repo.GetOperationLogPath())
+	if err != nil {
+		t.Fatalf("failed to load log: %v", err)
+	}
+	if len(log.Entries) != 1 || log.Entries[0].Command != "create" {
+		t.Fatalf("expected one create entry, got %+v", log.Entries)
+	}
+
+	undoList = true
+	if err := runUndo(nil, nil); err != nil {
+		t.Fatalf("runUndo --list failed: %v", err)
+	}
+	undoList = false
+
+	if err := runUndo(nil, nil); err != nil {
+		t.Fatalf("runUndo failed: %v", err)
+	}
+	if repo.repo.BranchExists("feat-undo") {
+		t.Fatalf("expected feat-undo to be removed")
+	}
+	metadata, err := config.LoadMetadata(repo.repo.GetMetadataPath())
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if metadata.IsTracked("feat-undo") {
+		t.Fatalf("expected feat-undo to be untracked after undo")
+	}
+
+	if err := runRedo(nil, nil); err != nil {
+		t.Fatalf("runRedo failed: %v", err)
+	}
+	if !repo.repo.BranchExists("feat-undo") {
+		t.Fatalf("expected feat-undo to be restored by redo")
+	}
+	metadata, err = config.LoadMetadata(repo.repo.GetMetadataPath())
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if parent, _ := metadata.GetParent("feat-undo"); parent != "main" {
+		t.Fatalf("expected feat-undo parent main after redo, got %q", parent)
+	}
+
+	if err := runRedo(nil, nil); err != nil {
+		t.Fatalf("runRedo with nothing to redo failed: %v", err)
+	}
+}
+
+func TestRunUndoRedoMetadataMigrate(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	prevForce := undoForce
+	defer func() { undoForce = prevForce }()
+	undoForce = true
+
+	repo.createBranch(t, "feat-migrate", "main")
+
+	if err := withOperationLog("metadata migrate", runMetadataMigrate)(nil, []string{"refs"}); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+
+	if err := runUndo(nil, nil); err != nil {
+		t.Fatalf("runUndo failed: %v", err)
+	}
+	cfg, err := config.Load(repo.repo.GetConfigPath())
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if cfg.GetMetadataBackend() != config.MetadataBackendJSON {
+		t.Fatalf("expected json backend after undo, got %s", cfg.GetMetadataBackend())
+	}
+	metadata, err := config.LoadMetadata(repo.repo.GetMetadataPath())
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if parent, _ := metadata.GetParent("feat-migrate"); parent != "main" {
+		t.Fatalf("expected feat-migrate parent main after undo, got %q", parent)
+	}
+	if refs, _ := repo.repo.ListRefs(config.MetadataRefPrefix); len(refs) != 0 {
+		t.Fatalf("expected metadata refs to be removed after undo, got %v", refs)
+	}
+
+	if err := runRedo(nil, nil); err != nil {
+		t.Fatalf("runRedo failed: %v", err)
+	}
+	cfg, err = config.Load(repo.repo.GetConfigPath())
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if cfg.GetMetadataBackend() != config.MetadataBackendRefs {
+		t.Fatalf("expected refs backend after redo, got %s", cfg.GetMetadataBackend())
+	}
+	metadata, err = config.LoadRepoMetadata(repo.repo)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if parent, _ := metadata.GetParent("feat-migrate"); parent != "main" {
+		t.Fatalf("expected feat-migrate parent main after redo, got %q", parent)
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/israelmalagutti/git-wrapper/internal/colors"
 	"github.com/israelmalagutti/git-wrapper/internal/config"
-	"github.com/israelmalagutti/git-wrapper/internal/git"
 	"github.com/spf13/cobra"
 )
 
@@ -28,7 +27,7 @@ Example:
   gw untrack              # Untrack current branch
   gw untrack feature-1    # Untrack specific branch
   gw untrack -f           # Force untrack without confirmation`,
-	RunE: runUntrack,
+	RunE: withOperationLog("untrack", runUntrack),
 }
 
 func init() {
@@ -38,7 +37,7 @@ func init() {
 
 func runUntrack(cmd *cobra.Command, args []string) error {
 	// Initialize repository
-	repo, err := git.NewRepo()
+	repo, err := openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -68,7 +67,7 @@ func runUntrack(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load metadata
-	metadata, err := config.LoadMetadata(repo.GetMetadataPath())
+	metadata, err := config.LoadRepoMetadata(repo)
 	if err != nil {
 		return fmt.Errorf("failed to load metadata: %w", err)
 	}
@@ -115,9 +114,16 @@ func runUntrack(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Reparent children to this branch's parent
+	// Reparent children to this branch's parent. The children now carry this
+	// branch's commits, so they inherit its base rather than keeping its tip
+	parentSHA := metadata.GetParentSHA(branchToUntrack)
 	for _, child := range children {
-		metadata.TrackBranch(child, parent)
+		if err := metadata.UpdateParent(child, parent); err != nil {
+			return fmt.Errorf("failed to reparent '%s': %w", child, err)
+		}
+		if err := metadata.SetParentSHA(child, parentSHA); err != nil {
+			return fmt.Errorf("failed to reparent '%s': %w", child, err)
+		}
 		fmt.Printf("%s Reparented %s to %s\n",
 			colors.Success("✓"),
 			colors.BranchChild(child),
@@ -128,7 +134,7 @@ func runUntrack(cmd *cobra.Command, args []string) error {
 	metadata.UntrackBranch(branchToUntrack)
 
 	// Save metadata
-	if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+	if err := config.SaveRepoMetadata(repo, metadata); err != nil {
 		return fmt.Errorf("failed to save metadata: %w", err)
 	}
 
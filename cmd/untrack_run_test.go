@@ -1,6 +1,9 @@
 package cmd
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestRunUntrackConfirmCancel(t *testing.T) {
 	repo := setupCmdTestRepo(t)
@@ -32,3 +35,54 @@ func TestRunUntrackErrors(t *testing.T) {
 		t.Fatalf("runUntrack missing branch should be no-op: %v", err)
 	}
 }
+
+func TestRunUntrackMiddleBranchKeepsCommitsOnRestack(t *testing.T) {
+	repo := setupCmdTestRepo(t)
+	defer repo.cleanup()
+
+	repo.createBranch(t, "feat-a", "main")
+	repo.commitFile(t, "a.txt", "a", "A commit")
+	repo.createBranch(t, "feat-b", "feat-a")
+	repo.commitFile(t, "b.txt", "b", "B commit")
+
+	// Record bases the way gw create does
+	for branch, parent := range map[string]string{"feat-a": "main", "feat-b": "feat-a"} {
+		sha, err := repo.repo.GetBranchCommit(parent)
+		if err != nil {
+			t.Fatalf("failed to get %s: %v", parent, err)
+		}
+		if err := repo.metadata.SetParentSHA(branch, sha); err != nil {
+			t.Fatalf("failed to set parent sha: %v", err)
+		}
+	}
+	if err := repo.metadata.Save(repo.repo.GetMetadataPath()); err != nil {
+		t.Fatalf("failed to save metadata: %v", err)
+	}
+
+	if err := repo.repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	repo.commitFile(t, "main.txt", "main", "main commit")
+
+	prevForce := untrackForce
+	defer func() { untrackForce = prevForce }()
+	untrackForce = true
+
+	if err := runUntrack(nil, []string{"feat-a"}); err != nil {
+		t.Fatalf("runUntrack failed: %v", err)
+	}
+	if err := runStackRestack(nil, nil); err != nil {
+		t.Fatalf("runStackRestack failed: %v", err)
+	}
+
+	out, err := repo.repo.RunGitCommand("log", "--format=%s", "main..feat-b")
+	if err != nil {
+		t.Fatalf("failed to log feat-b: %v", err)
+	}
+	if !strings.Contains(out, "A commit") || !strings.Contains(out, "B commit") {
+		t.Fatalf("expected feat-b to keep A and B commits, got %q", out)
+	}
+	if !repo.repo.IsAncestor("main", "feat-b") {
+		t.Fatalf("expected feat-b to be restacked onto main")
+	}
+}
@@ -0,0 +1,299 @@
+// Package absorb works out which commits staged changes belong to, for 'gw absorb'.
+package absorb
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Hunk is one hunk of a unified diff of a modified file
+type Hunk struct {
+	File string
+	// OldStart and OldCount locate the hunk, context included, in the old file
+	OldStart int
+	OldCount int
+	// Blame lists the old line numbers that decide where the hunk belongs: the
+	// lines it removes, and for pure insertions the lines right around them
+	Blame []int
+
+	header string // the file's diff header, up to the first @@
+	text   string // the @@ line and the hunk body
+}
+
+// Skipped is a file section of the diff that absorb does not handle
+type Skipped struct {
+	File   string
+	Reason string
+}
+
+// Location describes the hunk by the lines that place it, as file:line or file:first-last
+func (h Hunk) Location() string {
+	if len(h.Blame) == 0 {
+		return fmt.Sprintf("%s:%d", h.File, h.OldStart)
+	}
+	first, last := h.Blame[0], h.Blame[len(h.Blame)-1]
+	if first == last {
+		return fmt.Sprintf("%s:%d", h.File, first)
+	}
+	return fmt.Sprintf("%s:%d-%d", h.File, first, last)
+}
+
+// Patch builds a patch that git apply accepts from the given hunks. Hunks of
+// the same file may share context lines, so each gets its own file header and
+// git applies them one after another, bottom of the file first.
+func Patch(hunks []Hunk) string {
+	sorted := append([]Hunk(nil), hunks...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].File != sorted[j].File {
+			return sorted[i].File < sorted[j].File
+		}
+		return sorted[i].OldStart > sorted[j].OldStart
+	})
+
+	var patch strings.Builder
+	for _, h := range sorted {
+		patch.WriteString(h.header)
+		patch.WriteString(h.text)
+	}
+	return patch.String()
+}
+
+// ParseDiff splits the output of git diff into hunks. Files that are added,
+// deleted, renamed, binary or change mode are returned as skipped.
+func ParseDiff(diff string) ([]Hunk, []Skipped) {
+	var hunks []Hunk
+	var skipped []Skipped
+
+	for _, section := range splitSections(diff) {
+		header, body, _ := strings.Cut(section, "\n@@")
+		if body != "" {
+			body = "@@" + body
+			header += "\n"
+		}
+
+		file := sectionFile(header)
+		if reason := skipReason(header, body); reason != "" {
+			skipped = append(skipped, Skipped{File: file, Reason: reason})
+			continue
+		}
+
+		for _, text := range splitHunks(body) {
+			parsed, err := parseHunks(text)
+			if err != nil {
+				skipped = append(skipped, Skipped{File: file, Reason: err.Error()})
+				continue
+			}
+			for _, h := range parsed {
+				h.File, h.header = file, header
+				hunks = append(hunks, h)
+			}
+		}
+	}
+
+	return hunks, skipped
+}
+
+// splitSections splits a diff into one section per file
+func splitSections(diff string) []string {
+	var sections []string
+	for _, part := range strings.Split(diff, "\ndiff --git ") {
+		part = strings.TrimPrefix(part, "diff --git ")
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		if !strings.HasSuffix(part, "\n") {
+			part += "\n"
+		}
+		sections = append(sections, "diff --git "+part)
+	}
+	return sections
+}
+
+// sectionFile returns the file a section changes, from its +++ or diff --git line
+func sectionFile(header string) string {
+	for _, line := range strings.Split(header, "\n") {
+		if strings.HasPrefix(line, "+++ b/") {
+			return strings.TrimPrefix(line, "+++ b/")
+		}
+	}
+	first := strings.SplitN(header, "\n", 2)[0]
+	if i := strings.LastIndex(first, " b/"); i >= 0 {
+		return first[i+3:]
+	}
+	return first
+}
+
+// skipReason explains why a file section cannot be absorbed, or returns ""
+func skipReason(header, body string) string {
+	switch {
+	case strings.Contains(header, "\nnew file mode"):
+		return "new file"
+	case strings.Contains(header, "\ndeleted file mode"):
+		return "deleted file"
+	case strings.Contains(header, "\nrename from"), strings.Contains(header, "\ncopy from"):
+		return "renamed file"
+	case strings.Contains(header, "\nBinary files"), strings.Contains(header, "\nGIT binary patch"):
+		return "binary file"
+	case strings.Contains(header, "\nold mode"):
+		return "mode change"
+	case body == "":
+		return "no changes to absorb"
+	}
+	return ""
+}
+
+// splitHunks splits the body of a file section at each @@ line
+func splitHunks(body string) []string {
+	var hunks []string
+	lines := strings.SplitAfter(body, "\n")
+	var current strings.Builder
+	for _, line := range lines {
+		if strings.HasPrefix(line, "@@") && current.Len() > 0 {
+			hunks = append(hunks, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		hunks = append(hunks, current.String())
+	}
+	return hunks
+}
+
+// diffLine is one line of a hunk body with its position in the old and new file
+type diffLine struct {
+	kind byte
+	text string
+	old  int
+	new  int
+}
+
+// parseHunks reads a hunk as printed by git and splits it into one hunk per
+// group of changed lines, each with the context lines around it, so that
+// nearby changes from different commits can go their separate ways
+func parseHunks(text string) ([]Hunk, error) {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+
+	ranges := strings.Fields(lines[0])
+	if len(ranges) < 3 || !strings.HasPrefix(ranges[1], "-") || !strings.HasPrefix(ranges[2], "+") {
+		return nil, fmt.Errorf("malformed hunk header")
+	}
+	oldLine, _, err := parseRange(strings.TrimPrefix(ranges[1], "-"))
+	if err != nil {
+		return nil, err
+	}
+	newLine, _, err := parseRange(strings.TrimPrefix(ranges[2], "+"))
+	if err != nil {
+		return nil, err
+	}
+
+	var body []diffLine
+	for _, line := range lines[1:] {
+		if line == "" {
+			line = " "
+		}
+		// "\ No newline at end of file" belongs to the line before it
+		if line[0] == '\\' && len(body) > 0 {
+			body[len(body)-1].text += "\n" + line
+			continue
+		}
+		body = append(body, diffLine{kind: line[0], text: line, old: oldLine, new: newLine})
+		switch line[0] {
+		case ' ':
+			oldLine++
+			newLine++
+		case '-':
+			oldLine++
+		case '+':
+			newLine++
+		}
+	}
+
+	var hunks []Hunk
+	for i := 0; i < len(body); i++ {
+		if body[i].kind == ' ' {
+			continue
+		}
+		start := i
+		for i < len(body) && body[i].kind != ' ' {
+			i++
+		}
+		hunks = append(hunks, groupHunk(body, start, i))
+	}
+	return hunks, nil
+}
+
+// groupHunk builds the hunk for the changed lines body[start:end] and the
+// context line on either side of them
+func groupHunk(body []diffLine, start, end int) Hunk {
+	first, last := start, end
+	if first > 0 {
+		first--
+	}
+	if last < len(body) {
+		last++
+	}
+	group := body[first:last]
+
+	var h Hunk
+	var text strings.Builder
+	oldStart, newStart, newCount := 0, 0, 0
+	var removed, context []int
+	for _, line := range group {
+		text.WriteString(line.text + "\n")
+		if line.kind != '+' {
+			if h.OldCount == 0 {
+				oldStart = line.old
+			}
+			h.OldCount++
+		}
+		if line.kind != '-' {
+			if newCount == 0 {
+				newStart = line.new
+			}
+			newCount++
+		}
+		switch line.kind {
+		case '-':
+			removed = append(removed, line.old)
+		case ' ':
+			context = append(context, line.old)
+		}
+	}
+
+	// An empty side is numbered by the line it follows
+	if h.OldCount == 0 {
+		oldStart = group[0].old - 1
+	}
+	if newCount == 0 {
+		newStart = group[0].new - 1
+	}
+	h.OldStart = oldStart
+
+	// Removed lines place a hunk; pure insertions are placed by their neighbours
+	h.Blame = removed
+	if len(removed) == 0 {
+		h.Blame = context
+	}
+
+	h.text = fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", oldStart, h.OldCount, newStart, newCount) + text.String()
+	return h
+}
+
+// parseRange parses "start,count" or "start" from a hunk header
+func parseRange(spec string) (int, int, error) {
+	startText, countText, hasCount := strings.Cut(spec, ",")
+	start, err := strconv.Atoi(startText)
+	if err != nil {
+		return 0, 0, fmt.Errorf("malformed hunk range %q", spec)
+	}
+	count := 1
+	if hasCount {
+		if count, err = strconv.Atoi(countText); err != nil {
+			return 0, 0, fmt.Errorf("malformed hunk range %q", spec)
+		}
+	}
+	return start, count, nil
+}
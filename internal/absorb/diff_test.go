@@ -0,0 +1,96 @@
+package absorb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testDiff = `diff --git a/f.txt b/f.txt
+index 88e604a..9484e62 100644
+--- a/f.txt
++++ b/f.txt
+@@ -2,8 +2,9 @@
+ 2
+-three
++THREE
+ 4
+-5
++five
+ 6
+ 7
++ins
+ 8
+ 9
+diff --git a/n.txt b/n.txt
+new file mode 100644
+index 0000000..3e75765
+--- /dev/null
++++ b/n.txt
+@@ -0,0 +1 @@
++new
+diff --git a/img.png b/img.png
+index 1111111..2222222 100644
+Binary files a/img.png and b/img.png differ
+`
+
+func TestParseDiff(t *testing.T) {
+	hunks, skipped := ParseDiff(testDiff)
+
+	wantSkipped := []Skipped{{File: "n.txt", Reason: "new file"}, {File: "img.png", Reason: "binary file"}}
+	if !reflect.DeepEqual(skipped, wantSkipped) {
+		t.Errorf("skipped = %v, want %v", skipped, wantSkipped)
+	}
+
+	// The hunk is split into one per group of changed lines
+	if len(hunks) != 3 {
+		t.Fatalf("expected 3 hunks, got %d", len(hunks))
+	}
+	wantBlame := [][]int{{3}, {5}, {7, 8}}
+	wantLocation := []string{"f.txt:3", "f.txt:5", "f.txt:7-8"}
+	for i, h := range hunks {
+		if h.File != "f.txt" {
+			t.Errorf("hunk %d file = %q", i, h.File)
+		}
+		if !reflect.DeepEqual(h.Blame, wantBlame[i]) {
+			t.Errorf("hunk %d blame = %v, want %v", i, h.Blame, wantBlame[i])
+		}
+		if h.Location() != wantLocation[i] {
+			t.Errorf("hunk %d location = %q, want %q", i, h.Location(), wantLocation[i])
+		}
+	}
+
+	if want := "@@ -7,2 +7,3 @@\n 7\n+ins\n 8\n"; hunks[2].text != want {
+		t.Errorf("insertion hunk = %q, want %q", hunks[2].text, want)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	hunks, _ := ParseDiff(testDiff)
+	patch := Patch(hunks[:2])
+
+	// Each hunk has its own header, the one further down the file first
+	if got := strings.Count(patch, "diff --git a/f.txt b/f.txt\n"); got != 2 {
+		t.Errorf("expected 2 file headers, got %d:\n%s", got, patch)
+	}
+	if strings.Index(patch, "+five") > strings.Index(patch, "+THREE") {
+		t.Errorf("expected the lower hunk first:\n%s", patch)
+	}
+	if !strings.Contains(patch, "@@ -2,3 +2,3 @@\n 2\n-three\n+THREE\n 4\n") {
+		t.Errorf("unexpected patch:\n%s", patch)
+	}
+}
+
+func TestParseDiffNoNewline(t *testing.T) {
+	diff := "diff --git a/f.txt b/f.txt\n--- a/f.txt\n+++ b/f.txt\n@@ -1,2 +1,2 @@\n 1\n-2\n\\ No newline at end of file\n+two\n\\ No newline at end of file\n"
+	hunks, skipped := ParseDiff(diff)
+	if len(hunks) != 1 || len(skipped) != 0 {
+		t.Fatalf("expected 1 hunk, got %d hunks and %v", len(hunks), skipped)
+	}
+	if !reflect.DeepEqual(hunks[0].Blame, []int{2}) {
+		t.Errorf("blame = %v, want [2]", hunks[0].Blame)
+	}
+	if !strings.Contains(hunks[0].text, "-2\n\\ No newline at end of file\n+two\n\\ No newline at end of file\n") {
+		t.Errorf("marker lost: %q", hunks[0].text)
+	}
+}
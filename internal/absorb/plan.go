@@ -0,0 +1,75 @@
+package absorb
+
+import "fmt"
+
+// Target is a commit in the current stack that hunks can be absorbed into
+type Target struct {
+	Commit  string
+	Branch  string
+	Subject string
+}
+
+// Assignment is where a hunk goes. Target is nil if the hunk stays staged,
+// and Reason says why.
+type Assignment struct {
+	Hunk   Hunk
+	Target *Target
+	Reason string
+}
+
+// BlameFunc returns the commit that last changed each line of file, indexed from line 1 at [0]
+type BlameFunc func(file string) ([]string, error)
+
+// Assign gives each hunk to the target that last changed all the lines it
+// touches. Hunks whose lines come from several commits, or from a commit
+// that is not a target, are left unassigned.
+func Assign(hunks []Hunk, targets map[string]*Target, blame BlameFunc) []Assignment {
+	blames := map[string][]string{}
+	blameErrs := map[string]error{}
+
+	assignments := make([]Assignment, 0, len(hunks))
+	for _, h := range hunks {
+		lines, ok := blames[h.File]
+		if !ok && blameErrs[h.File] == nil {
+			var err error
+			if lines, err = blame(h.File); err != nil {
+				blameErrs[h.File] = err
+			} else {
+				blames[h.File] = lines
+			}
+		}
+		if err := blameErrs[h.File]; err != nil {
+			assignments = append(assignments, Assignment{Hunk: h, Reason: fmt.Sprintf("could not blame: %v", err)})
+			continue
+		}
+
+		assignments = append(assignments, assignHunk(h, lines, targets))
+	}
+	return assignments
+}
+
+// assignHunk finds the single target behind the blamed lines of h
+func assignHunk(h Hunk, lines []string, targets map[string]*Target) Assignment {
+	if len(h.Blame) == 0 {
+		return Assignment{Hunk: h, Reason: "no existing lines to place it by"}
+	}
+
+	commit := ""
+	for _, line := range h.Blame {
+		if line > len(lines) {
+			continue
+		}
+		switch {
+		case commit == "":
+			commit = lines[line-1]
+		case lines[line-1] != commit:
+			return Assignment{Hunk: h, Reason: "lines last changed in several commits"}
+		}
+	}
+
+	target, ok := targets[commit]
+	if !ok {
+		return Assignment{Hunk: h, Reason: "lines last changed outside the current stack"}
+	}
+	return Assignment{Hunk: h, Target: target}
+}
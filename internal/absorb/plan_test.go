@@ -0,0 +1,50 @@
+package absorb
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAssign(t *testing.T) {
+	hunks, _ := ParseDiff(testDiff)
+	targets := map[string]*Target{
+		"aaa": {Commit: "aaa", Branch: "feat-a", Subject: "Add a"},
+		"bbb": {Commit: "bbb", Branch: "feat-b", Subject: "Add b"},
+	}
+	blame := func(file string) ([]string, error) {
+		// Line 3 from feat-a, line 5 from trunk, lines 7 and 8 from two commits
+		return []string{"main", "main", "aaa", "main", "main", "main", "bbb", "aaa", "main"}, nil
+	}
+
+	assignments := Assign(hunks, targets, blame)
+	if len(assignments) != 3 {
+		t.Fatalf("expected 3 assignments, got %d", len(assignments))
+	}
+	if a := assignments[0]; a.Target == nil || a.Target.Branch != "feat-a" {
+		t.Errorf("expected line 3 to go to feat-a, got %+v", a)
+	}
+	if a := assignments[1]; a.Target != nil || a.Reason != "lines last changed outside the current stack" {
+		t.Errorf("expected line 5 to stay staged, got %+v", a)
+	}
+	if a := assignments[2]; a.Target != nil || a.Reason != "lines last changed in several commits" {
+		t.Errorf("expected lines 7-8 to stay staged, got %+v", a)
+	}
+}
+
+func TestAssignBlameError(t *testing.T) {
+	hunks, _ := ParseDiff(testDiff)
+	calls := 0
+	blame := func(file string) ([]string, error) {
+		calls++
+		return nil, errors.New("boom")
+	}
+
+	for _, a := range Assign(hunks, map[string]*Target{}, blame) {
+		if a.Target != nil || a.Reason != "could not blame: boom" {
+			t.Errorf("unexpected assignment %+v", a)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the file to be blamed once, got %d", calls)
+	}
+}
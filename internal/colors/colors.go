@@ -71,6 +71,24 @@ func IsEnabled() bool {
 	return enabled
 }
 
+// Strip removes ANSI escape codes from text, for output that is not a terminal
+func Strip(text string) string {
+	var result strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\033' && i+1 < len(text) && text[i+1] == '[' {
+			// Skip to the final byte of the escape sequence
+			j := i + 2
+			for j < len(text) && (text[j] < 0x40 || text[j] > 0x7e) {
+				j++
+			}
+			i = j
+			continue
+		}
+		result.WriteByte(text[i])
+	}
+	return result.String()
+}
+
 // apply wraps text with the given ANSI codes if colors are enabled
 func apply(code, text string) string {
 	if !enabled || text == "" {
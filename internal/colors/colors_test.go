@@ -291,3 +291,17 @@ func TestApplyHelpers(t *testing.T) {
 		t.Errorf("expected disabled colors to return plain text, got %q", got)
 	}
 }
+
+func TestStrip(t *testing.T) {
+	prev := enabled
+	defer func() { enabled = prev }()
+	enabled = true
+
+	colored := Success("#12 merged") + " · " + applyMultiple("approved", Bold, Green)
+	if got := Strip(colored); got != "#12 merged · approved" {
+		t.Errorf("expected codes to be stripped, got %q", got)
+	}
+	if got := Strip("plain"); got != "plain" {
+		t.Errorf("expected plain text to be unchanged, got %q", got)
+	}
+}
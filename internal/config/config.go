@@ -7,11 +7,30 @@ import (
 	"time"
 )
 
+// Metadata storage backends selectable in the config
+const (
+	MetadataBackendJSON = "json"
+	MetadataBackendRefs = "refs"
+)
+
 // Config represents the gw configuration
 type Config struct {
-	Version     string    `json:"version"`
-	Trunk       string    `json:"trunk"`
-	Initialized time.Time `json:"initialized"`
+	Version         string    `json:"version"`
+	Trunk           string    `json:"trunk"`
+	Initialized     time.Time `json:"initialized"`
+	MetadataBackend string    `json:"metadataBackend,omitempty"`
+	SyncMetadata    bool      `json:"syncMetadata,omitempty"`
+	Forge           *Forge    `json:"forge,omitempty"`
+}
+
+// Forge configures the code-hosting service pull requests are opened on
+type Forge struct {
+	// Provider names the service; only "github" is supported
+	Provider string `json:"provider,omitempty"`
+	// APIURL overrides the REST API endpoint, e.g. for GitHub Enterprise
+	APIURL string `json:"apiUrl,omitempty"`
+	// Repository is "owner/name", defaulting to the one origin points at
+	Repository string `json:"repository,omitempty"`
 }
 
 // Load reads the config from the specified path
@@ -60,3 +79,16 @@ func IsInitialized(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
 }
+
+// GetMetadataBackend returns the configured metadata backend, defaulting to JSON
+func (c *Config) GetMetadataBackend() string {
+	if c.MetadataBackend == "" {
+		return MetadataBackendJSON
+	}
+	return c.MetadataBackend
+}
+
+// IsValidMetadataBackend reports whether backend names a known metadata backend
+func IsValidMetadataBackend(backend string) bool {
+	return backend == MetadataBackendJSON || backend == MetadataBackendRefs
+}
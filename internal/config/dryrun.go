@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+)
+
+// DryRunStore keeps saved metadata in memory instead of writing it, so a dry
+// run can report what would change. Loads after a save see the saved metadata.
+type DryRunStore struct {
+	Base  MetadataStore
+	saved *Metadata
+}
+
+// Load returns the last saved metadata, or the base store's
+func (s *DryRunStore) Load() (*Metadata, error) {
+	if s.saved == nil {
+		return s.Base.Load()
+	}
+	return s.saved.clone(), nil
+}
+
+// Save keeps a copy of m
+func (s *DryRunStore) Save(m *Metadata) error {
+	s.saved = m.clone()
+	return nil
+}
+
+// Clear forgets every branch
+func (s *DryRunStore) Clear() error {
+	s.saved = &Metadata{Branches: make(map[string]*BranchMetadata)}
+	return nil
+}
+
+// Changes describes how the saved metadata differs from the base store's
+func (s *DryRunStore) Changes() ([]string, error) {
+	if s.saved == nil {
+		return nil, nil
+	}
+	before, err := s.Base.Load()
+	if err != nil {
+		return nil, err
+	}
+	return DiffMetadata(before, s.saved), nil
+}
+
+// clone returns a copy of m that shares nothing with it
+func (m *Metadata) clone() *Metadata {
+	copied := &Metadata{Branches: make(map[string]*BranchMetadata, len(m.Branches))}
+	for name, branch := range m.Branches {
+		b := *branch
+		copied.Branches[name] = &b
+	}
+	return copied
+}
+
+// DiffMetadata lists the changes from before to after, one line per branch
+// added or removed and per field changed, sorted by branch
+func DiffMetadata(before, after *Metadata) []string {
+	names := map[string]bool{}
+	for name := range before.Branches {
+		names[name] = true
+	}
+	for name := range after.Branches {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var lines []string
+	for _, name := range sorted {
+		was, now := before.Branches[name], after.Branches[name]
+		switch {
+		case was == nil:
+			lines = append(lines, fmt.Sprintf("+ %s (parent %s)", name, now.Parent))
+		case now == nil:
+			lines = append(lines, fmt.Sprintf("- %s (parent %s)", name, was.Parent))
+		default:
+			change := func(field, from, to string) {
+				if from != to {
+					lines = append(lines, fmt.Sprintf("~ %s: %s %s → %s", name, field, orNone(from), orNone(to)))
+				}
+			}
+			change("parent", was.Parent, now.Parent)
+			change("parent commit", shortSHA(was.ParentSHA), shortSHA(now.ParentSHA))
+			change("tracked", strconv.FormatBool(was.Tracked), strconv.FormatBool(now.Tracked))
+			change("pull request", prNumber(was.PRNumber), prNumber(now.PRNumber))
+		}
+	}
+	return lines
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func prNumber(number int) string {
+	if number == 0 {
+		return ""
+	}
+	return "#" + strconv.Itoa(number)
+}
+
+// OverrideMetadataStore makes OpenMetadataStore return store for repo until
+// the returned function is called
+func OverrideMetadataStore(repo *git.Repo, store MetadataStore) func() {
+	storeOverrides[repo.GetCommonDir()] = store
+	return func() { delete(storeOverrides, repo.GetCommonDir()) }
+}
+
+// storeOverrides holds the stores set by OverrideMetadataStore, keyed by the
+// repository's common git directory
+var storeOverrides = map[string]MetadataStore{}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/git/gittest"
+)
+
+func TestDiffMetadata(t *testing.T) {
+	before := &Metadata{Branches: map[string]*BranchMetadata{
+		"feat-a": {Parent: "main", Tracked: true},
+		"feat-b": {Parent: "feat-a", ParentSHA: "1111111aaaa", Tracked: true},
+		"old":    {Parent: "main", Tracked: true},
+	}}
+	after := &Metadata{Branches: map[string]*BranchMetadata{
+		"feat-a": {Parent: "main", Tracked: true, PRNumber: 7},
+		"feat-b": {Parent: "main", ParentSHA: "2222222bbbb", Tracked: true},
+		"feat-c": {Parent: "feat-b", Tracked: true},
+	}}
+
+	want := []string{
+		"~ feat-a: pull request (none) → #7",
+		"~ feat-b: parent feat-a → main",
+		"~ feat-b: parent commit 1111111 → 2222222",
+		"+ feat-c (parent feat-b)",
+		"- old (parent main)",
+	}
+	if got := DiffMetadata(before, after); !reflect.DeepEqual(got, want) {
+		t.Fatalf("DiffMetadata = %q, want %q", got, want)
+	}
+	if got := DiffMetadata(before, before); len(got) != 0 {
+		t.Fatalf("expected no changes, got %q", got)
+	}
+}
+
+func TestDryRunStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata")
+	base := &FileStore{Path: path}
+	initial := &Metadata{Branches: map[string]*BranchMetadata{"feat-a": {Parent: "main", Tracked: true}}}
+	if err := base.Save(initial); err != nil {
+		t.Fatalf("failed to save metadata: %v", err)
+	}
+
+	store := &DryRunStore{Base: base}
+	if changes, err := store.Changes(); err != nil || len(changes) != 0 {
+		t.Fatalf("expected no changes before a save, got %q, %v", changes, err)
+	}
+
+	metadata, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	metadata.TrackBranch("feat-b", "feat-a")
+	if err := store.Save(metadata); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Later loads see the save, but the file is untouched
+	metadata.UntrackBranch("feat-a")
+	reloaded, _ := store.Load()
+	if !reloaded.IsTracked("feat-a") || !reloaded.IsTracked("feat-b") {
+		t.Fatalf("expected the saved copy, got %+v", reloaded.Branches)
+	}
+	onDisk, _ := base.Load()
+	if onDisk.IsTracked("feat-b") {
+		t.Fatalf("expected the file to be unchanged")
+	}
+	if changes, _ := store.Changes(); !reflect.DeepEqual(changes, []string{"+ feat-b (parent feat-a)"}) {
+		t.Fatalf("unexpected changes %q", changes)
+	}
+}
+
+func TestOverrideMetadataStore(t *testing.T) {
+	runner := gittest.NewRunner(t)
+	runner.ExpectRepo(t.TempDir())
+	repo, err := git.NewRepoWithRunner(runner)
+	if err != nil {
+		t.Fatalf("NewRepoWithRunner failed: %v", err)
+	}
+
+	override := &DryRunStore{Base: &FileStore{Path: repo.GetMetadataPath()}}
+	restore := OverrideMetadataStore(repo, override)
+	if store, _ := OpenMetadataStore(repo); store != override {
+		t.Fatalf("expected the override, got %T", store)
+	}
+	restore()
+	if store, _ := OpenMetadataStore(repo); store == override {
+		t.Fatalf("expected the override to be removed")
+	}
+}
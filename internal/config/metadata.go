@@ -9,9 +9,11 @@ import (
 
 // BranchMetadata represents metadata for a tracked branch
 type BranchMetadata struct {
-	Parent  string    `json:"parent"`
-	Tracked bool      `json:"tracked"`
-	Created time.Time `json:"created"`
+	Parent    string    `json:"parent"`
+	ParentSHA string    `json:"parentSha,omitempty"`
+	Tracked   bool      `json:"tracked"`
+	Created   time.Time `json:"created"`
+	PRNumber  int       `json:"prNumber,omitempty"`
 }
 
 // Metadata represents the stack metadata
@@ -108,3 +110,41 @@ func (m *Metadata) UpdateParent(branch, newParent string) error {
 	meta.Parent = newParent
 	return nil
 }
+
+// GetParentSHA returns the parent commit the branch was last based on
+func (m *Metadata) GetParentSHA(branch string) string {
+	meta, exists := m.Branches[branch]
+	if !exists {
+		return ""
+	}
+	return meta.ParentSHA
+}
+
+// SetParentSHA records the parent commit the branch is based on
+func (m *Metadata) SetParentSHA(branch, sha string) error {
+	meta, exists := m.Branches[branch]
+	if !exists {
+		return fmt.Errorf("branch %s is not tracked", branch)
+	}
+	meta.ParentSHA = sha
+	return nil
+}
+
+// GetPRNumber returns the pull request opened for the branch, or 0 if none
+func (m *Metadata) GetPRNumber(branch string) int {
+	meta, exists := m.Branches[branch]
+	if !exists {
+		return 0
+	}
+	return meta.PRNumber
+}
+
+// SetPRNumber records the pull request opened for the branch
+func (m *Metadata) SetPRNumber(branch string, number int) error {
+	meta, exists := m.Branches[branch]
+	if !exists {
+		return fmt.Errorf("branch %s is not tracked", branch)
+	}
+	meta.PRNumber = number
+	return nil
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+)
+
+// MetadataRefPrefix is the ref namespace holding per-branch metadata blobs
+const MetadataRefPrefix = "refs/gw/meta/"
+
+// MetadataStore loads and saves stack metadata
+type MetadataStore interface {
+	Load() (*Metadata, error)
+	Save(m *Metadata) error
+	// Clear removes all stored metadata
+	Clear() error
+}
+
+// FileStore keeps metadata in a single JSON file
+type FileStore struct {
+	Path string
+}
+
+// Load reads the metadata file
+func (s *FileStore) Load() (*Metadata, error) {
+	return LoadMetadata(s.Path)
+}
+
+// Save writes the metadata file
+func (s *FileStore) Save(m *Metadata) error {
+	return m.Save(s.Path)
+}
+
+// Clear removes the metadata file
+func (s *FileStore) Clear() error {
+	if err := os.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove metadata: %w", err)
+	}
+	return nil
+}
+
+// RefStore keeps each branch's metadata as a JSON blob under refs/gw/meta/<branch>
+type RefStore struct {
+	Repo *git.Repo
+	// Prefix overrides the ref namespace, defaulting to MetadataRefPrefix
+	Prefix string
+}
+
+// prefix returns the ref namespace the store reads and writes
+func (s *RefStore) prefix() string {
+	if s.Prefix == "" {
+		return MetadataRefPrefix
+	}
+	return s.Prefix
+}
+
+// Load reads every metadata ref
+func (s *RefStore) Load() (*Metadata, error) {
+	refs, blobs, err := s.Blobs()
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &Metadata{Branches: make(map[string]*BranchMetadata)}
+	for ref, sha := range refs {
+		name := strings.TrimPrefix(ref, s.prefix())
+		branch, err := DecodeBranchMetadata(blobs[sha])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse metadata for %s: %w", name, err)
+		}
+		metadata.Branches[name] = branch
+	}
+
+	return metadata, nil
+}
+
+// Save writes changed branches as new blobs and moves all refs in one transaction
+func (s *RefStore) Save(m *Metadata) error {
+	refs, blobs, err := s.Blobs()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(m.Branches))
+	for name := range m.Branches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	updates := []git.RefUpdate{}
+	for _, name := range names {
+		data, err := EncodeBranchMetadata(m.Branches[name])
+		if err != nil {
+			return err
+		}
+
+		ref := s.prefix() + name
+		oldSHA := refs[ref]
+		if oldSHA != "" && string(blobs[oldSHA]) == string(data) {
+			continue
+		}
+
+		sha, err := s.Repo.HashObject(data)
+		if err != nil {
+			return fmt.Errorf("failed to write metadata: %w", err)
+		}
+		updates = append(updates, git.RefUpdate{Ref: ref, OldSHA: oldSHA, NewSHA: sha})
+	}
+
+	for ref, sha := range refs {
+		if _, ok := m.Branches[strings.TrimPrefix(ref, s.prefix())]; !ok {
+			updates = append(updates, git.RefUpdate{Ref: ref, OldSHA: sha})
+		}
+	}
+
+	if err := s.Repo.UpdateRefs(updates); err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	return nil
+}
+
+// Clear deletes every metadata ref
+func (s *RefStore) Clear() error {
+	refs, err := s.Repo.ListRefs(s.prefix())
+	if err != nil {
+		return err
+	}
+
+	updates := make([]git.RefUpdate, 0, len(refs))
+	for ref, sha := range refs {
+		updates = append(updates, git.RefUpdate{Ref: ref, OldSHA: sha})
+	}
+	return s.Repo.UpdateRefs(updates)
+}
+
+// Blobs returns the metadata refs and the contents of the blobs they point at
+func (s *RefStore) Blobs() (map[string]string, map[string][]byte, error) {
+	refs, err := s.Repo.ListRefs(s.prefix())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+
+	shas := make([]string, 0, len(refs))
+	for _, sha := range refs {
+		shas = append(shas, sha)
+	}
+
+	blobs, err := s.Repo.ReadBlobs(shas)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+	return refs, blobs, nil
+}
+
+// EncodeBranchMetadata returns the blob contents stored for a branch
+func EncodeBranchMetadata(branch *BranchMetadata) ([]byte, error) {
+	data, err := json.MarshalIndent(branch, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	return data, nil
+}
+
+// DecodeBranchMetadata parses a blob written by EncodeBranchMetadata
+func DecodeBranchMetadata(data []byte) (*BranchMetadata, error) {
+	var branch BranchMetadata
+	if err := json.Unmarshal(data, &branch); err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
+
+// NewMetadataStore returns the store for the given backend
+func NewMetadataStore(repo *git.Repo, backend string) (MetadataStore, error) {
+	switch backend {
+	case "", MetadataBackendJSON:
+		return &FileStore{Path: repo.GetMetadataPath()}, nil
+	case MetadataBackendRefs:
+		return &RefStore{Repo: repo}, nil
+	default:
+		return nil, fmt.Errorf("unknown metadata backend %q (expected %q or %q)", backend, MetadataBackendJSON, MetadataBackendRefs)
+	}
+}
+
+// OpenMetadataStore returns the store selected in the repository's config.
+// Repositories without a readable config use the JSON file, and one set by
+// OverrideMetadataStore takes precedence over both.
+func OpenMetadataStore(repo *git.Repo) (MetadataStore, error) {
+	if store, ok := storeOverrides[repo.GetCommonDir()]; ok {
+		return store, nil
+	}
+	cfg, err := Load(repo.GetConfigPath())
+	if err != nil {
+		return &FileStore{Path: repo.GetMetadataPath()}, nil
+	}
+	return NewMetadataStore(repo, cfg.MetadataBackend)
+}
+
+// LoadRepoMetadata loads metadata from the repository's configured store
+func LoadRepoMetadata(repo *git.Repo) (*Metadata, error) {
+	store, err := OpenMetadataStore(repo)
+	if err != nil {
+		return nil, err
+	}
+	return store.Load()
+}
+
+// SaveRepoMetadata saves metadata to the repository's configured store
+func SaveRepoMetadata(repo *git.Repo, m *Metadata) error {
+	store, err := OpenMetadataStore(repo)
+	if err != nil {
+		return err
+	}
+	return store.Save(m)
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+)
+
+func setupStoreRepo(t *testing.T) *git.Repo {
+	t.Helper()
+
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get cwd: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(origDir); err != nil {
+			t.Errorf("failed to restore cwd: %v", err)
+		}
+	})
+
+	dir := t.TempDir()
+	if err := exec.Command("git", "init", dir).Run(); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("failed to chdir: %v", err)
+	}
+
+	repo, err := git.NewRepo()
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	return repo
+}
+
+func TestRefStoreRoundTrip(t *testing.T) {
+	repo := setupStoreRepo(t)
+	store := &RefStore{Repo: repo}
+
+	empty, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(empty.Branches) != 0 {
+		t.Fatalf("expected no branches, got %d", len(empty.Branches))
+	}
+
+	meta := &Metadata{Branches: make(map[string]*BranchMetadata)}
+	meta.TrackBranch("feat-a", "main")
+	meta.TrackBranch("team/feat-b", "feat-a")
+	if err := meta.SetParentSHA("feat-a", "abc123"); err != nil {
+		t.Fatalf("SetParentSHA failed: %v", err)
+	}
+	if err := store.Save(meta); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	refs, err := repo.ListRefs(MetadataRefPrefix)
+	if err != nil {
+		t.Fatalf("ListRefs failed: %v", err)
+	}
+	if _, ok := refs[MetadataRefPrefix+"team/feat-b"]; !ok || len(refs) != 2 {
+		t.Fatalf("unexpected metadata refs: %v", refs)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if parent, _ := loaded.GetParent("team/feat-b"); parent != "feat-a" {
+		t.Fatalf("expected parent feat-a, got %q", parent)
+	}
+	if loaded.GetParentSHA("feat-a") != "abc123" {
+		t.Fatalf("expected parent sha to round trip")
+	}
+
+	// Unchanged branches keep their blob; removed branches lose their ref
+	loaded.UntrackBranch("team/feat-b")
+	if err := store.Save(loaded); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	after, err := repo.ListRefs(MetadataRefPrefix)
+	if err != nil {
+		t.Fatalf("ListRefs failed: %v", err)
+	}
+	if len(after) != 1 || after[MetadataRefPrefix+"feat-a"] != refs[MetadataRefPrefix+"feat-a"] {
+		t.Fatalf("unexpected refs after save: %v", after)
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	cleared, err := repo.ListRefs(MetadataRefPrefix)
+	if err != nil {
+		t.Fatalf("ListRefs failed: %v", err)
+	}
+	if len(cleared) != 0 {
+		t.Fatalf("expected no refs after clear, got %v", cleared)
+	}
+}
+
+func TestOpenMetadataStore(t *testing.T) {
+	repo := setupStoreRepo(t)
+
+	store, err := OpenMetadataStore(repo)
+	if err != nil {
+		t.Fatalf("OpenMetadataStore failed: %v", err)
+	}
+	if _, ok := store.(*FileStore); !ok {
+		t.Fatalf("expected file store without config, got %T", store)
+	}
+
+	cfg := NewConfig("main")
+	cfg.MetadataBackend = MetadataBackendRefs
+	if err := cfg.Save(repo.GetConfigPath()); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	store, err = OpenMetadataStore(repo)
+	if err != nil {
+		t.Fatalf("OpenMetadataStore failed: %v", err)
+	}
+	if _, ok := store.(*RefStore); !ok {
+		t.Fatalf("expected ref store, got %T", store)
+	}
+
+	meta := &Metadata{Branches: make(map[string]*BranchMetadata)}
+	meta.TrackBranch("feat", "main")
+	if err := SaveRepoMetadata(repo, meta); err != nil {
+		t.Fatalf("SaveRepoMetadata failed: %v", err)
+	}
+	if _, err := os.Stat(repo.GetMetadataPath()); !os.IsNotExist(err) {
+		t.Fatalf("expected no metadata file with ref backend")
+	}
+	loaded, err := LoadRepoMetadata(repo)
+	if err != nil {
+		t.Fatalf("LoadRepoMetadata failed: %v", err)
+	}
+	if !loaded.IsTracked("feat") {
+		t.Fatalf("expected feat to be tracked")
+	}
+
+	cfg.MetadataBackend = "bogus"
+	if err := cfg.Save(repo.GetConfigPath()); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	if _, err := OpenMetadataStore(repo); err == nil {
+		t.Fatalf("expected error for unknown backend")
+	}
+}
+
+func TestFileStoreClear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".gw_stack_metadata")
+	store := &FileStore{Path: path}
+
+	meta := &Metadata{Branches: make(map[string]*BranchMetadata)}
+	if err := store.Save(meta); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear of missing file failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected metadata file to be removed")
+	}
+}
@@ -0,0 +1,161 @@
+// Package executor runs a command on several commits at once, each in its own
+// temporary worktree, for 'gw foreach --jobs'.
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+)
+
+// Job is a branch to run the command on, at the given commit
+type Job struct {
+	Branch string
+	Commit string
+}
+
+// Result is the outcome of the command on one job. Skipped jobs did not run
+// because an earlier one failed; Err is set when the command could not start.
+type Result struct {
+	Job      Job
+	ExitCode int
+	Skipped  bool
+	Err      error
+}
+
+// Passed reports whether the command ran and exited successfully
+func (r *Result) Passed() bool {
+	return !r.Skipped && r.Err == nil && r.ExitCode == 0
+}
+
+// Executor runs Command on jobs in temporary worktrees, Jobs at a time
+type Executor struct {
+	Repo     *git.Repo
+	Command  []string
+	Jobs     int
+	FailFast bool
+	// Out receives the command's output, each line prefixed with the branch
+	Out io.Writer
+	// OnDone, if set, is called as each job finishes, one call at a time
+	OnDone func(Result)
+}
+
+// Run runs the command on every job and returns the results in the order of jobs.
+// With FailFast, jobs that have not started when one fails are skipped, as are
+// all jobs that have not started once the repo's context is done.
+func (e *Executor) Run(jobs []Job) ([]Result, error) {
+	dir, err := e.Repo.GetWorktreesDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create worktrees directory: %w", err)
+	}
+	// Cleaning up still has to happen after an interrupt
+	cleanup := e.Repo.WithContext(context.WithoutCancel(e.Repo.Context()))
+	defer func() {
+		_ = cleanup.PruneWorktrees()
+		_ = os.Remove(dir)
+	}()
+
+	width := 0
+	for _, job := range jobs {
+		width = max(width, len(job.Branch))
+	}
+	out := &lineWriter{w: e.Out}
+
+	results := make([]Result, len(jobs))
+	limit := make(chan struct{}, max(e.Jobs, 1))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	failed := false
+
+	done := func(i int, result Result) {
+		mu.Lock()
+		defer mu.Unlock()
+		results[i] = result
+		failed = failed || !result.Passed()
+		if e.OnDone != nil {
+			e.OnDone(result)
+		}
+	}
+
+	for i, job := range jobs {
+		limit <- struct{}{}
+
+		mu.Lock()
+		skip := (e.FailFast && failed) || e.Repo.Context().Err() != nil
+		mu.Unlock()
+		if skip {
+			<-limit
+			done(i, Result{Job: job, Skipped: true})
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, job Job) {
+			defer wg.Done()
+			defer func() { <-limit }()
+
+			prefix := colors.CycleText(fmt.Sprintf("[%-*s]", width, job.Branch), i) + " "
+			done(i, e.runJob(cleanup, dir, job, &prefixWriter{out: out, prefix: prefix}))
+		}(i, job)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// runJob runs the command on job in a new worktree under dir and removes it
+// afterwards through cleanup
+func (e *Executor) runJob(cleanup *git.Repo, dir string, job Job, w *prefixWriter) Result {
+	path, err := os.MkdirTemp(dir, strings.ReplaceAll(job.Branch, "/", "-")+"-*")
+	if err != nil {
+		return Result{Job: job, ExitCode: -1, Err: fmt.Errorf("failed to create worktree: %w", err)}
+	}
+	defer os.RemoveAll(path)
+
+	if err := e.Repo.AddWorktree(path, job.Commit); err != nil {
+		return Result{Job: job, ExitCode: -1, Err: err}
+	}
+	defer func() { _ = cleanup.RemoveWorktree(path) }()
+
+	code, err := RunCommand(e.Repo.Context(), path, job.Branch, e.Command, w, w)
+	w.Flush()
+	return Result{Job: job, ExitCode: code, Err: err}
+}
+
+// RunCommand runs args in dir with GW_BRANCH set to branch and returns its exit
+// code. A single argument is run by the shell; several are run as they are.
+// The error is only set when the command could not be started or ctx was done.
+func RunCommand(ctx context.Context, dir, branch string, args []string, stdout, stderr io.Writer) (int, error) {
+	var cmd *exec.Cmd
+	if len(args) == 1 {
+		cmd = exec.CommandContext(ctx, "sh", "-c", args[0])
+	} else {
+		cmd = exec.CommandContext(ctx, args[0], args[1:]...)
+	}
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GW_BRANCH="+branch)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return -1, ctxErr
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+		return -1, err
+	}
+	return 0, nil
+}
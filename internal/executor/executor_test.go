@@ -0,0 +1,230 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+)
+
+// setupExecutorRepo creates a repository with branches a and b, each adding
+// its own name to f.txt, and returns the directory it is in
+func setupExecutorRepo(t *testing.T) (string, func()) {
+	t.Helper()
+
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get cwd: %v", err)
+	}
+
+	dir := t.TempDir()
+	cmds := [][]string{
+		{"git", "init", dir},
+		{"git", "-C", dir, "config", "user.email", "test@test.com"},
+		{"git", "-C", dir, "config", "user.name", "Test User"},
+		{"git", "-C", dir, "config", "commit.gpgsign", "false"},
+		{"git", "-C", dir, "commit", "--allow-empty", "-m", "Initial"},
+		{"git", "-C", dir, "branch", "-M", "main"},
+	}
+	for _, name := range []string{"a", "b"} {
+		cmds = append(cmds,
+			[]string{"git", "-C", dir, "checkout", "-q", "-b", name, "main"},
+			[]string{"sh", "-c", "echo " + name + " > " + filepath.Join(dir, "f.txt")},
+			[]string{"git", "-C", dir, "add", "f.txt"},
+			[]string{"git", "-C", dir, "commit", "-m", "Add " + name},
+		)
+	}
+	cmds = append(cmds, []string{"git", "-C", dir, "checkout", "-q", "main"})
+	for _, args := range cmds {
+		if err := exec.Command(args[0], args[1:]...).Run(); err != nil {
+			t.Fatalf("failed to run %v: %v", args, err)
+		}
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("failed to chdir: %v", err)
+	}
+	return dir, func() {
+		if err := os.Chdir(origDir); err != nil {
+			t.Errorf("failed to restore cwd: %v", err)
+		}
+	}
+}
+
+func executorJobs(t *testing.T, repo *git.Repo, branches ...string) []Job {
+	t.Helper()
+	var jobs []Job
+	for _, branch := range branches {
+		sha, err := repo.GetBranchCommit(branch)
+		if err != nil {
+			t.Fatalf("failed to get %s: %v", branch, err)
+		}
+		jobs = append(jobs, Job{Branch: branch, Commit: sha})
+	}
+	return jobs
+}
+
+func TestExecutorRun(t *testing.T) {
+	prevColors := colors.IsEnabled()
+	defer colors.SetEnabled(prevColors)
+	colors.SetEnabled(false)
+
+	_, cleanup := setupExecutorRepo(t)
+	defer cleanup()
+
+	repo, err := git.NewRepo()
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+
+	var out bytes.Buffer
+	var done []string
+	e := &Executor{
+		Repo:    repo,
+		Command: []string{`cat f.txt; printf "on $GW_BRANCH"; test "$GW_BRANCH" = a`},
+		Jobs:    2,
+		Out:     &out,
+		OnDone:  func(r Result) { done = append(done, r.Job.Branch) },
+	}
+	results, err := e.Run(executorJobs(t, repo, "a", "b"))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(results) != 2 || !results[0].Passed() || results[1].Passed() || results[1].ExitCode != 1 {
+		t.Fatalf("expected a to pass and b to fail, got %+v", results)
+	}
+	sort.Strings(done)
+	if strings.Join(done, " ") != "a b" {
+		t.Fatalf("expected OnDone for both jobs, got %v", done)
+	}
+
+	// Each job saw its own commit, and every line is prefixed
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	sort.Strings(lines)
+	want := []string{"[a] a", "[a] on a", "[b] b", "[b] on b"}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+
+	dir, _ := repo.GetWorktreesDir()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed, got %v", dir, err)
+	}
+	if list, _ := repo.RunGitCommand("worktree", "list"); strings.Count(list, "\n") != 0 {
+		t.Fatalf("expected only the main worktree, got:\n%s", list)
+	}
+}
+
+func TestExecutorFailFast(t *testing.T) {
+	_, cleanup := setupExecutorRepo(t)
+	defer cleanup()
+
+	repo, err := git.NewRepo()
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+
+	e := &Executor{Repo: repo, Command: []string{"false"}, Jobs: 1, FailFast: true, Out: &bytes.Buffer{}}
+	results, err := e.Run(executorJobs(t, repo, "a", "b"))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if results[0].Skipped || results[0].ExitCode != 1 || !results[1].Skipped {
+		t.Fatalf("expected a to fail and b to be skipped, got %+v", results)
+	}
+}
+
+func TestExecutorFromLinkedWorktree(t *testing.T) {
+	dir, cleanup := setupExecutorRepo(t)
+	defer cleanup()
+
+	linked := filepath.Join(t.TempDir(), "linked")
+	if err := exec.Command("git", "-C", dir, "worktree", "add", "-q", linked, "a").Run(); err != nil {
+		t.Fatalf("failed to add worktree: %v", err)
+	}
+	if err := os.Chdir(linked); err != nil {
+		t.Fatalf("failed to chdir: %v", err)
+	}
+
+	repo, err := git.NewRepo()
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+
+	// Worktrees go in the common directory, and branch a checked out here can still run
+	worktrees, _ := repo.GetWorktreesDir()
+	mainDir, _ := filepath.EvalSymlinks(filepath.Join(dir, ".git"))
+	if got, _ := filepath.EvalSymlinks(filepath.Dir(worktrees)); got != mainDir {
+		t.Fatalf("expected worktrees under %s, got %s", mainDir, worktrees)
+	}
+
+	var out bytes.Buffer
+	e := &Executor{Repo: repo, Command: []string{"cat", "f.txt"}, Jobs: 2, Out: &out}
+	results, err := e.Run(executorJobs(t, repo, "a", "b"))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !results[0].Passed() || !results[1].Passed() {
+		t.Fatalf("expected both to pass, got %+v\n%s", results, out.String())
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := &prefixWriter{out: &lineWriter{w: &out}, prefix: "[x] "}
+
+	_, _ = w.Write([]byte("one\ntw"))
+	_, _ = w.Write([]byte("o\nthree"))
+	if out.String() != "[x] one\n[x] two\n" {
+		t.Fatalf("expected only whole lines before Flush, got %q", out.String())
+	}
+	w.Flush()
+	if out.String() != "[x] one\n[x] two\n[x] three\n" {
+		t.Fatalf("unexpected output after Flush: %q", out.String())
+	}
+}
+
+func TestExecutorCancelled(t *testing.T) {
+	_, cleanup := setupExecutorRepo(t)
+	defer cleanup()
+
+	repo, err := git.NewRepo()
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Interrupted while a is running: b never starts, and a's worktree is still removed
+	e := &Executor{
+		Repo:    repo.WithContext(ctx),
+		Command: []string{"true"},
+		Jobs:    1,
+		Out:     &bytes.Buffer{},
+		OnDone:  func(Result) { cancel() },
+	}
+	results, err := e.Run(executorJobs(t, repo, "a", "b"))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !results[0].Passed() || !results[1].Skipped {
+		t.Fatalf("expected a to pass and b to be skipped, got %+v", results)
+	}
+	if list, _ := repo.RunGitCommand("worktree", "list"); strings.Count(list, "\n") != 0 {
+		t.Fatalf("expected only the main worktree, got:\n%s", list)
+	}
+
+	code, err := RunCommand(ctx, ".", "a", []string{"true"}, &bytes.Buffer{}, &bytes.Buffer{})
+	if code != -1 || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a cancelled command, got %d, %v", code, err)
+	}
+}
@@ -0,0 +1,49 @@
+package executor
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// lineWriter writes whole lines to w, one at a time, for several jobs at once
+type lineWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lineWriter) writeLine(prefix string, line []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = io.WriteString(l.w, prefix)
+	_, _ = l.w.Write(line)
+}
+
+// prefixWriter holds a job's output until it has a full line, then writes it
+// with the job's prefix so lines from parallel jobs do not interleave
+type prefixWriter struct {
+	out    *lineWriter
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		p.out.writeLine(p.prefix, p.buf[:i+1])
+		p.buf = p.buf[i+1:]
+	}
+	return len(b), nil
+}
+
+// Flush writes a last line that did not end in a newline
+func (p *prefixWriter) Flush() {
+	if len(p.buf) > 0 {
+		p.out.writeLine(p.prefix, append(p.buf, '\n'))
+		p.buf = nil
+	}
+}
@@ -0,0 +1,46 @@
+package forge
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Cache holds the last fetched pull request statuses, keyed by branch
+type Cache struct {
+	Fetched  time.Time          `json:"fetched"`
+	Branches map[string]*Status `json:"branches"`
+}
+
+// LoadCache reads the status cache, returning an empty cache if it does not exist
+func LoadCache(path string) (*Cache, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Cache{Branches: make(map[string]*Status)}, nil
+		}
+		return nil, fmt.Errorf("failed to read PR cache: %w", err)
+	}
+
+	var cache Cache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("failed to parse PR cache: %w", err)
+	}
+	if cache.Branches == nil {
+		cache.Branches = make(map[string]*Status)
+	}
+	return &cache, nil
+}
+
+// Save writes the status cache
+func (c *Cache) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal PR cache: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write PR cache: %w", err)
+	}
+	return nil
+}
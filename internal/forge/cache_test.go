@@ -0,0 +1,35 @@
+package forge
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+
+	empty, err := LoadCache(path)
+	if err != nil {
+		t.Fatalf("LoadCache failed: %v", err)
+	}
+	if len(empty.Branches) != 0 {
+		t.Fatalf("expected empty cache, got %+v", empty)
+	}
+
+	cache := &Cache{
+		Fetched:  time.Now(),
+		Branches: map[string]*Status{"feat": {Number: 3, State: StateOpen, CIStatus: CISuccess}},
+	}
+	if err := cache.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadCache(path)
+	if err != nil {
+		t.Fatalf("LoadCache failed: %v", err)
+	}
+	if got := loaded.Branches["feat"]; got == nil || got.Number != 3 || got.CIStatus != CISuccess {
+		t.Fatalf("unexpected cached status: %+v", got)
+	}
+}
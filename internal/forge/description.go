@@ -0,0 +1,31 @@
+package forge
+
+import "strings"
+
+// Markers delimiting the part of a pull request body that gw manages
+const (
+	StackBlockStart = "<!-- gw:stack:start -->"
+	StackBlockEnd   = "<!-- gw:stack:end -->"
+)
+
+// ReplaceStackBlock returns body with its managed block replaced by block.
+// Text outside the markers is kept as is; a body without a block gets one appended.
+// A start marker without an end marker opens a block that runs to the end of the body.
+func ReplaceStackBlock(body, block string) string {
+	managed := StackBlockStart + "\n" + strings.TrimSpace(block) + "\n" + StackBlockEnd
+
+	start := strings.Index(body, StackBlockStart)
+	if start >= 0 {
+		end := len(body)
+		if i := strings.Index(body[start:], StackBlockEnd); i >= 0 {
+			end = start + i + len(StackBlockEnd)
+		}
+		return body[:start] + managed + body[end:]
+	}
+
+	body = strings.TrimRight(body, " \t\r\n")
+	if body == "" {
+		return managed
+	}
+	return body + "\n\n" + managed
+}
@@ -0,0 +1,47 @@
+package forge
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReplaceStackBlock(t *testing.T) {
+	block := "- #1 feat-a"
+	managed := StackBlockStart + "\n- #1 feat-a\n" + StackBlockEnd
+
+	if got := ReplaceStackBlock("", block); got != managed {
+		t.Fatalf("unexpected block for empty body: %q", got)
+	}
+
+	appended := ReplaceStackBlock("Fixes the login form.\n\n", block)
+	if appended != "Fixes the login form.\n\n"+managed {
+		t.Fatalf("unexpected appended block: %q", appended)
+	}
+
+	// Only the managed block changes; text before and after it is kept
+	body := "Intro\n\n" + StackBlockStart + "\nold table\n" + StackBlockEnd + "\n\nOutro"
+	want := "Intro\n\n" + managed + "\n\nOutro"
+	if got := ReplaceStackBlock(body, block); got != want {
+		t.Fatalf("unexpected replaced body: %q", got)
+	}
+
+	if got := ReplaceStackBlock(want, block); got != want {
+		t.Fatalf("expected replacement to be idempotent, got %q", got)
+	}
+
+	// A start marker without an end marker runs to the end of the body
+	trimmed := "Intro\n\n" + StackBlockStart + "\nold table"
+	got := ReplaceStackBlock(trimmed, block)
+	if got != "Intro\n\n"+managed {
+		t.Fatalf("unexpected body for unterminated block: %q", got)
+	}
+	if strings.Count(got, StackBlockStart) != 1 {
+		t.Fatalf("expected a single block, got %q", got)
+	}
+
+	// Author text before the block survives the next sync
+	withText := "Author notes\n\n" + got
+	if again := ReplaceStackBlock(withText, "- #2 feat-b"); !strings.HasPrefix(again, "Author notes\n\n") {
+		t.Fatalf("expected author text to be kept, got %q", again)
+	}
+}
@@ -0,0 +1,59 @@
+package forge
+
+import (
+	"fmt"
+	"io"
+)
+
+// DryRunProvider looks pull requests up through Provider but only prints the
+// ones it would create or update
+type DryRunProvider struct {
+	Provider
+	Out io.Writer
+}
+
+// CreatePullRequest prints the pull request and returns it unnumbered
+func (p *DryRunProvider) CreatePullRequest(opts CreateOptions) (*PullRequest, error) {
+	draft := ""
+	if opts.Draft {
+		draft = " (draft)"
+	}
+	fmt.Fprintf(p.Out, "dry-run: create pull request %s → %s%s: %s\n", opts.Head, opts.Base, draft, opts.Title)
+	return &PullRequest{
+		Title: opts.Title,
+		Body:  opts.Body,
+		Head:  opts.Head,
+		Base:  opts.Base,
+		State: StateOpen,
+		Draft: opts.Draft,
+	}, nil
+}
+
+// UpdatePullRequest prints the fields that would change and returns the
+// pull request as it would be afterwards
+func (p *DryRunProvider) UpdatePullRequest(number int, opts UpdateOptions) (*PullRequest, error) {
+	pr := &PullRequest{Number: number, State: StateOpen}
+	// Pull requests created earlier in the dry run have no number to look up
+	if number != 0 {
+		existing, err := p.Provider.GetPullRequest(number)
+		if err != nil {
+			return nil, err
+		}
+		copied := *existing
+		pr = &copied
+	}
+
+	if opts.Title != nil && *opts.Title != pr.Title {
+		fmt.Fprintf(p.Out, "dry-run: set title of pull request #%d to %q\n", number, *opts.Title)
+		pr.Title = *opts.Title
+	}
+	if opts.Base != nil && *opts.Base != pr.Base {
+		fmt.Fprintf(p.Out, "dry-run: set base of pull request #%d to %s\n", number, *opts.Base)
+		pr.Base = *opts.Base
+	}
+	if opts.Body != nil && *opts.Body != pr.Body {
+		fmt.Fprintf(p.Out, "dry-run: update description of pull request #%d\n", number)
+		pr.Body = *opts.Body
+	}
+	return pr, nil
+}
@@ -0,0 +1,51 @@
+package forge
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/forge/forgetest"
+)
+
+func TestDryRunProvider(t *testing.T) {
+	server := forgetest.NewServer(t, "acme", "widgets")
+	number := server.AddPull(forgetest.PullRequest{Head: "feat-a", Base: "main", State: "open", Title: "Add a"})
+
+	var out bytes.Buffer
+	provider := &DryRunProvider{Provider: NewGitHub(server.URL, "token", "acme", "widgets"), Out: &out}
+
+	// Lookups go to the forge
+	pr, err := provider.FindPullRequest("feat-a")
+	if err != nil || pr == nil || pr.Number != number {
+		t.Fatalf("FindPullRequest = %+v, %v", pr, err)
+	}
+
+	created, err := provider.CreatePullRequest(CreateOptions{Title: "Add b", Head: "feat-b", Base: "feat-a", Draft: true})
+	if err != nil || created.Number != 0 || created.Head != "feat-b" {
+		t.Fatalf("CreatePullRequest = %+v, %v", created, err)
+	}
+
+	base, title := "feat-c", "Add a"
+	updated, err := provider.UpdatePullRequest(number, UpdateOptions{Base: &base, Title: &title})
+	if err != nil || updated.Base != "feat-c" || updated.Title != "Add a" {
+		t.Fatalf("UpdatePullRequest = %+v, %v", updated, err)
+	}
+
+	// Nothing was written
+	if got, _ := provider.GetPullRequest(number); got.Base != "main" {
+		t.Fatalf("expected the pull request to be unchanged, got base %s", got.Base)
+	}
+	if pr, _ := provider.FindPullRequest("feat-b"); pr != nil {
+		t.Fatalf("expected no pull request for feat-b, got %+v", pr)
+	}
+
+	want := "dry-run: create pull request feat-b → feat-a (draft): Add b\n" +
+		"dry-run: set base of pull request #1 to feat-c\n"
+	if out.String() != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+	if strings.Contains(out.String(), "title") {
+		t.Fatalf("an unchanged title should not be printed")
+	}
+}
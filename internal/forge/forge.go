@@ -0,0 +1,130 @@
+// Package forge talks to the code-hosting service that pull requests live on.
+package forge
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+)
+
+// Pull request states
+const (
+	StateOpen   = "open"
+	StateClosed = "closed"
+	StateMerged = "merged"
+)
+
+// PullRequest is a pull request as reported by the forge
+type PullRequest struct {
+	Number  int
+	URL     string
+	Title   string
+	Body    string
+	Head    string
+	Base    string
+	State   string
+	Draft   bool
+	HeadSHA string
+}
+
+// Review decisions
+const (
+	ReviewApproved         = "approved"
+	ReviewChangesRequested = "changes_requested"
+	ReviewRequired         = "review_required"
+)
+
+// CI statuses
+const (
+	CISuccess = "success"
+	CIFailure = "failure"
+	CIPending = "pending"
+)
+
+// Status summarizes a pull request's state, review decision and CI result.
+// Empty ReviewDecision or CIStatus means there is nothing to report.
+type Status struct {
+	Number         int    `json:"number"`
+	URL            string `json:"url"`
+	State          string `json:"state"`
+	Draft          bool   `json:"draft"`
+	ReviewDecision string `json:"reviewDecision,omitempty"`
+	CIStatus       string `json:"ciStatus,omitempty"`
+}
+
+// CreateOptions describes a new pull request
+type CreateOptions struct {
+	Title string
+	Body  string
+	Head  string
+	Base  string
+	Draft bool
+}
+
+// UpdateOptions lists the fields of a pull request to change; nil fields are left alone
+type UpdateOptions struct {
+	Title *string
+	Body  *string
+	Base  *string
+}
+
+// Provider is implemented by each supported forge
+type Provider interface {
+	// FindPullRequest returns the open pull request for head, or nil if there is none
+	FindPullRequest(head string) (*PullRequest, error)
+	GetPullRequest(number int) (*PullRequest, error)
+	CreatePullRequest(opts CreateOptions) (*PullRequest, error)
+	UpdatePullRequest(number int, opts UpdateOptions) (*PullRequest, error)
+	GetStatus(number int) (*Status, error)
+}
+
+// Open returns the provider configured for the repository
+func Open(repo *git.Repo, cfg *config.Config) (Provider, error) {
+	settings := config.Forge{}
+	if cfg.Forge != nil {
+		settings = *cfg.Forge
+	}
+
+	if settings.Provider != "" && settings.Provider != "github" {
+		return nil, fmt.Errorf("unsupported forge provider %q", settings.Provider)
+	}
+
+	repository := settings.Repository
+	if repository == "" {
+		url, err := repo.GetRemoteURL("origin")
+		if err != nil {
+			return nil, err
+		}
+		repository, err = ParseGitHubRepository(url)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	owner, name, ok := strings.Cut(repository, "/")
+	if !ok || owner == "" || name == "" {
+		return nil, fmt.Errorf("invalid repository %q (expected owner/name)", repository)
+	}
+
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+
+	return NewGitHub(settings.APIURL, token, owner, name), nil
+}
+
+var githubURLPattern = regexp.MustCompile(`github\.com[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// ParseGitHubRepository extracts "owner/name" from a GitHub remote URL
+func ParseGitHubRepository(url string) (string, error) {
+	match := githubURLPattern.FindStringSubmatch(strings.TrimSpace(url))
+	if match == nil {
+		return "", fmt.Errorf("cannot determine GitHub repository from remote %q; set forge.repository in the config", url)
+	}
+	return match[1] + "/" + match[2], nil
+}
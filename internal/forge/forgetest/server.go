@@ -0,0 +1,379 @@
+// Package forgetest provides an in-memory stand-in for the GitHub REST API.
+package forgetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// PullRequest is a pull request held by the stand-in
+type PullRequest struct {
+	Number int
+	Title  string
+	Body   string
+	Head   string
+	Base   string
+	State  string // "open" or "closed"
+	Draft  bool
+	Merged bool
+	// HeadSHA is the commit CI statuses are looked up for
+	HeadSHA string
+}
+
+// Review is a pull request review
+type Review struct {
+	User  string
+	State string // APPROVED, CHANGES_REQUESTED, COMMENTED or DISMISSED
+}
+
+// CheckRun is a CI check run on a commit
+type CheckRun struct {
+	Status     string // queued, in_progress or completed
+	Conclusion string
+}
+
+// Server serves the subset of the GitHub REST API that gw uses
+type Server struct {
+	*httptest.Server
+	Owner string
+	Repo  string
+
+	mu        sync.Mutex
+	pulls     map[int]*PullRequest
+	next      int
+	requests  []string
+	reviews   map[int][]Review
+	statuses  map[string][]string
+	checkRuns map[string][]CheckRun
+}
+
+// NewServer starts a stand-in for owner/repo that is closed when the test ends
+func NewServer(t testing.TB, owner, repo string) *Server {
+	t.Helper()
+
+	s := &Server{
+		Owner:     owner,
+		Repo:      repo,
+		pulls:     map[int]*PullRequest{},
+		next:      1,
+		reviews:   map[int][]Review{},
+		statuses:  map[string][]string{},
+		checkRuns: map[string][]CheckRun{},
+	}
+
+	mux := http.NewServeMux()
+	prefix := fmt.Sprintf("/repos/%s/%s", owner, repo)
+	mux.HandleFunc("GET "+prefix+"/pulls", s.listPulls)
+	mux.HandleFunc("POST "+prefix+"/pulls", s.createPull)
+	mux.HandleFunc("GET "+prefix+"/pulls/{number}", s.getPull)
+	mux.HandleFunc("PATCH "+prefix+"/pulls/{number}", s.updatePull)
+	mux.HandleFunc("GET "+prefix+"/pulls/{number}/reviews", s.listReviews)
+	mux.HandleFunc("GET "+prefix+"/commits/{ref}/status", s.combinedStatus)
+	mux.HandleFunc("GET "+prefix+"/commits/{ref}/check-runs", s.listCheckRuns)
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		s.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Pull returns a copy of a pull request, or nil if it does not exist
+func (s *Server) Pull(number int) *PullRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pr, ok := s.pulls[number]
+	if !ok {
+		return nil
+	}
+	copied := *pr
+	return &copied
+}
+
+// Pulls returns copies of all pull requests ordered by number
+func (s *Server) Pulls() []PullRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pulls := make([]PullRequest, 0, len(s.pulls))
+	for _, pr := range s.pulls {
+		pulls = append(pulls, *pr)
+	}
+	sort.Slice(pulls, func(i, j int) bool { return pulls[i].Number < pulls[j].Number })
+	return pulls
+}
+
+// AddPull stores a pull request, assigning the next number, and returns the number
+func (s *Server) AddPull(pr PullRequest) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pr.Number = s.next
+	s.next++
+	if pr.State == "" {
+		pr.State = "open"
+	}
+	s.pulls[pr.Number] = &pr
+	return pr.Number
+}
+
+// UpdatePull changes a stored pull request in place
+func (s *Server) UpdatePull(number int, update func(pr *PullRequest)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pr, ok := s.pulls[number]; ok {
+		update(pr)
+	}
+}
+
+// AddReview records a review on a pull request
+func (s *Server) AddReview(number int, review Review) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reviews[number] = append(s.reviews[number], review)
+}
+
+// AddStatus records a commit status ("success", "pending", "failure" or "error") on sha
+func (s *Server) AddStatus(sha, state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses[sha] = append(s.statuses[sha], state)
+}
+
+// AddCheckRun records a check run on sha
+func (s *Server) AddCheckRun(sha string, run CheckRun) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkRuns[sha] = append(s.checkRuns[sha], run)
+}
+
+// Requests returns every request received as "METHOD path"
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) listPulls(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	head := strings.TrimPrefix(r.URL.Query().Get("head"), s.Owner+":")
+	state := r.URL.Query().Get("state")
+	if state == "" {
+		state = "open"
+	}
+
+	result := []map[string]interface{}{}
+	for _, number := range s.sortedNumbers() {
+		pr := s.pulls[number]
+		if head != "" && pr.Head != head {
+			continue
+		}
+		if state != "all" && pr.State != state {
+			continue
+		}
+		result = append(result, s.render(pr))
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) createPull(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+		Head  string `json:"head"`
+		Base  string `json:"base"`
+		Draft bool   `json:"draft"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.Head == "" || req.Base == "" || req.Head == req.Base {
+		writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+		return
+	}
+	for _, pr := range s.pulls {
+		if pr.Head == req.Head && pr.State == "open" {
+			writeError(w, http.StatusUnprocessableEntity, "A pull request already exists for "+s.Owner+":"+req.Head+".")
+			return
+		}
+	}
+
+	pr := &PullRequest{
+		Number: s.next,
+		Title:  req.Title,
+		Body:   req.Body,
+		Head:   req.Head,
+		Base:   req.Base,
+		State:  "open",
+		Draft:  req.Draft,
+	}
+	s.next++
+	s.pulls[pr.Number] = pr
+	writeJSON(w, http.StatusCreated, s.render(pr))
+}
+
+func (s *Server) getPull(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pr := s.lookup(w, r)
+	if pr == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, s.render(pr))
+}
+
+func (s *Server) updatePull(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Title *string `json:"title"`
+		Body  *string `json:"body"`
+		Base  *string `json:"base"`
+		State *string `json:"state"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pr := s.lookup(w, r)
+	if pr == nil {
+		return
+	}
+	if req.Title != nil {
+		pr.Title = *req.Title
+	}
+	if req.Body != nil {
+		pr.Body = *req.Body
+	}
+	if req.Base != nil {
+		pr.Base = *req.Base
+	}
+	if req.State != nil {
+		pr.State = *req.State
+	}
+	writeJSON(w, http.StatusOK, s.render(pr))
+}
+
+func (s *Server) listReviews(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pr := s.lookup(w, r)
+	if pr == nil {
+		return
+	}
+
+	result := []map[string]interface{}{}
+	for _, review := range s.reviews[pr.Number] {
+		result = append(result, map[string]interface{}{
+			"user":  map[string]interface{}{"login": review.User},
+			"state": review.State,
+		})
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) combinedStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := s.statuses[r.PathValue("ref")]
+	state := "pending"
+	if len(statuses) > 0 {
+		state = "success"
+	}
+	for _, status := range statuses {
+		switch status {
+		case "failure", "error":
+			state = "failure"
+		case "pending":
+			if state != "failure" {
+				state = "pending"
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"state": state, "total_count": len(statuses)})
+}
+
+func (s *Server) listCheckRuns(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := []map[string]interface{}{}
+	for _, run := range s.checkRuns[r.PathValue("ref")] {
+		runs = append(runs, map[string]interface{}{"status": run.Status, "conclusion": run.Conclusion})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"total_count": len(runs), "check_runs": runs})
+}
+
+// lookup returns the pull request named in the path or writes a 404
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) *PullRequest {
+	number, err := strconv.Atoi(r.PathValue("number"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return nil
+	}
+	pr, ok := s.pulls[number]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return nil
+	}
+	return pr
+}
+
+// sortedNumbers returns pull request numbers in ascending order
+func (s *Server) sortedNumbers() []int {
+	numbers := make([]int, 0, len(s.pulls))
+	for number := range s.pulls {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+// render encodes a pull request the way the GitHub API does
+func (s *Server) render(pr *PullRequest) map[string]interface{} {
+	var mergedAt interface{}
+	if pr.Merged {
+		mergedAt = "2024-01-01T00:00:00Z"
+	}
+	return map[string]interface{}{
+		"number":    pr.Number,
+		"html_url":  fmt.Sprintf("https://github.com/%s/%s/pull/%d", s.Owner, s.Repo, pr.Number),
+		"title":     pr.Title,
+		"body":      pr.Body,
+		"state":     pr.State,
+		"draft":     pr.Draft,
+		"merged_at": mergedAt,
+		"head":      map[string]interface{}{"ref": pr.Head, "sha": pr.HeadSHA},
+		"base":      map[string]interface{}{"ref": pr.Base},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
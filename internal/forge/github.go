@@ -0,0 +1,303 @@
+package forge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultGitHubAPIURL is the public GitHub REST endpoint
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+// GitHub implements Provider against the GitHub REST API
+type GitHub struct {
+	apiURL string
+	token  string
+	owner  string
+	repo   string
+	client *http.Client
+}
+
+// NewGitHub returns a GitHub provider; an empty apiURL uses DefaultGitHubAPIURL
+func NewGitHub(apiURL, token, owner, repo string) *GitHub {
+	if apiURL == "" {
+		apiURL = DefaultGitHubAPIURL
+	}
+	return &GitHub{
+		apiURL: strings.TrimSuffix(apiURL, "/"),
+		token:  token,
+		owner:  owner,
+		repo:   repo,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// githubPull mirrors the fields gw reads from a GitHub pull request
+type githubPull struct {
+	Number   int     `json:"number"`
+	HTMLURL  string  `json:"html_url"`
+	Title    string  `json:"title"`
+	Body     string  `json:"body"`
+	State    string  `json:"state"`
+	Draft    bool    `json:"draft"`
+	MergedAt *string `json:"merged_at"`
+	Head     struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+func (p githubPull) toPullRequest() *PullRequest {
+	state := p.State
+	if p.MergedAt != nil {
+		state = StateMerged
+	}
+	return &PullRequest{
+		Number:  p.Number,
+		URL:     p.HTMLURL,
+		Title:   p.Title,
+		Body:    p.Body,
+		Head:    p.Head.Ref,
+		Base:    p.Base.Ref,
+		State:   state,
+		Draft:   p.Draft,
+		HeadSHA: p.Head.SHA,
+	}
+}
+
+// FindPullRequest returns the open pull request for head, or nil if there is none
+func (g *GitHub) FindPullRequest(head string) (*PullRequest, error) {
+	query := url.Values{"head": {g.owner + ":" + head}, "state": {"open"}}
+	var pulls []githubPull
+	if err := g.do(http.MethodGet, g.repoPath("/pulls?"+query.Encode()), nil, &pulls); err != nil {
+		return nil, err
+	}
+	if len(pulls) == 0 {
+		return nil, nil
+	}
+	return pulls[0].toPullRequest(), nil
+}
+
+// GetPullRequest returns a pull request by number
+func (g *GitHub) GetPullRequest(number int) (*PullRequest, error) {
+	var pull githubPull
+	if err := g.do(http.MethodGet, g.repoPath(fmt.Sprintf("/pulls/%d", number)), nil, &pull); err != nil {
+		return nil, err
+	}
+	return pull.toPullRequest(), nil
+}
+
+// CreatePullRequest opens a new pull request
+func (g *GitHub) CreatePullRequest(opts CreateOptions) (*PullRequest, error) {
+	body := map[string]interface{}{
+		"title": opts.Title,
+		"body":  opts.Body,
+		"head":  opts.Head,
+		"base":  opts.Base,
+		"draft": opts.Draft,
+	}
+	var pull githubPull
+	if err := g.do(http.MethodPost, g.repoPath("/pulls"), body, &pull); err != nil {
+		return nil, err
+	}
+	return pull.toPullRequest(), nil
+}
+
+// UpdatePullRequest changes the title, body or base of a pull request
+func (g *GitHub) UpdatePullRequest(number int, opts UpdateOptions) (*PullRequest, error) {
+	body := map[string]interface{}{}
+	if opts.Title != nil {
+		body["title"] = *opts.Title
+	}
+	if opts.Body != nil {
+		body["body"] = *opts.Body
+	}
+	if opts.Base != nil {
+		body["base"] = *opts.Base
+	}
+	var pull githubPull
+	if err := g.do(http.MethodPatch, g.repoPath(fmt.Sprintf("/pulls/%d", number)), body, &pull); err != nil {
+		return nil, err
+	}
+	return pull.toPullRequest(), nil
+}
+
+// GetStatus returns the pull request's state, review decision and combined CI result
+func (g *GitHub) GetStatus(number int) (*Status, error) {
+	pr, err := g.GetPullRequest(number)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{Number: pr.Number, URL: pr.URL, State: pr.State, Draft: pr.Draft}
+	if pr.State != StateOpen {
+		return status, nil
+	}
+
+	if status.ReviewDecision, err = g.reviewDecision(number); err != nil {
+		return nil, err
+	}
+	if pr.HeadSHA != "" {
+		if status.CIStatus, err = g.ciStatus(pr.HeadSHA); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// reviewDecision combines each reviewer's latest verdict
+func (g *GitHub) reviewDecision(number int) (string, error) {
+	var reviews []struct {
+		User struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		State string `json:"state"`
+	}
+	if err := g.do(http.MethodGet, g.repoPath(fmt.Sprintf("/pulls/%d/reviews?per_page=100", number)), nil, &reviews); err != nil {
+		return "", err
+	}
+
+	// Reviews are returned oldest first; comments don't change a verdict
+	latest := map[string]string{}
+	for _, review := range reviews {
+		switch review.State {
+		case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+			latest[review.User.Login] = review.State
+		}
+	}
+
+	decision := ReviewRequired
+	for _, state := range latest {
+		switch state {
+		case "CHANGES_REQUESTED":
+			return ReviewChangesRequested, nil
+		case "APPROVED":
+			decision = ReviewApproved
+		}
+	}
+	return decision, nil
+}
+
+// ciStatus combines commit statuses and check runs for a commit
+func (g *GitHub) ciStatus(sha string) (string, error) {
+	var combined struct {
+		State      string `json:"state"`
+		TotalCount int    `json:"total_count"`
+	}
+	if err := g.do(http.MethodGet, g.repoPath("/commits/"+sha+"/status"), nil, &combined); err != nil {
+		return "", err
+	}
+
+	var checks struct {
+		CheckRuns []struct {
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+		} `json:"check_runs"`
+	}
+	if err := g.do(http.MethodGet, g.repoPath("/commits/"+sha+"/check-runs?per_page=100"), nil, &checks); err != nil {
+		return "", err
+	}
+
+	results := []string{}
+	if combined.TotalCount > 0 {
+		switch combined.State {
+		case "success":
+			results = append(results, CISuccess)
+		case "pending":
+			results = append(results, CIPending)
+		default:
+			results = append(results, CIFailure)
+		}
+	}
+	for _, run := range checks.CheckRuns {
+		switch {
+		case run.Status != "completed":
+			results = append(results, CIPending)
+		case run.Conclusion == "success" || run.Conclusion == "neutral" || run.Conclusion == "skipped":
+			results = append(results, CISuccess)
+		default:
+			results = append(results, CIFailure)
+		}
+	}
+
+	status := ""
+	for _, result := range results {
+		switch {
+		case result == CIFailure:
+			return CIFailure, nil
+		case result == CIPending:
+			status = CIPending
+		case status == "":
+			status = CISuccess
+		}
+	}
+	return status, nil
+}
+
+// repoPath returns the API path for a repository resource
+func (g *GitHub) repoPath(path string) string {
+	return fmt.Sprintf("/repos/%s/%s%s", g.owner, g.repo, path)
+}
+
+// do sends a request and decodes the JSON response into out
+func (g *GitHub) do(method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, g.apiURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(data, &apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return fmt.Errorf("%s %s failed: %s (%d)", method, path, apiErr.Message, resp.StatusCode)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
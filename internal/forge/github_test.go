@@ -0,0 +1,131 @@
+package forge
+
+import (
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/forge/forgetest"
+)
+
+func TestGitHubPullRequests(t *testing.T) {
+	server := forgetest.NewServer(t, "acme", "widgets")
+	gh := NewGitHub(server.URL, "token", "acme", "widgets")
+
+	none, err := gh.FindPullRequest("feat")
+	if err != nil {
+		t.Fatalf("FindPullRequest failed: %v", err)
+	}
+	if none != nil {
+		t.Fatalf("expected no pull request, got %+v", none)
+	}
+
+	created, err := gh.CreatePullRequest(CreateOptions{Title: "Add feat", Body: "body", Head: "feat", Base: "main", Draft: true})
+	if err != nil {
+		t.Fatalf("CreatePullRequest failed: %v", err)
+	}
+	if created.Number != 1 || !created.Draft || created.State != StateOpen || created.Base != "main" {
+		t.Fatalf("unexpected pull request: %+v", created)
+	}
+
+	if _, err := gh.CreatePullRequest(CreateOptions{Title: "Again", Head: "feat", Base: "main"}); err == nil {
+		t.Fatalf("expected error for duplicate pull request")
+	}
+
+	found, err := gh.FindPullRequest("feat")
+	if err != nil || found == nil || found.Number != 1 {
+		t.Fatalf("expected to find PR #1, got %+v (%v)", found, err)
+	}
+
+	base := "develop"
+	updated, err := gh.UpdatePullRequest(1, UpdateOptions{Base: &base})
+	if err != nil {
+		t.Fatalf("UpdatePullRequest failed: %v", err)
+	}
+	if updated.Base != "develop" || updated.Title != "Add feat" {
+		t.Fatalf("unexpected update result: %+v", updated)
+	}
+
+	server.UpdatePull(1, func(pr *forgetest.PullRequest) {
+		pr.State = "closed"
+		pr.Merged = true
+	})
+	merged, err := gh.GetPullRequest(1)
+	if err != nil {
+		t.Fatalf("GetPullRequest failed: %v", err)
+	}
+	if merged.State != StateMerged {
+		t.Fatalf("expected merged state, got %s", merged.State)
+	}
+
+	if _, err := gh.GetPullRequest(42); err == nil {
+		t.Fatalf("expected error for missing pull request")
+	}
+}
+
+func TestParseGitHubRepository(t *testing.T) {
+	tests := map[string]string{
+		"git@github.com:acme/widgets.git":       "acme/widgets",
+		"https://github.com/acme/widgets":       "acme/widgets",
+		"https://github.com/acme/widgets.git":   "acme/widgets",
+		"ssh://git@github.com/acme/widgets.git": "acme/widgets",
+	}
+	for url, want := range tests {
+		got, err := ParseGitHubRepository(url)
+		if err != nil || got != want {
+			t.Errorf("ParseGitHubRepository(%q) = %q, %v; want %q", url, got, err, want)
+		}
+	}
+
+	if _, err := ParseGitHubRepository("/tmp/remote.git"); err == nil {
+		t.Errorf("expected error for non-GitHub remote")
+	}
+}
+
+func TestGitHubGetStatus(t *testing.T) {
+	server := forgetest.NewServer(t, "acme", "widgets")
+	gh := NewGitHub(server.URL, "token", "acme", "widgets")
+
+	number := server.AddPull(forgetest.PullRequest{Head: "feat", Base: "main", State: "open", HeadSHA: "abc"})
+
+	status, err := gh.GetStatus(number)
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status.State != StateOpen || status.ReviewDecision != ReviewRequired || status.CIStatus != "" {
+		t.Fatalf("unexpected status without reviews or CI: %+v", status)
+	}
+
+	// A later verdict from the same reviewer replaces the earlier one
+	server.AddReview(number, forgetest.Review{User: "alice", State: "CHANGES_REQUESTED"})
+	server.AddReview(number, forgetest.Review{User: "alice", State: "APPROVED"})
+	server.AddReview(number, forgetest.Review{User: "bob", State: "COMMENTED"})
+	server.AddStatus("abc", "success")
+	server.AddCheckRun("abc", forgetest.CheckRun{Status: "in_progress"})
+
+	status, err = gh.GetStatus(number)
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status.ReviewDecision != ReviewApproved || status.CIStatus != CIPending {
+		t.Fatalf("expected approved with pending CI, got %+v", status)
+	}
+
+	server.AddReview(number, forgetest.Review{User: "bob", State: "CHANGES_REQUESTED"})
+	server.AddCheckRun("abc", forgetest.CheckRun{Status: "completed", Conclusion: "failure"})
+
+	status, err = gh.GetStatus(number)
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status.ReviewDecision != ReviewChangesRequested || status.CIStatus != CIFailure {
+		t.Fatalf("expected changes requested with failing CI, got %+v", status)
+	}
+
+	server.UpdatePull(number, func(pr *forgetest.PullRequest) { pr.State = "closed"; pr.Merged = true })
+	status, err = gh.GetStatus(number)
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status.State != StateMerged || status.ReviewDecision != "" || status.CIStatus != "" {
+		t.Fatalf("expected merged status without review or CI, got %+v", status)
+	}
+}
@@ -164,12 +164,138 @@ func (r *Repo) IsMergedInto(branch, target string) (bool, error) {
 	return false, nil
 }
 
-// IsBehind checks if branch is behind its parent (needs rebase)
-func (r *Repo) IsBehind(branch, parent string) (bool, error) {
-	// Get merge base between branch and parent
-	mergeBase, err := r.RunGitCommand("merge-base", branch, parent)
+// Ways a branch can have landed on its target, as reported by DetectMerge
+const (
+	MergedByAncestry = "merge"  // the branch tip is reachable from the target
+	MergedByPatchID  = "rebase" // every commit has a patch-id equivalent on the target
+	MergedByTree     = "squash" // merging the branch would not change the target's tree
+)
+
+// DetectMerge reports how branch was merged into target, or "" if it was not.
+// Besides ancestry it recognizes rebase merges through git cherry and squash
+// merges by checking that the branch's changes are already in target's tree.
+func (r *Repo) DetectMerge(branch, target string) (string, error) {
+	merged, err := r.IsMergedInto(branch, target)
 	if err != nil {
-		return false, fmt.Errorf("failed to get merge base: %w", err)
+		return "", err
+	}
+	if merged {
+		return MergedByAncestry, nil
+	}
+
+	// "-" marks commits with an equivalent change upstream, "+" those without
+	output, err := r.RunGitCommand("cherry", target, branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to compare patches: %w", err)
+	}
+	if output != "" && !strings.Contains("\n"+output, "\n+") {
+		return MergedByPatchID, nil
+	}
+
+	// A conflicting merge exits non-zero, which means the changes are not in target
+	tree, err := r.RunGitCommand("merge-tree", "--write-tree", target, branch)
+	if err != nil {
+		return "", nil
+	}
+	targetTree, err := r.RunGitCommand("rev-parse", target+"^{tree}")
+	if err != nil {
+		return "", fmt.Errorf("failed to read tree of %s: %w", target, err)
+	}
+	if tree == targetTree {
+		return MergedByTree, nil
+	}
+	return "", nil
+}
+
+// CheckedOutBranches returns the branches checked out in any worktree of the repository
+func (r *Repo) CheckedOutBranches() (map[string]bool, error) {
+	output, err := r.RunGitCommand("worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	branches := map[string]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		if ref, ok := strings.CutPrefix(line, "branch refs/heads/"); ok {
+			branches[ref] = true
+		}
+	}
+	return branches, nil
+}
+
+// ChangedFiles lists the tracked files with staged or unstaged changes
+func (r *Repo) ChangedFiles() ([]string, error) {
+	output, err := r.RunGitCommand("diff", "--name-only", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files: %w", err)
+	}
+	if output == "" {
+		return []string{}, nil
+	}
+	return strings.Split(output, "\n"), nil
+}
+
+// GetMergeBase returns the best common ancestor of two commits
+func (r *Repo) GetMergeBase(a, b string) (string, error) {
+	output, err := r.RunGitCommand("merge-base", a, b)
+	if err != nil {
+		return "", fmt.Errorf("failed to get merge base: %w", err)
+	}
+	return output, nil
+}
+
+// AheadBehind counts the commits branch has that upstream lacks, and the reverse
+func (r *Repo) AheadBehind(branch, upstream string) (ahead, behind int, err error) {
+	output, err := r.RunGitCommand("rev-list", "--left-right", "--count", upstream+"..."+branch)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count commits: %w", err)
+	}
+	if _, err := fmt.Sscanf(output, "%d %d", &behind, &ahead); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse commit counts %q: %w", output, err)
+	}
+	return ahead, behind, nil
+}
+
+// IsAncestor checks if ancestor is reachable from descendant
+func (r *Repo) IsAncestor(ancestor, descendant string) bool {
+	_, err := r.RunGitCommand("merge-base", "--is-ancestor", ancestor, descendant)
+	return err == nil
+}
+
+// GetRestackBase returns the commit the branch's own history starts from.
+// The recorded base (the parent tip the branch was last stacked on) is used
+// while it is still part of the branch history, so commits the parent has
+// since amended or squashed away are not replayed. Otherwise the merge-base
+// with the parent is used.
+func (r *Repo) GetRestackBase(branch, parent, recordedBase string) (string, error) {
+	parentCommit, err := r.RunGitCommand("rev-parse", parent)
+	if err != nil {
+		return "", fmt.Errorf("failed to get parent commit: %w", err)
+	}
+
+	mergeBase, err := r.GetMergeBase(branch, parent)
+	if err != nil {
+		return "", err
+	}
+
+	if recordedBase == "" || !r.IsAncestor(recordedBase, branch) {
+		return mergeBase, nil
+	}
+
+	// Branch already sits on the parent tip, which moved past the recorded base
+	if mergeBase == parentCommit && r.IsAncestor(recordedBase, parentCommit) {
+		return parentCommit, nil
+	}
+
+	return recordedBase, nil
+}
+
+// IsBehind checks if branch is behind its parent (needs rebase).
+// recordedBase is the parent commit the branch was last based on, or empty.
+func (r *Repo) IsBehind(branch, parent, recordedBase string) (bool, error) {
+	base, err := r.GetRestackBase(branch, parent, recordedBase)
+	if err != nil {
+		return false, err
 	}
 
 	// Get parent's current commit
@@ -178,8 +304,8 @@ func (r *Repo) IsBehind(branch, parent string) (bool, error) {
 		return false, fmt.Errorf("failed to get parent commit: %w", err)
 	}
 
-	// If merge base != parent commit, branch is behind
-	return mergeBase != parentCommit, nil
+	// If the branch does not start at the parent commit, branch is behind
+	return base != parentCommit, nil
 }
 
 // Rebase rebases a branch onto another
@@ -188,6 +314,12 @@ func (r *Repo) Rebase(branch, onto string) error {
 	return err
 }
 
+// RebaseOnto replays the commits between base and branch onto newParent
+func (r *Repo) RebaseOnto(branch, newParent, base string) error {
+	_, err := r.RunGitCommand("rebase", "--onto", newParent, base, branch)
+	return err
+}
+
 // AbortRebase aborts an in-progress rebase
 func (r *Repo) AbortRebase() error {
 	_, err := r.RunGitCommand("rebase", "--abort")
@@ -102,7 +102,7 @@ func TestBranchErrorPaths(t *testing.T) {
 	}
 
 	// IsBehind error on missing parent
-	if _, err := repo.IsBehind("main", "missing"); err == nil {
+	if _, err := repo.IsBehind("main", "missing", ""); err == nil {
 		t.Fatalf("expected is-behind error")
 	}
 
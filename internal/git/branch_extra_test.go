@@ -136,7 +136,7 @@ func TestRemoteOperations(t *testing.T) {
 		t.Fatalf("ResetToRemote failed: %v", err)
 	}
 
-	behind, err := repo.IsBehind("main", "origin/main")
+	behind, err := repo.IsBehind("main", "origin/main", "")
 	if err != nil {
 		t.Fatalf("IsBehind failed: %v", err)
 	}
@@ -166,7 +166,7 @@ func TestRemoteOperations(t *testing.T) {
 	if _, err := repo.RunGitCommand("commit", "--allow-empty", "-m", "parent moves"); err != nil {
 		t.Fatalf("failed to commit on main: %v", err)
 	}
-	behind, err = repo.IsBehind("feat-behind", "main")
+	behind, err = repo.IsBehind("feat-behind", "main", "")
 	if err != nil {
 		t.Fatalf("IsBehind failed: %v", err)
 	}
@@ -195,3 +195,213 @@ func TestRemoteOperations(t *testing.T) {
 		t.Fatalf("expected feat merged into main, got %v (%v)", merged, err)
 	}
 }
+
+func TestRebaseOntoRecordedBaseAfterParentAmend(t *testing.T) {
+	tmpDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repo, err := NewRepo()
+	if err != nil {
+		t.Fatalf("NewRepo failed: %v", err)
+	}
+
+	commitFile := func(name, contents, message string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if _, err := repo.RunGitCommand("add", name); err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+		if _, err := repo.RunGitCommand("commit", "-m", message); err != nil {
+			t.Fatalf("failed to commit %s: %v", name, err)
+		}
+	}
+
+	if _, err := repo.RunGitCommand("checkout", "-b", "parent"); err != nil {
+		t.Fatalf("failed to create parent: %v", err)
+	}
+	commitFile("parent.txt", "v1", "parent commit")
+	recordedBase, err := repo.GetBranchCommit("parent")
+	if err != nil {
+		t.Fatalf("failed to get parent commit: %v", err)
+	}
+
+	if _, err := repo.RunGitCommand("checkout", "-b", "child"); err != nil {
+		t.Fatalf("failed to create child: %v", err)
+	}
+	commitFile("child.txt", "child", "child commit")
+
+	// Amend the parent so the child's base no longer exists on it
+	if err := repo.CheckoutBranch("parent"); err != nil {
+		t.Fatalf("failed to checkout parent: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "parent.txt"), []byte("v2"), 0644); err != nil {
+		t.Fatalf("failed to write parent.txt: %v", err)
+	}
+	if _, err := repo.RunGitCommand("commit", "-a", "--amend", "--no-edit"); err != nil {
+		t.Fatalf("failed to amend parent: %v", err)
+	}
+
+	behind, err := repo.IsBehind("child", "parent", recordedBase)
+	if err != nil {
+		t.Fatalf("IsBehind failed: %v", err)
+	}
+	if !behind {
+		t.Fatalf("expected child to be behind amended parent")
+	}
+
+	base, err := repo.GetRestackBase("child", "parent", recordedBase)
+	if err != nil {
+		t.Fatalf("GetRestackBase failed: %v", err)
+	}
+	if base != recordedBase {
+		t.Fatalf("expected recorded base %s, got %s", recordedBase, base)
+	}
+
+	if err := repo.RebaseOnto("child", "parent", base); err != nil {
+		t.Fatalf("RebaseOnto failed: %v", err)
+	}
+
+	count, err := repo.RunGitCommand("rev-list", "--count", "parent..child")
+	if err != nil {
+		t.Fatalf("failed to count commits: %v", err)
+	}
+	if count != "1" {
+		t.Fatalf("expected only the child commit to be replayed, got %s commits", count)
+	}
+
+	behind, err = repo.IsBehind("child", "parent", recordedBase)
+	if err != nil {
+		t.Fatalf("IsBehind failed: %v", err)
+	}
+	if behind {
+		t.Fatalf("expected child to be up to date with a stale recorded base")
+	}
+}
+
+func TestDetectMerge(t *testing.T) {
+	tmpDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repo, err := NewRepo()
+	if err != nil {
+		t.Fatalf("NewRepo failed: %v", err)
+	}
+
+	commitFile := func(name, contents, message string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if _, err := repo.RunGitCommand("add", name); err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+		if _, err := repo.RunGitCommand("commit", "-m", message); err != nil {
+			t.Fatalf("failed to commit %s: %v", name, err)
+		}
+	}
+	branchFrom := func(branch, start string, files ...string) {
+		t.Helper()
+		if _, err := repo.RunGitCommand("checkout", "-b", branch, start); err != nil {
+			t.Fatalf("failed to create %s: %v", branch, err)
+		}
+		for _, name := range files {
+			commitFile(name, branch+" "+name, branch+" "+name)
+		}
+	}
+	detect := func(branch string) string {
+		t.Helper()
+		method, err := repo.DetectMerge(branch, "main")
+		if err != nil {
+			t.Fatalf("DetectMerge(%s) failed: %v", branch, err)
+		}
+		return method
+	}
+
+	branchFrom("merged", "main", "merged.txt")
+	branchFrom("rebased", "main", "r1.txt", "r2.txt")
+	branchFrom("squashed", "main", "s1.txt", "s2.txt")
+	branchFrom("open", "main", "open.txt")
+
+	if err := repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	if _, err := repo.RunGitCommand("merge", "--ff-only", "merged"); err != nil {
+		t.Fatalf("failed to merge: %v", err)
+	}
+	commitFile("trunk.txt", "trunk", "unrelated trunk work")
+	if _, err := repo.RunGitCommand("cherry-pick", "main..rebased"); err != nil {
+		t.Fatalf("failed to cherry-pick: %v", err)
+	}
+	if _, err := repo.RunGitCommand("merge", "--squash", "squashed"); err != nil {
+		t.Fatalf("failed to squash: %v", err)
+	}
+	if _, err := repo.RunGitCommand("commit", "-m", "Squashed"); err != nil {
+		t.Fatalf("failed to commit squash: %v", err)
+	}
+	commitFile("later.txt", "later", "more trunk work")
+
+	tests := map[string]string{
+		"merged":   MergedByAncestry,
+		"rebased":  MergedByPatchID,
+		"squashed": MergedByTree,
+		"open":     "",
+	}
+	for branch, want := range tests {
+		if got := detect(branch); got != want {
+			t.Errorf("DetectMerge(%s) = %q, want %q", branch, got, want)
+		}
+	}
+
+	// A child of the squashed branch still carries its own change
+	branchFrom("child", "squashed", "child.txt")
+	if got := detect("child"); got != "" {
+		t.Fatalf("expected child of a squashed branch to be unmerged, got %q", got)
+	}
+}
+
+func TestAheadBehind(t *testing.T) {
+	tmpDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repo, err := NewRepo()
+	if err != nil {
+		t.Fatalf("NewRepo failed: %v", err)
+	}
+
+	commitFile := func(name string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if _, err := repo.RunGitCommand("add", name); err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+		if _, err := repo.RunGitCommand("commit", "-m", name); err != nil {
+			t.Fatalf("failed to commit %s: %v", name, err)
+		}
+	}
+
+	if _, err := repo.RunGitCommand("checkout", "-b", "feat"); err != nil {
+		t.Fatalf("failed to create feat: %v", err)
+	}
+	commitFile("a.txt")
+	commitFile("b.txt")
+	if err := repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	commitFile("main.txt")
+
+	ahead, behind, err := repo.AheadBehind("feat", "main")
+	if err != nil {
+		t.Fatalf("AheadBehind failed: %v", err)
+	}
+	if ahead != 2 || behind != 1 {
+		t.Fatalf("expected 2 ahead and 1 behind, got %d and %d", ahead, behind)
+	}
+
+	if _, _, err := repo.AheadBehind("feat", "missing"); err == nil {
+		t.Fatalf("expected error for a missing branch")
+	}
+}
@@ -0,0 +1,96 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BranchInfo is what gw needs to know about a local branch, read for every
+// branch at once by Branches
+type BranchInfo struct {
+	Name       string
+	SHA        string
+	CommitTime time.Time
+	// IsHead is set for the branch checked out in this worktree
+	IsHead bool
+	// Upstream is the remote-tracking branch, e.g. origin/feat, or empty
+	Upstream string
+	// Ahead and Behind count commits relative to Upstream
+	Ahead  int
+	Behind int
+	// UpstreamGone is set when Upstream no longer exists
+	UpstreamGone bool
+}
+
+// branchCache holds the result of Branches until a git command that may move
+// a ref runs through the repo. Copies of a Repo share it.
+type branchCache struct {
+	mu       sync.Mutex
+	branches map[string]*BranchInfo
+}
+
+func (c *branchCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.branches = nil
+}
+
+// branchFormat separates fields with tabs, which ref names cannot contain
+const branchFormat = "%(refname)\t%(objectname)\t%(committerdate:unix)\t%(HEAD)\t%(upstream:short)\t%(upstream:track,nobracket)"
+
+// Branches returns every local branch, keyed by name, from a single
+// for-each-ref. The result is cached on the repo and read again only after a
+// command run through the repo may have changed a ref.
+func (r *Repo) Branches() (map[string]*BranchInfo, error) {
+	r.branches.mu.Lock()
+	defer r.branches.mu.Unlock()
+	if r.branches.branches != nil {
+		return r.branches.branches, nil
+	}
+
+	result, err := r.run(Invocation{Args: []string{"for-each-ref", "--format=" + branchFormat, "refs/heads"}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	branches := make(map[string]*BranchInfo)
+	for _, line := range strings.Split(result.Stdout, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 6 {
+			continue
+		}
+
+		info := &BranchInfo{
+			Name:     strings.TrimPrefix(fields[0], "refs/heads/"),
+			SHA:      fields[1],
+			IsHead:   fields[3] == "*",
+			Upstream: fields[4],
+		}
+		if seconds, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+			info.CommitTime = time.Unix(seconds, 0)
+		}
+		parseTrack(info, fields[5])
+		branches[info.Name] = info
+	}
+
+	r.branches.branches = branches
+	return branches, nil
+}
+
+// parseTrack reads "ahead 1, behind 2" or "gone" into info
+func parseTrack(info *BranchInfo, track string) {
+	if track == "gone" {
+		info.UpstreamGone = true
+		return
+	}
+	for _, part := range strings.Split(track, ", ") {
+		if count, ok := strings.CutPrefix(part, "ahead "); ok {
+			info.Ahead, _ = strconv.Atoi(count)
+		} else if count, ok := strings.CutPrefix(part, "behind "); ok {
+			info.Behind, _ = strconv.Atoi(count)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package git
+
+import (
+	"testing"
+)
+
+func TestBranches(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repo, err := NewRepo()
+	if err != nil {
+		t.Fatalf("NewRepo failed: %v", err)
+	}
+
+	mainSHA, _ := repo.GetBranchCommit("main")
+	for _, args := range [][]string{
+		{"remote", "add", "origin", "https://example.com/repo.git"},
+		{"update-ref", "refs/remotes/origin/main", mainSHA},
+		{"checkout", "-q", "-b", "feat"},
+		{"commit", "-q", "--allow-empty", "-m", "feat"},
+		{"config", "branch.feat.remote", "origin"},
+		{"config", "branch.feat.merge", "refs/heads/main"},
+		{"branch", "stale", "main"},
+		{"config", "branch.stale.remote", "origin"},
+		{"config", "branch.stale.merge", "refs/heads/stale"},
+	} {
+		if _, err := repo.RunGitCommand(args...); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+
+	branches, err := repo.Branches()
+	if err != nil {
+		t.Fatalf("Branches failed: %v", err)
+	}
+	if len(branches) != 3 {
+		t.Fatalf("expected main, feat and stale, got %v", branches)
+	}
+
+	feat := branches["feat"]
+	featSHA, _ := repo.GetBranchCommit("feat")
+	if feat.SHA != featSHA || !feat.IsHead || feat.CommitTime.IsZero() {
+		t.Fatalf("unexpected feat %+v", feat)
+	}
+	if feat.Upstream != "origin/main" || feat.Ahead != 1 || feat.Behind != 0 || feat.UpstreamGone {
+		t.Fatalf("unexpected feat upstream %+v", feat)
+	}
+	if branches["main"].IsHead || branches["main"].Upstream != "" {
+		t.Fatalf("unexpected main %+v", branches["main"])
+	}
+	if !branches["stale"].UpstreamGone {
+		t.Fatalf("expected stale's upstream to be gone, got %+v", branches["stale"])
+	}
+
+	// Cached until a ref may have moved
+	again, _ := repo.Branches()
+	if again["feat"] != feat {
+		t.Fatalf("expected the cached branches")
+	}
+	if err := repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("CheckoutBranch failed: %v", err)
+	}
+	again, _ = repo.Branches()
+	if again["feat"] == feat || again["feat"].IsHead || !again["main"].IsHead {
+		t.Fatalf("expected the branches to be read again after checkout")
+	}
+}
@@ -0,0 +1,114 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DryRunRunner passes read-only invocations on to Runner and prints every
+// other one to Out instead of running it, answering as if it succeeded with
+// no output. Commands that only write objects, or only touch a temporary
+// index, count as read-only so in-memory rewrites can still be planned.
+type DryRunRunner struct {
+	Runner Runner
+	Out    io.Writer
+}
+
+// Run runs inv if it is read-only and prints it otherwise
+func (d *DryRunRunner) Run(ctx context.Context, inv Invocation) (Result, error) {
+	if isReadOnly(inv) {
+		return d.Runner.Run(ctx, inv)
+	}
+
+	fmt.Fprintf(d.Out, "dry-run: %s\n", inv)
+	if inv.Stdin != "" {
+		for _, line := range strings.Split(strings.TrimRight(inv.Stdin, "\n"), "\n") {
+			fmt.Fprintf(d.Out, "dry-run:   %s\n", line)
+		}
+	}
+	return Result{}, nil
+}
+
+// readOnlyCommands never change refs, the index or the working tree
+var readOnlyCommands = map[string]bool{
+	"blame": true, "cat-file": true, "cherry": true, "commit-tree": true,
+	"describe": true, "diff": true, "diff-index": true, "diff-tree": true,
+	"for-each-ref": true, "hash-object": true, "log": true, "ls-files": true,
+	"ls-tree": true, "merge-base": true, "merge-tree": true, "mktree": true,
+	"name-rev": true, "rev-list": true, "rev-parse": true, "show": true,
+	"show-ref": true, "status": true, "var": true, "version": true,
+}
+
+// isReadOnly reports whether inv leaves refs, the index and the working tree alone
+func isReadOnly(inv Invocation) bool {
+	command, args := splitSubcommand(inv.Args)
+	if readOnlyCommands[command] {
+		return true
+	}
+
+	switch command {
+	case "branch":
+		// Listing, unless a branch name is given to create or rename
+		for _, arg := range args {
+			switch arg {
+			case "--show-current", "--list", "-l", "--contains", "--merged", "--no-merged", "--points-at":
+				return true
+			}
+		}
+		return positionalCount(args) == 0
+	case "config":
+		return hasAny(args, "--get", "--get-all", "--get-regexp", "-l", "--list")
+	case "symbolic-ref":
+		return positionalCount(args) <= 1 && !hasAny(args, "-d", "--delete")
+	case "remote":
+		return len(args) == 0 || hasAny(args, "-v", "get-url", "show")
+	case "worktree", "stash":
+		return len(args) > 0 && args[0] == "list"
+	case "read-tree", "apply", "update-index", "write-tree":
+		for _, env := range inv.Env {
+			if strings.HasPrefix(env, "GIT_INDEX_FILE=") {
+				return true
+			}
+		}
+		return command == "write-tree"
+	}
+	return false
+}
+
+// splitSubcommand skips git's own options, e.g. -c name=value, and returns the
+// subcommand and its arguments
+func splitSubcommand(args []string) (string, []string) {
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "-c" || args[i] == "-C":
+			i++
+		case strings.HasPrefix(args[i], "-"):
+		default:
+			return args[i], args[i+1:]
+		}
+	}
+	return "", nil
+}
+
+func positionalCount(args []string) int {
+	count := 0
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			count++
+		}
+	}
+	return count
+}
+
+func hasAny(args []string, options ...string) bool {
+	for _, arg := range args {
+		for _, option := range options {
+			if arg == option {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package git_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/git/gittest"
+)
+
+func TestDryRunRunner(t *testing.T) {
+	runner := gittest.NewRunner(t)
+	var out bytes.Buffer
+	dry := &git.DryRunRunner{Runner: runner, Out: &out}
+	ctx := context.Background()
+
+	// Read-only and object-only commands still run
+	passed := []git.Invocation{
+		{Args: []string{"rev-parse", "HEAD"}},
+		{Args: []string{"branch", "--show-current"}},
+		{Args: []string{"branch", "--format=%(refname:short)"}},
+		{Args: []string{"-c", "user.name=Ada", "commit-tree", "abc", "-p", "def"}},
+		{Args: []string{"config", "--get", "remote.origin.url"}},
+		{Args: []string{"worktree", "list"}},
+		{Args: []string{"apply", "--cached"}, Env: []string{"GIT_INDEX_FILE=/tmp/index"}},
+	}
+	for _, inv := range passed {
+		runner.Expect(inv.Args...).Returns("ok\n")
+		if result, err := dry.Run(ctx, inv); err != nil || result.Stdout != "ok\n" {
+			t.Errorf("expected %s to run, got %+v, %v", inv, result, err)
+		}
+	}
+
+	// Everything else is printed and answered as a success
+	held := []git.Invocation{
+		{Args: []string{"checkout", "feat"}},
+		{Args: []string{"branch", "-D", "feat"}},
+		{Args: []string{"branch", "feat"}},
+		{Args: []string{"rebase", "--onto", "main", "abc", "feat"}},
+		{Args: []string{"config", "branch.feat.remote", "origin"}},
+		{Args: []string{"apply", "--cached"}},
+		{Args: []string{"update-ref", "--stdin"}, Stdin: "start\nupdate refs/heads/feat new old\ncommit\n"},
+	}
+	for _, inv := range held {
+		if result, err := dry.Run(ctx, inv); err != nil || result.Stdout != "" {
+			t.Errorf("expected %s to be held back, got %+v, %v", inv, result, err)
+		}
+	}
+
+	got := out.String()
+	for _, want := range []string{
+		"dry-run: git checkout feat\n",
+		"dry-run: git branch -D feat\n",
+		"dry-run: git update-ref --stdin\ndry-run:   start\ndry-run:   update refs/heads/feat new old\ndry-run:   commit\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in output:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "rev-parse") {
+		t.Errorf("read-only commands should not be printed:\n%s", got)
+	}
+}
@@ -0,0 +1,116 @@
+// Package gittest provides a scripted stand-in for git, for unit tests that
+// should not create real repositories.
+package gittest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+)
+
+// Step is one expected git invocation and what it answers
+type Step struct {
+	args   []string
+	result git.Result
+	fails  bool
+}
+
+// Returns makes the step print stdout and exit successfully
+func (s *Step) Returns(stdout string) *Step {
+	s.result = git.Result{Stdout: stdout}
+	s.fails = false
+	return s
+}
+
+// Fails makes the step print stderr and exit with code
+func (s *Step) Fails(code int, stderr string) *Step {
+	s.result = git.Result{Stderr: stderr, ExitCode: code}
+	s.fails = true
+	return s
+}
+
+// Runner is a git.Runner that answers invocations from a script, in order,
+// and records every invocation it receives. An invocation the script does not
+// expect next fails the test, as does a script that is not finished when the
+// test ends.
+type Runner struct {
+	t testing.TB
+
+	mu    sync.Mutex
+	steps []*Step
+	calls []git.Invocation
+}
+
+// NewRunner returns an empty script that is checked when the test ends
+func NewRunner(t testing.TB) *Runner {
+	t.Helper()
+	r := &Runner{t: t}
+	t.Cleanup(func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for _, step := range r.steps {
+			t.Errorf("expected git %s, but it was not run", strings.Join(step.args, " "))
+		}
+	})
+	return r
+}
+
+// Expect adds an invocation of git with args to the script. It succeeds
+// without output unless Returns or Fails says otherwise.
+func (r *Runner) Expect(args ...string) *Step {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	step := &Step{args: args}
+	r.steps = append(r.steps, step)
+	return step
+}
+
+// ExpectRepo scripts the lookup git.NewRepoWithRunner makes for a
+// repository in dir
+func (r *Runner) ExpectRepo(dir string) {
+	r.Expect("rev-parse", "--git-dir", "--git-common-dir", "--show-toplevel").
+		Returns(fmt.Sprintf("%s/.git\n%s/.git\n%s\n", dir, dir, dir))
+}
+
+// Calls returns every invocation received so far
+func (r *Runner) Calls() []git.Invocation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]git.Invocation(nil), r.calls...)
+}
+
+// Run answers inv with the next step of the script
+func (r *Runner) Run(ctx context.Context, inv git.Invocation) (git.Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, inv)
+
+	if err := ctx.Err(); err != nil {
+		return git.Result{ExitCode: -1}, err
+	}
+
+	got := strings.Join(inv.Args, " ")
+	if len(r.steps) == 0 {
+		r.t.Errorf("unexpected %s", inv)
+		return git.Result{ExitCode: -1}, fmt.Errorf("unexpected %s", inv)
+	}
+	step := r.steps[0]
+	if want := strings.Join(step.args, " "); got != want {
+		r.t.Errorf("expected git %s, got %s", want, inv)
+		return git.Result{ExitCode: -1}, fmt.Errorf("unexpected %s", inv)
+	}
+	r.steps = r.steps[1:]
+
+	result := step.result
+	if inv.Combined {
+		result.Stdout, result.Stderr = result.Stdout+result.Stderr, ""
+	}
+	if step.fails {
+		return result, fmt.Errorf("exit status %d", result.ExitCode)
+	}
+	return result, nil
+}
@@ -0,0 +1,106 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// StagedDiff returns the staged changes as a patch with the given lines of context
+func (r *Repo) StagedDiff(context int) (string, error) {
+	out, _, err := r.runGit(nil, "", "diff", "--cached", "--no-color", "--no-ext-diff",
+		"--src-prefix=a/", "--dst-prefix=b/", fmt.Sprintf("-U%d", context))
+	if err != nil {
+		return "", fmt.Errorf("failed to read staged changes: %w", err)
+	}
+	return out, nil
+}
+
+// BlameFile returns the commit that last changed each line of file at rev,
+// with line 1 at index 0
+func (r *Repo) BlameFile(rev, file string) ([]string, error) {
+	out, _, err := r.runGit(nil, "", "blame", "--porcelain", rev, "--", file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to blame %s: %w", file, err)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(out, "\n") {
+		// Each line of the file is introduced by "<sha> <orig line> <final line> [<count>]"
+		fields := strings.Fields(line)
+		if len(fields) < 3 || len(fields) > 4 || len(fields[0]) != 40 || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		final, err := strconv.Atoi(fields[2])
+		if err != nil || final < 1 {
+			continue
+		}
+		for len(lines) < final {
+			lines = append(lines, "")
+		}
+		lines[final-1] = fields[0]
+	}
+	return lines, nil
+}
+
+// ApplyToTree applies patch to the tree of commit and returns the resulting
+// tree, without touching the index or working tree
+func (r *Repo) ApplyToTree(commit, patch string) (string, error) {
+	tmpDir, err := os.MkdirTemp("", "gw-index-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(tmpDir, "index")}
+
+	if _, _, err := r.runGit(env, "", "read-tree", commit); err != nil {
+		return "", fmt.Errorf("failed to read tree of %s: %w", commit, err)
+	}
+	if _, _, err := r.runGit(env, patch, "apply", "--cached"); err != nil {
+		return "", fmt.Errorf("patch does not apply to %s: %w", commit, err)
+	}
+	tree, _, err := r.runGit(env, "", "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("failed to write tree: %w", err)
+	}
+	return strings.TrimSpace(tree), nil
+}
+
+// AmendTree creates a copy of commit with the given tree, keeping its
+// parents, author and message
+func (r *Repo) AmendTree(commit, tree string) (string, error) {
+	raw, _, err := r.runGit(nil, "", "cat-file", "commit", commit)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit %s: %w", commit, err)
+	}
+	c := parseCommit(raw)
+
+	args := []string{"commit-tree", tree}
+	for _, parent := range c.parents {
+		args = append(args, "-p", parent)
+	}
+	var env []string
+	if c.author != nil {
+		env = []string{
+			"GIT_AUTHOR_NAME=" + c.author[0],
+			"GIT_AUTHOR_EMAIL=" + c.author[1],
+			"GIT_AUTHOR_DATE=" + c.author[2],
+		}
+	}
+	created, _, err := r.runGit(env, c.message, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to amend %s: %w", commit, err)
+	}
+	return strings.TrimSpace(created), nil
+}
+
+// CommitTree creates a commit with the given tree on top of parent
+func (r *Repo) CommitTree(tree, parent, message string) (string, error) {
+	created, _, err := r.runGit(nil, message, "commit-tree", tree, "-p", parent)
+	if err != nil {
+		return "", fmt.Errorf("failed to create commit: %w", err)
+	}
+	return strings.TrimSpace(created), nil
+}
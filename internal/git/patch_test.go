@@ -0,0 +1,94 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPatchHelpers(t *testing.T) {
+	tmpDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repo, err := NewRepo()
+	if err != nil {
+		t.Fatalf("NewRepo failed: %v", err)
+	}
+
+	writeFile := func(contents string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(tmpDir, "f.txt"), []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write f.txt: %v", err)
+		}
+		if _, err := repo.RunGitCommand("add", "f.txt"); err != nil {
+			t.Fatalf("failed to add f.txt: %v", err)
+		}
+	}
+
+	writeFile("one\nmid\ntwo\n")
+	if _, err := repo.RunGitCommand("commit", "-m", "Add f"); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	first, _ := repo.GetBranchCommit("main")
+	writeFile("one\nmid\nTWO\n")
+	if _, err := repo.RunGitCommand("-c", "user.name=Ada", "-c", "user.email=ada@example.com",
+		"commit", "-m", "Shout two\n\nWith a body."); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	second, _ := repo.GetBranchCommit("main")
+
+	blame, err := repo.BlameFile("HEAD", "f.txt")
+	if err != nil {
+		t.Fatalf("BlameFile failed: %v", err)
+	}
+	if len(blame) != 3 || blame[1] != first || blame[2] != second {
+		t.Fatalf("unexpected blame %v, want [%s %s %s]", blame, first, first, second)
+	}
+
+	writeFile("ONE\nmid\nTWO\n")
+	diff, err := repo.StagedDiff(1)
+	if err != nil {
+		t.Fatalf("StagedDiff failed: %v", err)
+	}
+	if !strings.Contains(diff, "-one\n+ONE\n") {
+		t.Fatalf("unexpected diff:\n%s", diff)
+	}
+
+	// Applying to an older commit leaves the index and HEAD alone
+	tree, err := repo.ApplyToTree(first, diff)
+	if err != nil {
+		t.Fatalf("ApplyToTree failed: %v", err)
+	}
+	if contents, _ := repo.RunGitCommand("cat-file", "-p", tree+":f.txt"); contents != "ONE\nmid\ntwo" {
+		t.Errorf("unexpected tree contents %q", contents)
+	}
+	if head, _ := repo.GetBranchCommit("main"); head != second {
+		t.Errorf("HEAD moved to %s", head)
+	}
+	if staged, _ := repo.RunGitCommand("diff", "--cached", "--name-only"); staged != "f.txt" {
+		t.Errorf("index changed, staged files: %q", staged)
+	}
+
+	if _, err := repo.ApplyToTree(first, strings.Replace(diff, "-one", "-uno", 1)); err == nil {
+		t.Errorf("expected error for a patch that does not apply")
+	}
+
+	// AmendTree keeps the author, message and parents
+	amended, err := repo.AmendTree(second, tree)
+	if err != nil {
+		t.Fatalf("AmendTree failed: %v", err)
+	}
+	info, _ := repo.RunGitCommand("log", "-1", "--format=%an|%P|%T|%B", amended)
+	if want := "Ada|" + first + "|" + tree + "|Shout two\n\nWith a body."; info != want {
+		t.Errorf("amended commit = %q, want %q", info, want)
+	}
+
+	fixup, err := repo.CommitTree(tree, second, "fixup! Shout two")
+	if err != nil {
+		t.Fatalf("CommitTree failed: %v", err)
+	}
+	if info, _ := repo.RunGitCommand("log", "-1", "--format=%P|%s", fixup); info != second+"|fixup! Shout two" {
+		t.Errorf("unexpected fixup commit %q", info)
+	}
+}
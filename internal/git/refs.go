@@ -0,0 +1,185 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RefUpdate describes a single ref change applied by UpdateRefs.
+// An empty OldSHA means the ref must not exist yet; an empty NewSHA deletes it.
+type RefUpdate struct {
+	Ref    string
+	OldSHA string
+	NewSHA string
+}
+
+// ListBranchRefs returns the commit SHA of every local branch
+func (r *Repo) ListBranchRefs() (map[string]string, error) {
+	output, err := r.RunGitCommand("for-each-ref", "--format=%(refname:short) %(objectname)", "refs/heads")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branch refs: %w", err)
+	}
+
+	refs := make(map[string]string)
+	if output == "" {
+		return refs, nil
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Fields(line)
+		if len(parts) != 2 {
+			continue
+		}
+		refs[parts[0]] = parts[1]
+	}
+	return refs, nil
+}
+
+// UpdateRefs applies all updates in a single transaction.
+// Every ref must still point at its OldSHA, otherwise nothing is changed.
+func (r *Repo) UpdateRefs(updates []RefUpdate) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	var input strings.Builder
+	input.WriteString("start\n")
+	for _, u := range updates {
+		switch {
+		case u.OldSHA == "" && u.NewSHA == "":
+			continue
+		case u.OldSHA == "":
+			fmt.Fprintf(&input, "create %s %s\n", u.Ref, u.NewSHA)
+		case u.NewSHA == "":
+			fmt.Fprintf(&input, "delete %s %s\n", u.Ref, u.OldSHA)
+		default:
+			fmt.Fprintf(&input, "update %s %s %s\n", u.Ref, u.NewSHA, u.OldSHA)
+		}
+	}
+	input.WriteString("prepare\ncommit\n")
+
+	if _, err := r.RunGitCommandWithInput(input.String(), "update-ref", "--stdin"); err != nil {
+		return fmt.Errorf("failed to update refs: %w", err)
+	}
+	return nil
+}
+
+// ListRefs returns the object SHA of every ref under prefix, keyed by full ref name
+func (r *Repo) ListRefs(prefix string) (map[string]string, error) {
+	output, err := r.RunGitCommand("for-each-ref", "--format=%(refname) %(objectname)", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs: %w", err)
+	}
+
+	refs := make(map[string]string)
+	if output == "" {
+		return refs, nil
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Fields(line)
+		if len(parts) != 2 {
+			continue
+		}
+		refs[parts[0]] = parts[1]
+	}
+	return refs, nil
+}
+
+// HashObject writes data to the object database as a blob and returns its SHA
+func (r *Repo) HashObject(data []byte) (string, error) {
+	sha, err := r.RunGitCommandWithInput(string(data), "hash-object", "-w", "--stdin")
+	if err != nil {
+		return "", fmt.Errorf("failed to write blob: %w", err)
+	}
+	return sha, nil
+}
+
+// ReadBlobs returns the contents of the given blobs, keyed by SHA
+func (r *Repo) ReadBlobs(shas []string) (map[string][]byte, error) {
+	blobs := make(map[string][]byte, len(shas))
+	if len(shas) == 0 {
+		return blobs, nil
+	}
+
+	result, err := r.run(Invocation{Args: []string{"cat-file", "--batch"}, Stdin: strings.Join(shas, "\n") + "\n"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blobs: %w", err)
+	}
+	output := []byte(result.Stdout)
+
+	// Each object is "<sha> <type> <size>\n<contents>\n"
+	for len(output) > 0 {
+		header, rest, found := bytes.Cut(output, []byte("\n"))
+		if !found {
+			return nil, fmt.Errorf("failed to read blobs: truncated output")
+		}
+
+		fields := strings.Fields(string(header))
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("failed to read blobs: %s", string(header))
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil || size+1 > len(rest) {
+			return nil, fmt.Errorf("failed to read blobs: invalid size in %q", string(header))
+		}
+
+		blobs[fields[0]] = rest[:size]
+		output = rest[size+1:]
+	}
+
+	return blobs, nil
+}
+
+// FetchRefspecs fetches the given refspecs from remote, pruning refs the remote no longer has
+func (r *Repo) FetchRefspecs(remote string, refspecs ...string) error {
+	args := append([]string{"fetch", "--prune", "--no-tags", remote}, refspecs...)
+	if _, err := r.RunGitCommand(args...); err != nil {
+		return fmt.Errorf("failed to fetch from %s: %w", remote, err)
+	}
+	return nil
+}
+
+// PushRefs pushes each update's NewSHA to its ref on remote.
+// Every remote ref must still point at OldSHA (or not exist when OldSHA is empty).
+func (r *Repo) PushRefs(remote string, updates []RefUpdate) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	args := []string{"push", "--porcelain"}
+	for _, u := range updates {
+		args = append(args, fmt.Sprintf("--force-with-lease=%s:%s", u.Ref, u.OldSHA))
+	}
+	args = append(args, remote)
+	for _, u := range updates {
+		args = append(args, fmt.Sprintf("%s:%s", u.NewSHA, u.Ref))
+	}
+
+	if _, err := r.RunGitCommand(args...); err != nil {
+		return fmt.Errorf("failed to push to %s: %w", remote, err)
+	}
+	return nil
+}
+
+// PushBranch pushes a local branch to the same name on remote and sets it as upstream.
+// The remote branch must still be at expected (or not exist when expected is empty).
+func (r *Repo) PushBranch(remote, branch, expected string) error {
+	lease := fmt.Sprintf("--force-with-lease=refs/heads/%s:%s", branch, expected)
+	refspec := fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, branch)
+	if _, err := r.RunGitCommand("push", "--porcelain", "--set-upstream", lease, remote, refspec); err != nil {
+		return fmt.Errorf("failed to push %s: %w", branch, err)
+	}
+	return nil
+}
+
+// GetRemoteURL returns the URL configured for remote
+func (r *Repo) GetRemoteURL(remote string) (string, error) {
+	url, err := r.RunGitCommand("remote", "get-url", remote)
+	if err != nil {
+		return "", fmt.Errorf("failed to get url of remote %s: %w", remote, err)
+	}
+	return url, nil
+}
@@ -0,0 +1,161 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrCannotReplay means the commits have to be rebased in the working tree,
+// e.g. because the range contains merge commits
+var ErrCannotReplay = errors.New("commits cannot be replayed in memory")
+
+// ReplayCommits replays the commits in base..branch onto onto without touching the
+// working tree, the index or any ref, and returns the new tip. Like rebase, it keeps
+// author information, drops commits whose changes are already present and keeps
+// commits that were empty to begin with. If a commit does not apply cleanly the
+// conflicting paths are returned instead of a tip.
+func (r *Repo) ReplayCommits(branch, onto, base string) (string, []string, error) {
+	merges, err := r.RunGitCommand("rev-list", "--merges", base+".."+branch)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+	if merges != "" {
+		return "", nil, ErrCannotReplay
+	}
+
+	output, err := r.RunGitCommand("rev-list", "--reverse", "--topo-order", base+".."+branch)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+
+	current, err := r.RunGitCommand("rev-parse", onto+"^{commit}")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to resolve %s: %w", onto, err)
+	}
+	if output == "" {
+		return current, nil, nil
+	}
+
+	for _, commit := range strings.Split(output, "\n") {
+		next, conflicts, err := r.replayCommit(commit, current)
+		if err != nil || len(conflicts) > 0 {
+			return "", conflicts, err
+		}
+		current = next
+	}
+
+	return current, nil, nil
+}
+
+// replayCommit applies a single commit on top of onto and returns the resulting commit
+func (r *Repo) replayCommit(commit, onto string) (string, []string, error) {
+	raw, _, err := r.runGit(nil, "", "cat-file", "commit", commit)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read commit %s: %w", commit, err)
+	}
+	c := parseCommit(raw)
+	if len(c.parents) != 1 || c.author == nil {
+		return "", nil, ErrCannotReplay
+	}
+	tree, parent := c.tree, c.parents[0]
+
+	// Already on top of onto: keep the commit as is
+	if parent == onto {
+		return commit, nil, nil
+	}
+
+	parentTree, err := r.RunGitCommand("rev-parse", parent+"^{tree}")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read tree of %s: %w", parent, err)
+	}
+	ontoTree, err := r.RunGitCommand("rev-parse", onto+"^{tree}")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read tree of %s: %w", onto, err)
+	}
+
+	// A commit that was empty to begin with stays empty
+	newTree := ontoTree
+	if parentTree != tree {
+		// A stand-in for onto whose only parent is the commit's parent makes that
+		// parent the merge base, which turns the merge into a cherry-pick
+		standIn, err := r.RunGitCommand("commit-tree", ontoTree, "-p", parent, "-m", "gw replay")
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to prepare replay of %s: %w", commit, err)
+		}
+
+		out, code, err := r.runGit(nil, "", "merge-tree", "--write-tree", "--name-only", "--no-messages", standIn, commit)
+		if code == 1 {
+			return "", conflictedPaths(out), nil
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to replay %s: %w", commit, err)
+		}
+		newTree = strings.SplitN(strings.TrimSpace(out), "\n", 2)[0]
+
+		// The changes are already in onto, so rebase would drop the commit
+		if newTree == ontoTree {
+			return onto, nil, nil
+		}
+	}
+
+	env := []string{
+		"GIT_AUTHOR_NAME=" + c.author[0],
+		"GIT_AUTHOR_EMAIL=" + c.author[1],
+		"GIT_AUTHOR_DATE=" + c.author[2],
+	}
+	created, _, err := r.runGit(env, c.message, "commit-tree", newTree, "-p", onto)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create commit for %s: %w", commit, err)
+	}
+	return strings.TrimSpace(created), nil, nil
+}
+
+// rawCommit holds the parts of a commit object that replaying needs
+type rawCommit struct {
+	tree    string
+	parents []string
+	author  []string // name, email, date
+	message string
+}
+
+// parseCommit parses the output of git cat-file commit
+func parseCommit(raw string) rawCommit {
+	var c rawCommit
+	headers, message, _ := strings.Cut(raw, "\n\n")
+	c.message = message
+
+	for _, line := range strings.Split(headers, "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "tree":
+			c.tree = value
+		case "parent":
+			c.parents = append(c.parents, value)
+		case "author":
+			open := strings.LastIndex(value, " <")
+			close := strings.LastIndex(value, "> ")
+			if open >= 0 && close > open {
+				c.author = []string{value[:open], value[open+2 : close], value[close+2:]}
+			}
+		}
+	}
+	return c
+}
+
+// conflictedPaths parses the file list printed by merge-tree --name-only after the tree
+func conflictedPaths(output string) []string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	seen := map[string]bool{}
+	paths := []string{}
+	for _, line := range lines[1:] {
+		if line == "" {
+			break
+		}
+		if !seen[line] {
+			seen[line] = true
+			paths = append(paths, line)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,102 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplayCommits(t *testing.T) {
+	tmpDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repo, err := NewRepo()
+	if err != nil {
+		t.Fatalf("NewRepo failed: %v", err)
+	}
+
+	commitFile := func(name, contents, message string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if _, err := repo.RunGitCommand("add", name); err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+		if _, err := repo.RunGitCommand("commit", "-m", message); err != nil {
+			t.Fatalf("failed to commit %s: %v", name, err)
+		}
+	}
+	checkout := func(args ...string) {
+		t.Helper()
+		if _, err := repo.RunGitCommand(append([]string{"checkout"}, args...)...); err != nil {
+			t.Fatalf("checkout %v failed: %v", args, err)
+		}
+	}
+
+	base, _ := repo.GetBranchCommit("main")
+	checkout("-b", "feat")
+	if _, err := repo.RunGitCommand("commit", "--allow-empty", "-m", "empty on purpose"); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	if _, err := repo.RunGitCommand("-c", "user.name=Ada", "-c", "user.email=ada@example.com",
+		"commit", "--allow-empty", "--amend", "--reset-author", "-m", "empty on purpose"); err != nil {
+		t.Fatalf("failed to set author: %v", err)
+	}
+	commitFile("feat.txt", "feat", "Add feat\n\nWith a body.")
+	commitFile("dup.txt", "dup", "Add dup")
+	oldTip, _ := repo.GetBranchCommit("feat")
+
+	checkout("main")
+	commitFile("dup.txt", "dup", "Add dup on main")
+	commitFile("main.txt", "main", "Move main")
+
+	newTip, conflicts, err := repo.ReplayCommits("feat", "main", base)
+	if err != nil || len(conflicts) != 0 {
+		t.Fatalf("ReplayCommits failed: %v %v", err, conflicts)
+	}
+
+	// Nothing but the object database changes
+	if tip, _ := repo.GetBranchCommit("feat"); tip != oldTip {
+		t.Fatalf("expected feat ref to be untouched")
+	}
+	if current, _ := repo.GetCurrentBranch(); current != "main" {
+		t.Fatalf("expected HEAD to stay on main, got %s", current)
+	}
+
+	// The duplicate commit is dropped, the originally empty one kept
+	log, err := repo.RunGitCommand("log", "--format=%an|%s", "main.."+newTip)
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+	if log != "Test User|Add feat\nAda|empty on purpose" {
+		t.Fatalf("unexpected replayed commits:\n%s", log)
+	}
+	body, _ := repo.RunGitCommand("log", "-1", "--format=%b", newTip)
+	if body != "With a body." {
+		t.Fatalf("expected message body to be kept, got %q", body)
+	}
+	if !repo.IsAncestor("main", newTip) {
+		t.Fatalf("expected replayed commits on top of main")
+	}
+	if files, _ := repo.RunGitCommand("ls-tree", "--name-only", newTip); files != "README.md\ndup.txt\nfeat.txt\nmain.txt" {
+		t.Fatalf("unexpected tree:\n%s", files)
+	}
+
+	// Conflicting changes report the paths instead of a tip
+	checkout("-b", "clash", base)
+	commitFile("main.txt", "clash", "Clash with main")
+	tip, conflicts, err := repo.ReplayCommits("clash", "main", base)
+	if err != nil || tip != "" || len(conflicts) != 1 || conflicts[0] != "main.txt" {
+		t.Fatalf("expected conflict in main.txt, got %q %v %v", tip, conflicts, err)
+	}
+
+	// Merge commits are left to a working-tree rebase
+	if _, err := repo.RunGitCommand("merge", "--no-ff", "-m", "Merge feat", "feat"); err != nil {
+		t.Fatalf("failed to merge: %v", err)
+	}
+	if _, _, err := repo.ReplayCommits("clash", "main", base); !errors.Is(err, ErrCannotReplay) {
+		t.Fatalf("expected ErrCannotReplay, got %v", err)
+	}
+}
@@ -1,8 +1,8 @@
 package git
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
 	"path/filepath"
 	"strings"
 )
@@ -12,48 +12,54 @@ type Repo struct {
 	workDir   string
 	gitDir    string
 	commonDir string
+	runner    Runner
+	ctx       context.Context
+	branches  *branchCache
 }
 
 // NewRepo creates a new Repo instance and validates it's a git repository
 func NewRepo() (*Repo, error) {
-	// Check if we're in a git repository
-	if !IsGitRepo() {
-		return nil, fmt.Errorf("not a git repository (or any of the parent directories)")
-	}
+	return NewRepoWithRunner(DefaultRunner())
+}
 
-	repo := &Repo{}
+// NewRepoWithRunner is NewRepo with every git command run through runner
+func NewRepoWithRunner(runner Runner) (*Repo, error) {
+	repo := &Repo{runner: runner, ctx: context.Background(), branches: &branchCache{}}
 
-	// Get git directory (handles worktrees)
-	gitDir, err := exec.Command("git", "rev-parse", "--git-dir").Output()
+	// Get the git directory (per worktree), the common git directory (shared
+	// across worktrees) and the working directory
+	out, err := repo.RunGitCommand("rev-parse", "--git-dir", "--git-common-dir", "--show-toplevel")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get git directory: %w", err)
-	}
-	repo.gitDir = strings.TrimSpace(string(gitDir))
-
-	// Get common git directory (shared across worktrees)
-	commonDir, err := exec.Command("git", "rev-parse", "--git-common-dir").Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get git common directory: %w", err)
+		return nil, fmt.Errorf("not a git repository (or any of the parent directories)")
 	}
-	repo.commonDir = strings.TrimSpace(string(commonDir))
-
-	// Get working directory
-	workDir, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	dirs := strings.Split(out, "\n")
+	if len(dirs) != 3 {
+		return nil, fmt.Errorf("failed to get git directories: unexpected output %q", out)
 	}
-	repo.workDir = strings.TrimSpace(string(workDir))
+	repo.gitDir, repo.commonDir, repo.workDir = dirs[0], dirs[1], dirs[2]
 
 	return repo, nil
 }
 
 // IsGitRepo checks if the current directory is inside a git repository
 func IsGitRepo() bool {
-	cmd := exec.Command("git", "rev-parse", "--git-dir")
-	err := cmd.Run()
+	_, err := DefaultRunner().Run(context.Background(), Invocation{Args: []string{"rev-parse", "--git-dir"}})
 	return err == nil
 }
 
+// WithContext returns a copy of the repo whose git commands are cancelled
+// when ctx is done
+func (r *Repo) WithContext(ctx context.Context) *Repo {
+	copied := *r
+	copied.ctx = ctx
+	return &copied
+}
+
+// Context returns the context the repo's git commands run in
+func (r *Repo) Context() context.Context {
+	return r.ctx
+}
+
 // GetCommonDir returns the common git directory (shared across worktrees)
 func (r *Repo) GetCommonDir() string {
 	return r.commonDir
@@ -79,12 +85,71 @@ func (r *Repo) GetMetadataPath() string {
 	return filepath.Join(r.commonDir, ".gw_stack_metadata")
 }
 
+// GetOperationLogPath returns the path to gw operation log file
+func (r *Repo) GetOperationLogPath() string {
+	return filepath.Join(r.commonDir, ".gw_oplog")
+}
+
+// GetRestackStatePath returns the path to the state of an unfinished restack.
+// It lives in the worktree's git dir because rebases are per worktree.
+func (r *Repo) GetRestackStatePath() string {
+	return filepath.Join(r.gitDir, ".gw_restack_state")
+}
+
+// GetPRCachePath returns the path to the cached pull request statuses
+func (r *Repo) GetPRCachePath() string {
+	return filepath.Join(r.commonDir, ".gw_pr_cache")
+}
+
+// GetRunCachePath returns the path to the cached results of gw foreach
+func (r *Repo) GetRunCachePath() string {
+	return filepath.Join(r.commonDir, ".gw_run_cache")
+}
+
 // RunGitCommand executes a git command and returns output
 func (r *Repo) RunGitCommand(args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-	output, err := cmd.CombinedOutput()
+	result, err := r.run(Invocation{Args: args, Combined: true})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(result.Stdout), nil
+}
+
+// RunGitCommandWithInput executes a git command with the given stdin and returns output
+func (r *Repo) RunGitCommandWithInput(input string, args ...string) (string, error) {
+	result, err := r.run(Invocation{Args: args, Stdin: input, Combined: true})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(result.Stdout), nil
+}
+
+// RunInteractive runs a git command on gw's own terminal, so pagers and
+// editors git starts can talk to the user
+func (r *Repo) RunInteractive(args ...string) error {
+	_, err := r.run(Invocation{Args: args, Interactive: true})
+	return err
+}
+
+// runGit runs git with extra environment and stdin, returning stdout and the exit code
+func (r *Repo) runGit(env []string, input string, args ...string) (string, int, error) {
+	result, err := r.run(Invocation{Args: args, Env: env, Stdin: input})
+	return result.Stdout, result.ExitCode, err
+}
+
+// run passes inv to the repo's runner, adding what git printed to any error.
+// Anything that may move a ref drops the cached branches.
+func (r *Repo) run(inv Invocation) (Result, error) {
+	result, err := r.runner.Run(r.ctx, inv)
+	if !isReadOnly(inv) {
+		r.branches.invalidate()
+	}
 	if err != nil {
-		return "", fmt.Errorf("git %s failed: %w\n%s", strings.Join(args, " "), err, string(output))
+		output := result.Stderr
+		if inv.Combined {
+			output = result.Stdout
+		}
+		return result, fmt.Errorf("%s failed: %w\n%s", inv, err, output)
 	}
-	return strings.TrimSpace(string(output)), nil
+	return result, nil
 }
@@ -0,0 +1,113 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Invocation is one run of git
+type Invocation struct {
+	Args []string
+	// Env is added to the environment gw runs in
+	Env   []string
+	Stdin string
+	// Combined sends stderr to Stdout too, in the order git wrote it
+	Combined bool
+	// Interactive connects git to gw's own terminal, for pagers and editors.
+	// Nothing is captured, and Stdin and Combined are ignored.
+	Interactive bool
+}
+
+// String renders the invocation as a command line, for errors and traces
+func (inv Invocation) String() string {
+	return "git " + strings.Join(inv.Args, " ")
+}
+
+// Result is what git printed and how it exited
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// Runner runs git for a Repo. It returns an error when git exits non-zero
+// or cannot start, along with whatever git printed; ExitCode is -1 when git
+// did not run at all.
+type Runner interface {
+	Run(ctx context.Context, inv Invocation) (Result, error)
+}
+
+// ExecRunner runs the git binary on the PATH
+type ExecRunner struct{}
+
+// Run runs git, killing it if ctx is done first
+func (ExecRunner) Run(ctx context.Context, inv Invocation) (Result, error) {
+	cmd := exec.CommandContext(ctx, "git", inv.Args...)
+	if len(inv.Env) > 0 {
+		cmd.Env = append(os.Environ(), inv.Env...)
+	}
+	var stdout, stderr bytes.Buffer
+	if inv.Interactive {
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	} else {
+		if inv.Stdin != "" {
+			cmd.Stdin = strings.NewReader(inv.Stdin)
+		}
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if inv.Combined {
+			cmd.Stderr = &stdout
+		}
+	}
+
+	err := cmd.Run()
+	result := Result{Stdout: stdout.String(), Stderr: stderr.String()}
+	if err != nil {
+		result.ExitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
+	}
+	return result, err
+}
+
+// TraceRunner prints every invocation to Out with how long it took, then
+// passes it on to Runner
+type TraceRunner struct {
+	Runner Runner
+	Out    io.Writer
+}
+
+// Run runs inv through the wrapped runner and traces it
+func (t *TraceRunner) Run(ctx context.Context, inv Invocation) (Result, error) {
+	start := time.Now()
+	result, err := t.Runner.Run(ctx, inv)
+	elapsed := time.Since(start).Round(10 * time.Microsecond)
+
+	status := ""
+	if err != nil {
+		status = fmt.Sprintf(" (exit %d)", result.ExitCode)
+	}
+	fmt.Fprintf(t.Out, "trace: %s  %s%s\n", inv, elapsed, status)
+	return result, err
+}
+
+// DefaultRunner returns the runner NewRepo uses: git on the PATH, traced to
+// stderr when GW_TRACE=1
+func DefaultRunner() Runner {
+	if os.Getenv("GW_TRACE") == "1" {
+		return &TraceRunner{Runner: ExecRunner{}, Out: os.Stderr}
+	}
+	return ExecRunner{}
+}
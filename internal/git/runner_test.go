@@ -0,0 +1,209 @@
+package git_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/git/gittest"
+)
+
+func TestNewRepoWithRunner(t *testing.T) {
+	runner := gittest.NewRunner(t)
+	runner.ExpectRepo("/work")
+	runner.Expect("branch", "--show-current").Returns("feat\n")
+	runner.Expect("branch", "--show-current").Fails(128, "fatal: not a branch\n")
+
+	repo, err := git.NewRepoWithRunner(runner)
+	if err != nil {
+		t.Fatalf("NewRepoWithRunner failed: %v", err)
+	}
+	if repo.GetWorkDir() != "/work" || repo.GetCommonDir() != "/work/.git" {
+		t.Fatalf("unexpected directories %s and %s", repo.GetWorkDir(), repo.GetCommonDir())
+	}
+
+	if branch, err := repo.GetCurrentBranch(); err != nil || branch != "feat" {
+		t.Fatalf("GetCurrentBranch = %q, %v; want feat", branch, err)
+	}
+	_, err = repo.GetCurrentBranch()
+	if err == nil || !strings.Contains(err.Error(), "git branch --show-current failed") || !strings.Contains(err.Error(), "fatal: not a branch") {
+		t.Fatalf("expected the failed command and its output in the error, got %v", err)
+	}
+
+	if calls := runner.Calls(); len(calls) != 3 || !calls[0].Combined {
+		t.Fatalf("unexpected calls %+v", calls)
+	}
+}
+
+func TestNewRepoWithRunnerOutsideRepo(t *testing.T) {
+	runner := gittest.NewRunner(t)
+	runner.Expect("rev-parse", "--git-dir", "--git-common-dir", "--show-toplevel").Fails(128, "fatal: not a git repository")
+
+	if _, err := git.NewRepoWithRunner(runner); err == nil || !strings.Contains(err.Error(), "not a git repository") {
+		t.Fatalf("expected not a git repository, got %v", err)
+	}
+}
+
+func TestGetRestackBaseScripted(t *testing.T) {
+	runner := gittest.NewRunner(t)
+	runner.ExpectRepo("/work")
+	repo, err := git.NewRepoWithRunner(runner)
+	if err != nil {
+		t.Fatalf("NewRepoWithRunner failed: %v", err)
+	}
+
+	// The recorded base was amended away, so the merge-base is used
+	runner.Expect("rev-parse", "main").Returns("p2\n")
+	runner.Expect("merge-base", "feat", "main").Returns("p1\n")
+	runner.Expect("merge-base", "--is-ancestor", "old", "feat").Fails(1, "")
+	if base, err := repo.GetRestackBase("feat", "main", "old"); err != nil || base != "p1" {
+		t.Fatalf("GetRestackBase = %q, %v; want p1", base, err)
+	}
+
+	// The branch sits on the parent tip, which moved past the recorded base
+	runner.Expect("rev-parse", "main").Returns("p2\n")
+	runner.Expect("merge-base", "feat", "main").Returns("p2\n")
+	runner.Expect("merge-base", "--is-ancestor", "p1", "feat")
+	runner.Expect("merge-base", "--is-ancestor", "p1", "p2")
+	if base, err := repo.GetRestackBase("feat", "main", "p1"); err != nil || base != "p2" {
+		t.Fatalf("GetRestackBase = %q, %v; want p2", base, err)
+	}
+}
+
+func TestTraceRunner(t *testing.T) {
+	runner := gittest.NewRunner(t)
+	runner.Expect("rev-parse", "HEAD").Returns("abc\n")
+	runner.Expect("rev-parse", "nope").Fails(128, "fatal: bad revision")
+
+	var out bytes.Buffer
+	trace := &git.TraceRunner{Runner: runner, Out: &out}
+	ctx := context.Background()
+
+	if result, err := trace.Run(ctx, git.Invocation{Args: []string{"rev-parse", "HEAD"}}); err != nil || result.Stdout != "abc\n" {
+		t.Fatalf("Run = %+v, %v", result, err)
+	}
+	if _, err := trace.Run(ctx, git.Invocation{Args: []string{"rev-parse", "nope"}}); err == nil {
+		t.Fatalf("expected the failure to be passed on")
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "trace: git rev-parse HEAD  ") ||
+		!strings.HasPrefix(lines[1], "trace: git rev-parse nope  ") || !strings.HasSuffix(lines[1], " (exit 128)") {
+		t.Fatalf("unexpected trace:\n%s", out.String())
+	}
+}
+
+func TestDefaultRunner(t *testing.T) {
+	t.Setenv("GW_TRACE", "")
+	if _, ok := git.DefaultRunner().(git.ExecRunner); !ok {
+		t.Fatalf("expected ExecRunner without GW_TRACE")
+	}
+	t.Setenv("GW_TRACE", "1")
+	if _, ok := git.DefaultRunner().(*git.TraceRunner); !ok {
+		t.Fatalf("expected TraceRunner with GW_TRACE=1")
+	}
+}
+
+func TestExecRunner(t *testing.T) {
+	var runner git.ExecRunner
+
+	result, err := runner.Run(context.Background(), git.Invocation{Args: []string{"hash-object", "--stdin"}, Stdin: "hello\n"})
+	if err != nil || strings.TrimSpace(result.Stdout) != "ce013625030ba8dba906f756967f9e9ca394464a" {
+		t.Fatalf("Run = %+v, %v", result, err)
+	}
+
+	result, err = runner.Run(context.Background(), git.Invocation{Args: []string{"no-such-command"}, Combined: true})
+	if err == nil || result.ExitCode != 1 || !strings.Contains(result.Stdout, "no-such-command") {
+		t.Fatalf("expected exit 1 with the message in Stdout, got %+v, %v", result, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if result, err := runner.Run(ctx, git.Invocation{Args: []string{"version"}}); !errors.Is(err, context.Canceled) || result.ExitCode != -1 {
+		t.Fatalf("expected a cancelled run, got %+v, %v", result, err)
+	}
+}
+
+func TestRunInteractive(t *testing.T) {
+	runner := gittest.NewRunner(t)
+	runner.ExpectRepo("/work")
+	runner.Expect("diff", "main...feat")
+	runner.Expect("diff", "main...gone").Fails(128, "")
+	repo, err := git.NewRepoWithRunner(runner)
+	if err != nil {
+		t.Fatalf("NewRepoWithRunner failed: %v", err)
+	}
+
+	if err := repo.RunInteractive("diff", "main...feat"); err != nil {
+		t.Fatalf("RunInteractive failed: %v", err)
+	}
+	if err := repo.RunInteractive("diff", "main...gone"); err == nil {
+		t.Fatalf("expected the failed command to be reported")
+	}
+	if calls := runner.Calls(); len(calls) != 3 || !calls[1].Interactive || calls[1].Combined {
+		t.Fatalf("unexpected calls %+v", calls)
+	}
+}
+
+func TestRepoWithContext(t *testing.T) {
+	runner := gittest.NewRunner(t)
+	runner.ExpectRepo("/work")
+	runner.Expect("rev-parse", "HEAD").Returns("abc\n")
+	repo, err := git.NewRepoWithRunner(runner)
+	if err != nil {
+		t.Fatalf("NewRepoWithRunner failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cancelled := repo.WithContext(ctx)
+	if cancelled.Context() != ctx {
+		t.Fatalf("expected the new context")
+	}
+	if _, err := cancelled.GetBranchCommit("HEAD"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// The original repo is not affected
+	if sha, err := repo.GetBranchCommit("HEAD"); err != nil || sha != "abc" {
+		t.Fatalf("GetBranchCommit = %q, %v; want abc", sha, err)
+	}
+}
+
+func TestBranchesCached(t *testing.T) {
+	runner := gittest.NewRunner(t)
+	runner.ExpectRepo("/work")
+	repo, err := git.NewRepoWithRunner(runner)
+	if err != nil {
+		t.Fatalf("NewRepoWithRunner failed: %v", err)
+	}
+
+	listing := "refs/heads/main\tabc\t1700000000\t*\t\t\n"
+	format := "--format=%(refname)\t%(objectname)\t%(committerdate:unix)\t%(HEAD)\t%(upstream:short)\t%(upstream:track,nobracket)"
+	runner.Expect("for-each-ref", format, "refs/heads").Returns(listing)
+	runner.Expect("rev-parse", "main").Returns("abc\n")
+	runner.Expect("checkout", "main")
+	runner.Expect("for-each-ref", format, "refs/heads").Returns(listing)
+
+	// Read-only commands and copies of the repo keep the cache
+	for i := 0; i < 2; i++ {
+		if branches, err := repo.Branches(); err != nil || branches["main"].SHA != "abc" {
+			t.Fatalf("Branches = %v, %v", branches, err)
+		}
+	}
+	_, _ = repo.GetBranchCommit("main")
+	if _, err := repo.WithContext(context.Background()).Branches(); err != nil {
+		t.Fatalf("Branches failed: %v", err)
+	}
+
+	// Anything else reads them again
+	if err := repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("CheckoutBranch failed: %v", err)
+	}
+	if _, err := repo.Branches(); err != nil {
+		t.Fatalf("Branches failed: %v", err)
+	}
+}
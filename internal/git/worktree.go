@@ -0,0 +1,41 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// GetWorktreesDir returns the directory gw creates temporary worktrees in. It is
+// inside the common git directory, so it is the same from every worktree.
+func (r *Repo) GetWorktreesDir() (string, error) {
+	dir, err := filepath.Abs(filepath.Join(r.GetCommonDir(), "gw-worktrees"))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve worktrees directory: %w", err)
+	}
+	return dir, nil
+}
+
+// AddWorktree checks commit out in a new worktree at path with a detached HEAD,
+// so branches checked out elsewhere can be used too
+func (r *Repo) AddWorktree(path, commit string) error {
+	if _, _, err := r.runGit(nil, "", "worktree", "add", "--detach", path, commit); err != nil {
+		return fmt.Errorf("failed to create worktree for %s: %w", commit, err)
+	}
+	return nil
+}
+
+// RemoveWorktree deletes the worktree at path along with any changes in it
+func (r *Repo) RemoveWorktree(path string) error {
+	if _, _, err := r.runGit(nil, "", "worktree", "remove", "--force", path); err != nil {
+		return fmt.Errorf("failed to remove worktree %s: %w", path, err)
+	}
+	return nil
+}
+
+// PruneWorktrees forgets worktrees whose directories no longer exist
+func (r *Repo) PruneWorktrees() error {
+	if _, _, err := r.runGit(nil, "", "worktree", "prune"); err != nil {
+		return fmt.Errorf("failed to prune worktrees: %w", err)
+	}
+	return nil
+}
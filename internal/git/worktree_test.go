@@ -0,0 +1,55 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWorktrees(t *testing.T) {
+	_, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repo, err := NewRepo()
+	if err != nil {
+		t.Fatalf("NewRepo failed: %v", err)
+	}
+
+	dir, err := repo.GetWorktreesDir()
+	if err != nil {
+		t.Fatalf("GetWorktreesDir failed: %v", err)
+	}
+	if !filepath.IsAbs(dir) || filepath.Base(filepath.Dir(dir)) != ".git" {
+		t.Fatalf("expected an absolute path in .git, got %s", dir)
+	}
+
+	// The current branch can be checked out again, detached
+	path := filepath.Join(t.TempDir(), "wt")
+	if err := repo.AddWorktree(path, "main"); err != nil {
+		t.Fatalf("AddWorktree failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "README.md")); err != nil {
+		t.Fatalf("expected README.md in the worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "README.md"), []byte("changed"), 0644); err != nil {
+		t.Fatalf("failed to write README.md: %v", err)
+	}
+
+	if err := repo.RemoveWorktree(path); err != nil {
+		t.Fatalf("RemoveWorktree failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected the worktree to be removed, got %v", err)
+	}
+	if err := repo.PruneWorktrees(); err != nil {
+		t.Fatalf("PruneWorktrees failed: %v", err)
+	}
+	if list, _ := repo.RunGitCommand("worktree", "list"); strings.Count(list, "\n") != 0 {
+		t.Fatalf("expected only the main worktree, got:\n%s", list)
+	}
+
+	if err := repo.AddWorktree(path, "no-such-branch"); err == nil {
+		t.Fatalf("expected AddWorktree to fail for a missing commit")
+	}
+}
@@ -0,0 +1,232 @@
+// Package metasync exchanges stack metadata with a remote through refs/gw/meta/*.
+package metasync
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+)
+
+// Strategies for resolving a branch whose parent changed both locally and on the remote
+const (
+	StrategyOurs   = "ours"
+	StrategyTheirs = "theirs"
+)
+
+// IsValidStrategy reports whether strategy names a known merge strategy
+func IsValidStrategy(strategy string) bool {
+	return strategy == StrategyOurs || strategy == StrategyTheirs
+}
+
+// TrackingPrefix returns the local ref namespace holding a remote's metadata
+func TrackingPrefix(remote string) string {
+	return "refs/gw/remote/" + remote + "/meta/"
+}
+
+// Change describes how merging remote metadata affected a branch
+type Change struct {
+	Branch       string
+	LocalParent  string // parent before the merge, empty if the branch was untracked
+	RemoteParent string
+	Conflict     bool // both sides changed the parent since the last fetch
+	Applied      bool // the remote record was adopted
+}
+
+// Merge folds remote branch records into local metadata and returns what changed.
+// base holds the remote records as of the previous fetch, which tells local edits
+// apart from remote ones. Only branches accepted by exists are merged.
+func Merge(local *config.Metadata, base, remote map[string]*config.BranchMetadata, strategy string, exists func(string) bool) []Change {
+	names := make([]string, 0, len(remote))
+	for name := range remote {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	changes := []Change{}
+	for _, name := range names {
+		theirs := remote[name]
+		if !exists(name) || !exists(theirs.Parent) {
+			continue
+		}
+
+		ours, tracked := local.Branches[name]
+		if !tracked {
+			local.Branches[name] = copyRecord(theirs)
+			if createsCycle(local, name) {
+				delete(local.Branches, name)
+				continue
+			}
+			changes = append(changes, Change{Branch: name, RemoteParent: theirs.Parent, Applied: true})
+			continue
+		}
+
+		if ours.Parent == theirs.Parent {
+			continue
+		}
+
+		change := Change{Branch: name, LocalParent: ours.Parent, RemoteParent: theirs.Parent}
+		previous, known := base[name]
+		switch {
+		case known && previous.Parent == theirs.Parent:
+			// Only the local side changed; the next push publishes it
+			continue
+		case known && previous.Parent == ours.Parent:
+			change.Applied = true
+		default:
+			change.Conflict = true
+			change.Applied = strategy == StrategyTheirs
+		}
+
+		if change.Applied {
+			record := copyRecord(theirs)
+			record.Created = ours.Created
+			local.Branches[name] = record
+			if createsCycle(local, name) {
+				local.Branches[name] = ours
+				change.Applied = false
+				change.Conflict = true
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// Fetch downloads the remote's metadata and merges it into the local metadata
+func Fetch(repo *git.Repo, remote, strategy, trunk string) ([]Change, error) {
+	tracking := &config.RefStore{Repo: repo, Prefix: TrackingPrefix(remote)}
+
+	base, err := tracking.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	refspec := "+" + config.MetadataRefPrefix + "*:" + TrackingPrefix(remote) + "*"
+	if err := repo.FetchRefspecs(remote, refspec); err != nil {
+		return nil, err
+	}
+
+	fetched, err := tracking.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := config.LoadRepoMetadata(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load metadata: %w", err)
+	}
+
+	exists := func(branch string) bool {
+		return repo.BranchExists(branch)
+	}
+	// The trunk is never tracked, but it is a valid parent
+	changes := Merge(local, base.Branches, withoutBranch(fetched.Branches, trunk), strategy, exists)
+
+	for _, change := range changes {
+		if change.Applied {
+			if err := config.SaveRepoMetadata(repo, local); err != nil {
+				return nil, fmt.Errorf("failed to save metadata: %w", err)
+			}
+			break
+		}
+	}
+
+	return changes, nil
+}
+
+// Push publishes every local branch record that differs from the remote's copy.
+// It fails without changing anything if the remote moved since the last fetch.
+func Push(repo *git.Repo, remote string) ([]string, error) {
+	tracking := &config.RefStore{Repo: repo, Prefix: TrackingPrefix(remote)}
+	refs, blobs, err := tracking.Blobs()
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := config.LoadRepoMetadata(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load metadata: %w", err)
+	}
+
+	names := make([]string, 0, len(local.Branches))
+	for name := range local.Branches {
+		if repo.BranchExists(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	pushes := []git.RefUpdate{}
+	trackingUpdates := []git.RefUpdate{}
+	for _, name := range names {
+		data, err := config.EncodeBranchMetadata(local.Branches[name])
+		if err != nil {
+			return nil, err
+		}
+
+		trackingRef := TrackingPrefix(remote) + name
+		oldSHA := refs[trackingRef]
+		if oldSHA != "" && string(blobs[oldSHA]) == string(data) {
+			continue
+		}
+
+		sha, err := repo.HashObject(data)
+		if err != nil {
+			return nil, err
+		}
+		pushes = append(pushes, git.RefUpdate{Ref: config.MetadataRefPrefix + name, OldSHA: oldSHA, NewSHA: sha})
+		trackingUpdates = append(trackingUpdates, git.RefUpdate{Ref: trackingRef, OldSHA: oldSHA, NewSHA: sha})
+	}
+
+	if err := repo.PushRefs(remote, pushes); err != nil {
+		return nil, err
+	}
+
+	// Record what the remote now holds so the next fetch merges against it
+	if err := repo.UpdateRefs(trackingUpdates); err != nil {
+		return nil, err
+	}
+
+	pushed := make([]string, 0, len(pushes))
+	for _, u := range pushes {
+		pushed = append(pushed, u.Ref[len(config.MetadataRefPrefix):])
+	}
+	return pushed, nil
+}
+
+// copyRecord returns a copy of a branch record
+func copyRecord(record *config.BranchMetadata) *config.BranchMetadata {
+	copied := *record
+	return &copied
+}
+
+// createsCycle reports whether following parents from branch leads back to it
+func createsCycle(metadata *config.Metadata, branch string) bool {
+	seen := map[string]bool{branch: true}
+	current := branch
+	for {
+		parent, ok := metadata.GetParent(current)
+		if !ok {
+			return false
+		}
+		if seen[parent] {
+			return true
+		}
+		seen[parent] = true
+		current = parent
+	}
+}
+
+// withoutBranch returns records without the given branch
+func withoutBranch(records map[string]*config.BranchMetadata, branch string) map[string]*config.BranchMetadata {
+	filtered := make(map[string]*config.BranchMetadata, len(records))
+	for name, record := range records {
+		if name != branch {
+			filtered[name] = record
+		}
+	}
+	return filtered
+}
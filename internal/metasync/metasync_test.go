@@ -0,0 +1,107 @@
+package metasync
+
+import (
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+)
+
+func record(parent string) *config.BranchMetadata {
+	return &config.BranchMetadata{Parent: parent, Tracked: true}
+}
+
+func allExist(string) bool { return true }
+
+func TestMerge(t *testing.T) {
+	t.Run("tracks branches only known remotely", func(t *testing.T) {
+		local := &config.Metadata{Branches: map[string]*config.BranchMetadata{}}
+		remote := map[string]*config.BranchMetadata{"feat": record("main")}
+
+		changes := Merge(local, nil, remote, StrategyOurs, allExist)
+		if len(changes) != 1 || !changes[0].Applied || changes[0].LocalParent != "" {
+			t.Fatalf("unexpected changes: %+v", changes)
+		}
+		if parent, _ := local.GetParent("feat"); parent != "main" {
+			t.Fatalf("expected feat tracked on main, got %q", parent)
+		}
+	})
+
+	t.Run("skips branches missing locally", func(t *testing.T) {
+		local := &config.Metadata{Branches: map[string]*config.BranchMetadata{}}
+		remote := map[string]*config.BranchMetadata{"feat": record("gone")}
+
+		changes := Merge(local, nil, remote, StrategyTheirs, func(b string) bool { return b != "gone" })
+		if len(changes) != 0 || local.IsTracked("feat") {
+			t.Fatalf("expected branch with missing parent to be skipped: %+v", changes)
+		}
+	})
+
+	t.Run("applies remote-only parent changes", func(t *testing.T) {
+		local := &config.Metadata{Branches: map[string]*config.BranchMetadata{
+			"feat": record("main"),
+			"base": record("main"),
+		}}
+		base := map[string]*config.BranchMetadata{"feat": record("main")}
+		remote := map[string]*config.BranchMetadata{"feat": record("base")}
+
+		changes := Merge(local, base, remote, StrategyOurs, allExist)
+		if len(changes) != 1 || !changes[0].Applied || changes[0].Conflict {
+			t.Fatalf("unexpected changes: %+v", changes)
+		}
+		if parent, _ := local.GetParent("feat"); parent != "base" {
+			t.Fatalf("expected feat moved to base, got %q", parent)
+		}
+	})
+
+	t.Run("keeps local-only parent changes", func(t *testing.T) {
+		local := &config.Metadata{Branches: map[string]*config.BranchMetadata{"feat": record("base")}}
+		base := map[string]*config.BranchMetadata{"feat": record("main")}
+		remote := map[string]*config.BranchMetadata{"feat": record("main")}
+
+		if changes := Merge(local, base, remote, StrategyTheirs, allExist); len(changes) != 0 {
+			t.Fatalf("expected no changes, got %+v", changes)
+		}
+		if parent, _ := local.GetParent("feat"); parent != "base" {
+			t.Fatalf("expected local parent kept, got %q", parent)
+		}
+	})
+
+	t.Run("resolves conflicts with the strategy", func(t *testing.T) {
+		for _, tc := range []struct {
+			strategy string
+			want     string
+		}{
+			{StrategyOurs, "ours"},
+			{StrategyTheirs, "theirs"},
+		} {
+			local := &config.Metadata{Branches: map[string]*config.BranchMetadata{"feat": record("ours")}}
+			base := map[string]*config.BranchMetadata{"feat": record("main")}
+			remote := map[string]*config.BranchMetadata{"feat": record("theirs")}
+
+			changes := Merge(local, base, remote, tc.strategy, allExist)
+			if len(changes) != 1 || !changes[0].Conflict {
+				t.Fatalf("%s: expected a conflict, got %+v", tc.strategy, changes)
+			}
+			if parent, _ := local.GetParent("feat"); parent != tc.want {
+				t.Fatalf("%s: expected parent %s, got %q", tc.strategy, tc.want, parent)
+			}
+		}
+	})
+
+	t.Run("refuses parents that create a cycle", func(t *testing.T) {
+		local := &config.Metadata{Branches: map[string]*config.BranchMetadata{
+			"a": record("main"),
+			"b": record("a"),
+		}}
+		base := map[string]*config.BranchMetadata{"a": record("main")}
+		remote := map[string]*config.BranchMetadata{"a": record("b")}
+
+		changes := Merge(local, base, remote, StrategyTheirs, allExist)
+		if len(changes) != 1 || changes[0].Applied || !changes[0].Conflict {
+			t.Fatalf("expected cycle to be refused, got %+v", changes)
+		}
+		if parent, _ := local.GetParent("a"); parent != "main" {
+			t.Fatalf("expected a to stay on main, got %q", parent)
+		}
+	})
+}
@@ -0,0 +1,211 @@
+// Package oplog records mutating gw operations so they can be undone and redone.
+package oplog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/israelmalagutti/git-wrapper/internal/atomicfile"
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+)
+
+// maxEntries bounds how many operations are kept in the log
+const maxEntries = 100
+
+// Snapshot captures the branch refs, HEAD and stack metadata of a repository.
+// Backend is the metadata backend selected in the config, if one could be read.
+type Snapshot struct {
+	Head     string
+	Refs     map[string]string
+	Metadata *config.Metadata
+	Backend  string
+}
+
+// RefChange records a branch's commit before and after an operation.
+// An empty SHA means the branch did not exist at that point.
+type RefChange struct {
+	Branch string `json:"branch"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// Entry records a single mutating gw command
+type Entry struct {
+	ID             int              `json:"id"`
+	Command        string           `json:"command"`
+	Timestamp      time.Time        `json:"timestamp"`
+	HeadBefore     string           `json:"headBefore,omitempty"`
+	HeadAfter      string           `json:"headAfter,omitempty"`
+	Refs           []RefChange      `json:"refs"`
+	MetadataBefore *config.Metadata `json:"metadataBefore"`
+	MetadataAfter  *config.Metadata `json:"metadataAfter"`
+	BackendBefore  string           `json:"backendBefore,omitempty"`
+	BackendAfter   string           `json:"backendAfter,omitempty"`
+	Undone         bool             `json:"undone"`
+}
+
+// Log is the persistent list of recorded operations, oldest first
+type Log struct {
+	Entries []*Entry `json:"entries"`
+}
+
+// Load reads the operation log from the specified path
+func Load(path string) (*Log, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Log{}, nil
+		}
+		return nil, fmt.Errorf("failed to read operation log: %w", err)
+	}
+
+	var log Log
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, fmt.Errorf("failed to parse operation log: %w", err)
+	}
+
+	return &log, nil
+}
+
+// Save writes the operation log to the specified path
+func (l *Log) Save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal operation log: %w", err)
+	}
+
+	if err := atomicfile.Write(path, data); err != nil {
+		return fmt.Errorf("failed to write operation log: %w", err)
+	}
+
+	return nil
+}
+
+// Append adds an entry to the log. Entries undone before it can no longer
+// be redone, so they are dropped.
+func (l *Log) Append(entry *Entry) {
+	kept := l.Entries[:0]
+	for _, e := range l.Entries {
+		if !e.Undone {
+			kept = append(kept, e)
+		}
+	}
+	l.Entries = kept
+
+	entry.ID = 1
+	if len(l.Entries) > 0 {
+		entry.ID = l.Entries[len(l.Entries)-1].ID + 1
+	}
+	l.Entries = append(l.Entries, entry)
+
+	if len(l.Entries) > maxEntries {
+		l.Entries = l.Entries[len(l.Entries)-maxEntries:]
+	}
+}
+
+// LastApplied returns the most recent entry that has not been undone
+func (l *Log) LastApplied() *Entry {
+	for i := len(l.Entries) - 1; i >= 0; i-- {
+		if !l.Entries[i].Undone {
+			return l.Entries[i]
+		}
+	}
+	return nil
+}
+
+// NextRedo returns the oldest undone entry, which is the next one to redo
+func (l *Log) NextRedo() *Entry {
+	for _, e := range l.Entries {
+		if e.Undone {
+			return e
+		}
+	}
+	return nil
+}
+
+// TakeSnapshot captures the current branch refs, HEAD and metadata
+func TakeSnapshot(repo *git.Repo) (*Snapshot, error) {
+	refs, err := repo.ListBranchRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := config.LoadRepoMetadata(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	head, _ := repo.GetCurrentBranch()
+
+	backend := ""
+	if cfg, err := config.Load(repo.GetConfigPath()); err == nil {
+		backend = cfg.GetMetadataBackend()
+	}
+
+	return &Snapshot{
+		Head:     head,
+		Refs:     refs,
+		Metadata: metadata,
+		Backend:  backend,
+	}, nil
+}
+
+// NewEntry builds an entry from the snapshots taken around a command.
+// It returns nil when the command changed neither refs, metadata nor backend.
+func NewEntry(command string, before, after *Snapshot) *Entry {
+	var changes []RefChange
+
+	for branch, sha := range before.Refs {
+		if after.Refs[branch] != sha {
+			changes = append(changes, RefChange{Branch: branch, Before: sha, After: after.Refs[branch]})
+		}
+	}
+	for branch, sha := range after.Refs {
+		if _, existed := before.Refs[branch]; !existed {
+			changes = append(changes, RefChange{Branch: branch, After: sha})
+		}
+	}
+
+	if len(changes) == 0 && metadataEqual(before.Metadata, after.Metadata) && before.Backend == after.Backend {
+		return nil
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Branch < changes[j].Branch
+	})
+
+	return &Entry{
+		Command:        command,
+		Timestamp:      time.Now(),
+		HeadBefore:     before.Head,
+		HeadAfter:      after.Head,
+		Refs:           changes,
+		MetadataBefore: before.Metadata,
+		MetadataAfter:  after.Metadata,
+		BackendBefore:  before.Backend,
+		BackendAfter:   after.Backend,
+	}
+}
+
+// Branches returns the names of the branches the entry touched
+func (e *Entry) Branches() []string {
+	branches := make([]string, 0, len(e.Refs))
+	for _, change := range e.Refs {
+		branches = append(branches, change.Branch)
+	}
+	return branches
+}
+
+// metadataEqual compares two metadata snapshots by their serialized form
+func metadataEqual(a, b *config.Metadata) bool {
+	aData, aErr := json.Marshal(a)
+	bData, bErr := json.Marshal(b)
+	if aErr != nil || bErr != nil {
+		return false
+	}
+	return string(aData) == string(bData)
+}
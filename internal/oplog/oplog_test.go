@@ -0,0 +1,207 @@
+package oplog
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+)
+
+func setupOplogRepo(t *testing.T) (*git.Repo, func()) {
+	t.Helper()
+
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get cwd: %v", err)
+	}
+
+	dir := t.TempDir()
+	cmds := [][]string{
+		{"git", "init", dir},
+		{"git", "-C", dir, "config", "user.email", "test@test.com"},
+		{"git", "-C", dir, "config", "user.name", "Test User"},
+		{"git", "-C", dir, "config", "commit.gpgsign", "false"},
+		{"git", "-C", dir, "commit", "--allow-empty", "-m", "Initial"},
+		{"git", "-C", dir, "branch", "-M", "main"},
+	}
+	for _, args := range cmds {
+		if err := exec.Command(args[0], args[1:]...).Run(); err != nil {
+			t.Fatalf("failed to run %v: %v", args, err)
+		}
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("failed to chdir: %v", err)
+	}
+
+	repo, err := git.NewRepo()
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+
+	metadata := &config.Metadata{Branches: map[string]*config.BranchMetadata{}}
+	if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+		t.Fatalf("failed to save metadata: %v", err)
+	}
+
+	return repo, func() {
+		if err := os.Chdir(origDir); err != nil {
+			t.Errorf("failed to restore cwd: %v", err)
+		}
+	}
+}
+
+func TestLogAppendDropsUndoneEntries(t *testing.T) {
+	log := &Log{}
+	log.Append(&Entry{Command: "create"})
+	log.Append(&Entry{Command: "modify"})
+
+	if got := log.LastApplied(); got == nil || got.Command != "modify" {
+		t.Fatalf("expected modify as last applied, got %+v", got)
+	}
+	if log.NextRedo() != nil {
+		t.Fatalf("expected nothing to redo")
+	}
+
+	log.Entries[1].Undone = true
+	if got := log.NextRedo(); got == nil || got.Command != "modify" {
+		t.Fatalf("expected modify to redo, got %+v", got)
+	}
+
+	log.Append(&Entry{Command: "move"})
+	if len(log.Entries) != 2 {
+		t.Fatalf("expected undone entry to be dropped, got %d entries", len(log.Entries))
+	}
+	if log.Entries[1].Command != "move" || log.Entries[1].ID != 2 {
+		t.Fatalf("unexpected last entry: %+v", log.Entries[1])
+	}
+}
+
+func TestLogAppendTrimsToMaxEntries(t *testing.T) {
+	log := &Log{}
+	for i := 0; i < maxEntries+5; i++ {
+		log.Append(&Entry{Command: "commit"})
+	}
+	if len(log.Entries) != maxEntries {
+		t.Fatalf("expected %d entries, got %d", maxEntries, len(log.Entries))
+	}
+	if log.Entries[len(log.Entries)-1].ID != maxEntries+5 {
+		t.Fatalf("expected ids to keep increasing")
+	}
+}
+
+func TestLogSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "oplog")
+
+	empty, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load missing failed: %v", err)
+	}
+	if len(empty.Entries) != 0 {
+		t.Fatalf("expected empty log")
+	}
+
+	log := &Log{}
+	log.Append(&Entry{Command: "fold", Refs: []RefChange{{Branch: "feat", Before: "abc"}}})
+	if err := log.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded.Entries) != 1 || loaded.Entries[0].Refs[0].Before != "abc" {
+		t.Fatalf("unexpected loaded log: %+v", loaded.Entries)
+	}
+
+	if err := os.WriteFile(path, []byte("{bad"), 0600); err != nil {
+		t.Fatalf("failed to write bad log: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatalf("expected error for invalid log")
+	}
+}
+
+func TestUndoRedoRestoresRefsAndMetadata(t *testing.T) {
+	repo, cleanup := setupOplogRepo(t)
+	defer cleanup()
+
+	before, err := TakeSnapshot(repo)
+	if err != nil {
+		t.Fatalf("TakeSnapshot failed: %v", err)
+	}
+
+	// Simulate 'gw create feat' followed by a commit on main
+	if _, err := repo.RunGitCommand("checkout", "-b", "feat"); err != nil {
+		t.Fatalf("failed to create feat: %v", err)
+	}
+	if _, err := repo.RunGitCommand("commit", "--allow-empty", "-m", "feat"); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	metadata, err := config.LoadMetadata(repo.GetMetadataPath())
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	metadata.TrackBranch("feat", "main")
+	if err := metadata.Save(repo.GetMetadataPath()); err != nil {
+		t.Fatalf("failed to save metadata: %v", err)
+	}
+
+	after, err := TakeSnapshot(repo)
+	if err != nil {
+		t.Fatalf("TakeSnapshot failed: %v", err)
+	}
+
+	entry := NewEntry("create", before, after)
+	if entry == nil {
+		t.Fatalf("expected an entry")
+	}
+	if len(entry.Refs) != 1 || entry.Refs[0].Branch != "feat" || entry.Refs[0].Before != "" {
+		t.Fatalf("unexpected ref changes: %+v", entry.Refs)
+	}
+	if NewEntry("noop", after, after) != nil {
+		t.Fatalf("expected no entry when nothing changed")
+	}
+
+	if err := Undo(repo, entry); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if repo.BranchExists("feat") {
+		t.Fatalf("expected feat to be removed by undo")
+	}
+	if current, _ := repo.GetCurrentBranch(); current != "main" {
+		t.Fatalf("expected to be on main after undo, got %s", current)
+	}
+	restored, err := config.LoadMetadata(repo.GetMetadataPath())
+	if err != nil {
+		t.Fatalf("failed to load metadata: %v", err)
+	}
+	if restored.IsTracked("feat") {
+		t.Fatalf("expected metadata to be restored")
+	}
+
+	if err := Redo(repo, entry); err != nil {
+		t.Fatalf("Redo failed: %v", err)
+	}
+	if !repo.BranchExists("feat") {
+		t.Fatalf("expected feat to be recreated by redo")
+	}
+	if current, _ := repo.GetCurrentBranch(); current != "feat" {
+		t.Fatalf("expected to be on feat after redo, got %s", current)
+	}
+
+	// Undo refuses when the branch moved since the operation
+	if _, err := repo.RunGitCommand("commit", "--allow-empty", "-m", "later"); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	if err := Undo(repo, entry); err == nil {
+		t.Fatalf("expected undo to fail after branch moved")
+	}
+	if current, _ := repo.GetCurrentBranch(); current != "feat" {
+		t.Fatalf("expected to stay on feat after failed undo, got %s", current)
+	}
+}
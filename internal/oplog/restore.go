@@ -0,0 +1,125 @@
+package oplog
+
+import (
+	"fmt"
+
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+)
+
+// Undo restores the branch refs, HEAD, metadata and backend recorded before the entry
+func Undo(repo *git.Repo, entry *Entry) error {
+	updates := make([]git.RefUpdate, 0, len(entry.Refs))
+	for _, change := range entry.Refs {
+		updates = append(updates, git.RefUpdate{
+			Ref:    "refs/heads/" + change.Branch,
+			OldSHA: change.After,
+			NewSHA: change.Before,
+		})
+	}
+	return restore(repo, updates, entry.HeadBefore, entry.MetadataBefore, entry.BackendBefore)
+}
+
+// Redo re-applies the branch refs, HEAD, metadata and backend recorded after the entry
+func Redo(repo *git.Repo, entry *Entry) error {
+	updates := make([]git.RefUpdate, 0, len(entry.Refs))
+	for _, change := range entry.Refs {
+		updates = append(updates, git.RefUpdate{
+			Ref:    "refs/heads/" + change.Branch,
+			OldSHA: change.Before,
+			NewSHA: change.After,
+		})
+	}
+	return restore(repo, updates, entry.HeadAfter, entry.MetadataAfter, entry.BackendAfter)
+}
+
+// restore applies ref updates in one transaction, writes metadata and checks out head
+func restore(repo *git.Repo, updates []git.RefUpdate, head string, metadata *config.Metadata, backend string) error {
+	status, err := repo.RunGitCommand("status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return fmt.Errorf("failed to check working tree: %w", err)
+	}
+	if status != "" {
+		return fmt.Errorf("working tree has uncommitted changes; commit or stash them first")
+	}
+
+	current, _ := repo.GetCurrentBranch()
+
+	// Detach so the checked-out branch can be moved or deleted safely
+	if current != "" {
+		if _, err := repo.RunGitCommand("checkout", "--quiet", "--detach"); err != nil {
+			return fmt.Errorf("failed to detach HEAD: %w", err)
+		}
+	}
+
+	if err := repo.UpdateRefs(updates); err != nil {
+		if current != "" {
+			_ = repo.CheckoutBranch(current)
+		}
+		return fmt.Errorf("branches changed since this operation: %w", err)
+	}
+
+	if metadata != nil {
+		if err := saveMetadata(repo, metadata, backend); err != nil {
+			// Put the refs back so refs and metadata stay consistent
+			_ = repo.UpdateRefs(reverseUpdates(updates))
+			if current != "" {
+				_ = repo.CheckoutBranch(current)
+			}
+			return fmt.Errorf("failed to save metadata: %w", err)
+		}
+	}
+
+	target := head
+	if target == "" || !repo.BranchExists(target) {
+		target = current
+	}
+	if target != "" && repo.BranchExists(target) {
+		if err := repo.CheckoutBranch(target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveMetadata writes metadata to backend. When the config selects another backend,
+// it is switched over and the copy held by the previous backend is removed, as
+// 'gw metadata migrate' does. An empty backend keeps the configured one.
+func saveMetadata(repo *git.Repo, metadata *config.Metadata, backend string) error {
+	cfg, err := config.Load(repo.GetConfigPath())
+	if backend == "" || err != nil || cfg.GetMetadataBackend() == backend {
+		return config.SaveRepoMetadata(repo, metadata)
+	}
+
+	from, err := config.NewMetadataStore(repo, cfg.GetMetadataBackend())
+	if err != nil {
+		return err
+	}
+	to, err := config.NewMetadataStore(repo, backend)
+	if err != nil {
+		return err
+	}
+
+	if err := to.Save(metadata); err != nil {
+		return err
+	}
+	cfg.MetadataBackend = backend
+	if err := cfg.Save(repo.GetConfigPath()); err != nil {
+		_ = to.Clear()
+		return err
+	}
+
+	// A copy left in the unselected backend is never read, so it is not an error
+	_ = from.Clear()
+	return nil
+}
+
+// reverseUpdates returns the updates that undo the given ones
+func reverseUpdates(updates []git.RefUpdate) []git.RefUpdate {
+	reversed := make([]git.RefUpdate, 0, len(updates))
+	for _, u := range updates {
+		reversed = append(reversed, git.RefUpdate{Ref: u.Ref, OldSHA: u.NewSHA, NewSHA: u.OldSHA})
+	}
+	return reversed
+}
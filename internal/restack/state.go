@@ -0,0 +1,86 @@
+// Package restack persists the queue of a multi-branch restack so an operation
+// stopped by a conflict can be resumed or rolled back.
+package restack
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/israelmalagutti/git-wrapper/internal/atomicfile"
+)
+
+// Snapshot is a branch as it was before the operation touched it
+type Snapshot struct {
+	SHA       string `json:"sha"`
+	ParentSHA string `json:"parentSha,omitempty"`
+}
+
+// State is a restack in progress. Queue holds the branches still to be
+// restacked; while a conflict is pending its first entry is the branch
+// whose rebase stopped.
+type State struct {
+	Operation      string              `json:"operation"`
+	OriginalBranch string              `json:"originalBranch"`
+	Queue          []string            `json:"queue"`
+	Original       map[string]Snapshot `json:"original"`
+}
+
+// New returns an empty state for operation, started from originalBranch
+func New(operation, originalBranch string) *State {
+	return &State{
+		Operation:      operation,
+		OriginalBranch: originalBranch,
+		Queue:          []string{},
+		Original:       make(map[string]Snapshot),
+	}
+}
+
+// Remember records a branch's pre-operation state unless it is already known
+func (s *State) Remember(branch, sha, parentSHA string) {
+	if _, ok := s.Original[branch]; ok {
+		return
+	}
+	s.Original[branch] = Snapshot{SHA: sha, ParentSHA: parentSHA}
+}
+
+// Load reads the state file, returning nil if no operation is in progress
+func Load(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read restack state: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse restack state: %w", err)
+	}
+	if state.Original == nil {
+		state.Original = make(map[string]Snapshot)
+	}
+	return &state, nil
+}
+
+// Save writes the state file, replacing it atomically
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal restack state: %w", err)
+	}
+
+	if err := atomicfile.Write(path, data); err != nil {
+		return fmt.Errorf("failed to write restack state: %w", err)
+	}
+	return nil
+}
+
+// Clear removes the state file once the operation has finished or been aborted
+func Clear(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove restack state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package restack
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStateSaveLoadClear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state")
+
+	missing, err := Load(path)
+	if err != nil || missing != nil {
+		t.Fatalf("expected no state, got %+v (%v)", missing, err)
+	}
+
+	state := New("sync", "feat-a")
+	state.Queue = []string{"feat-b", "feat-c"}
+	state.Remember("feat-b", "abc", "base1")
+	state.Remember("feat-b", "def", "base2")
+	if err := state.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Operation != "sync" || loaded.OriginalBranch != "feat-a" || len(loaded.Queue) != 2 {
+		t.Fatalf("unexpected state: %+v", loaded)
+	}
+	if got := loaded.Original["feat-b"]; got.SHA != "abc" || got.ParentSHA != "base1" {
+		t.Fatalf("expected first snapshot to be kept, got %+v", got)
+	}
+
+	if err := Clear(path); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if err := Clear(path); err != nil {
+		t.Fatalf("Clear of missing state failed: %v", err)
+	}
+	if state, _ := Load(path); state != nil {
+		t.Fatalf("expected state to be cleared")
+	}
+}
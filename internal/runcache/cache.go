@@ -0,0 +1,85 @@
+// Package runcache remembers the results of commands run on branches by
+// 'gw foreach', so commits that already passed are not run again.
+package runcache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/israelmalagutti/git-wrapper/internal/atomicfile"
+)
+
+// Result is the outcome of running a command on one commit
+type Result struct {
+	ExitCode int       `json:"exitCode"`
+	Ran      time.Time `json:"ran"`
+}
+
+// Passed reports whether the command exited successfully
+func (r *Result) Passed() bool {
+	return r.ExitCode == 0
+}
+
+// Cache holds results keyed by command, as returned by Key, then by commit SHA
+type Cache struct {
+	Commands map[string]map[string]*Result `json:"commands"`
+}
+
+// Key returns the cache key of a command given as gw foreach arguments. A single
+// argument is run by the shell and several are run as argv, so the mode is part
+// of the key, and the arguments are JSON-encoded so spaces inside them count.
+func Key(args []string) string {
+	mode := "argv"
+	if len(args) == 1 {
+		mode = "sh"
+	}
+	data, _ := json.Marshal(args)
+	return mode + " " + string(data)
+}
+
+// Load reads the result cache, returning an empty cache if it does not exist
+func Load(path string) (*Cache, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Cache{Commands: make(map[string]map[string]*Result)}, nil
+		}
+		return nil, fmt.Errorf("failed to read run cache: %w", err)
+	}
+
+	var cache Cache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("failed to parse run cache: %w", err)
+	}
+	if cache.Commands == nil {
+		cache.Commands = make(map[string]map[string]*Result)
+	}
+	return &cache, nil
+}
+
+// Save writes the result cache, replacing it atomically
+func (c *Cache) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal run cache: %w", err)
+	}
+	if err := atomicfile.Write(path, data); err != nil {
+		return fmt.Errorf("failed to write run cache: %w", err)
+	}
+	return nil
+}
+
+// Get returns the result of command on commit, or nil if it has not been run
+func (c *Cache) Get(command, commit string) *Result {
+	return c.Commands[command][commit]
+}
+
+// Put records the result of command on commit
+func (c *Cache) Put(command, commit string, result *Result) {
+	if c.Commands[command] == nil {
+		c.Commands[command] = make(map[string]*Result)
+	}
+	c.Commands[command][commit] = result
+}
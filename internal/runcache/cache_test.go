@@ -0,0 +1,65 @@
+package runcache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+
+	empty, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if empty.Get("make test", "abc") != nil {
+		t.Fatalf("expected empty cache, got %+v", empty)
+	}
+
+	empty.Put("make test", "abc", &Result{ExitCode: 0, Ran: time.Now()})
+	empty.Put("make test", "def", &Result{ExitCode: 2, Ran: time.Now()})
+	if err := empty.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := loaded.Get("make test", "abc"); got == nil || !got.Passed() {
+		t.Fatalf("expected abc to have passed, got %+v", got)
+	}
+	if got := loaded.Get("make test", "def"); got == nil || got.Passed() || got.ExitCode != 2 {
+		t.Fatalf("expected def to have failed with 2, got %+v", got)
+	}
+	// Results are kept per command
+	if got := loaded.Get("make lint", "abc"); got != nil {
+		t.Fatalf("expected no result for another command, got %+v", got)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	if err := os.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatalf("failed to write cache: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatalf("expected error for an invalid cache")
+	}
+}
+
+func TestKey(t *testing.T) {
+	shell := Key([]string{"go test ./..."})
+	argv := Key([]string{"go", "test", "./..."})
+	if shell == argv {
+		t.Fatalf("expected shell and argv forms to have different keys, got %q", shell)
+	}
+	if Key([]string{"echo", "a b"}) == Key([]string{"echo", "a", "b"}) {
+		t.Fatalf("expected spaces inside arguments to change the key")
+	}
+	if Key([]string{"go", "test"}) != Key([]string{"go", "test"}) {
+		t.Fatalf("expected the same arguments to give the same key")
+	}
+}
@@ -0,0 +1,136 @@
+package stack
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+)
+
+// GraphOptions controls how the stack is exported as a graph
+type GraphOptions struct {
+	// NeedsRestack marks branches whose parent has moved since they were stacked on it
+	NeedsRestack map[string]bool
+}
+
+// graphNodes returns trunk followed by every branch, parents before children
+func (s *Stack) graphNodes() []*Node {
+	return append([]*Node{s.Trunk}, s.GetTopologicalOrder()...)
+}
+
+// graphLabel returns the lines of a node's label: its name, then its annotation
+// and restack state when present. Annotations are stripped of terminal colors.
+func (s *Stack) graphLabel(node *Node, opts GraphOptions) []string {
+	lines := []string{node.Name}
+	if annotation := colors.Strip(s.Annotations[node.Name]); annotation != "" {
+		lines = append(lines, annotation)
+	}
+	if opts.NeedsRestack[node.Name] {
+		lines = append(lines, "needs restack")
+	}
+	return lines
+}
+
+// RenderDOT renders the stack as a Graphviz digraph, with trunk at the bottom.
+// Edges point from each branch to the node it is drawn on, as in RenderTree.
+func (s *Stack) RenderDOT(opts GraphOptions) string {
+	var result strings.Builder
+	result.WriteString("digraph stack {\n")
+	result.WriteString("  rankdir=BT;\n")
+	result.WriteString("  node [shape=box, style=rounded];\n")
+
+	for _, node := range s.graphNodes() {
+		escaped := []string{}
+		for _, line := range s.graphLabel(node, opts) {
+			escaped = append(escaped, dotEscape(line))
+		}
+
+		styles := []string{"rounded"}
+		attrs := []string{fmt.Sprintf("label=\"%s\"", strings.Join(escaped, "\\n"))}
+		if node.IsTrunk {
+			styles = append(styles, "filled")
+			attrs = append(attrs, "fillcolor=lightgrey")
+		}
+		if node.IsCurrent {
+			styles = append(styles, "bold")
+			attrs = append(attrs, "color=blue", "penwidth=2")
+		}
+		if opts.NeedsRestack[node.Name] {
+			styles = append(styles, "dashed")
+			if !node.IsCurrent {
+				attrs = append(attrs, "color=orange")
+			}
+		}
+		attrs = append(attrs, fmt.Sprintf("style=\"%s\"", strings.Join(styles, ",")))
+
+		fmt.Fprintf(&result, "  \"%s\" [%s];\n", dotEscape(node.Name), strings.Join(attrs, ", "))
+	}
+
+	for _, node := range s.graphNodes() {
+		for _, child := range node.SortedChildren() {
+			fmt.Fprintf(&result, "  \"%s\" -> \"%s\";\n", dotEscape(child.Name), dotEscape(node.Name))
+		}
+	}
+
+	result.WriteString("}\n")
+	return result.String()
+}
+
+// RenderMermaid renders the stack as a Mermaid flowchart, with trunk at the bottom.
+// Nodes get the classes trunk, current and needsRestack.
+func (s *Stack) RenderMermaid(opts GraphOptions) string {
+	var result strings.Builder
+	result.WriteString("graph BT\n")
+
+	nodes := s.graphNodes()
+	ids := make(map[string]string, len(nodes))
+	for i, node := range nodes {
+		ids[node.Name] = fmt.Sprintf("n%d", i)
+	}
+
+	classes := map[string][]string{}
+	for _, node := range nodes {
+		escaped := []string{}
+		for _, line := range s.graphLabel(node, opts) {
+			escaped = append(escaped, mermaidEscape(line))
+		}
+		fmt.Fprintf(&result, "  %s[\"%s\"]\n", ids[node.Name], strings.Join(escaped, "<br/>"))
+
+		if node.IsTrunk {
+			classes["trunk"] = append(classes["trunk"], ids[node.Name])
+		}
+		if node.IsCurrent {
+			classes["current"] = append(classes["current"], ids[node.Name])
+		}
+		if opts.NeedsRestack[node.Name] {
+			classes["needsRestack"] = append(classes["needsRestack"], ids[node.Name])
+		}
+	}
+
+	for _, node := range nodes {
+		for _, child := range node.SortedChildren() {
+			fmt.Fprintf(&result, "  %s --> %s\n", ids[child.Name], ids[node.Name])
+		}
+	}
+
+	result.WriteString("  classDef trunk fill:#eeeeee,stroke:#999999\n")
+	result.WriteString("  classDef current stroke:#1f6feb,stroke-width:3px\n")
+	result.WriteString("  classDef needsRestack stroke:#d29922,stroke-dasharray:5 5\n")
+	for _, class := range []string{"trunk", "current", "needsRestack"} {
+		if len(classes[class]) > 0 {
+			fmt.Fprintf(&result, "  class %s %s\n", strings.Join(classes[class], ","), class)
+		}
+	}
+
+	return result.String()
+}
+
+// dotEscape escapes text for use inside a double-quoted DOT string
+func dotEscape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text)
+}
+
+// mermaidEscape escapes text for use inside a double-quoted Mermaid label
+func mermaidEscape(text string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(text)
+}
@@ -0,0 +1,73 @@
+package stack
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+)
+
+func graphTestStack() *Stack {
+	trunk := &Node{Name: "main", IsTrunk: true}
+	a := &Node{Name: "feat-a", Parent: trunk, IsCurrent: true}
+	b := &Node{Name: `feat-"b"`, Parent: a}
+	trunk.Children = []*Node{a}
+	a.Children = []*Node{b}
+
+	prev := colors.IsEnabled()
+	colors.SetEnabled(true)
+	annotation := colors.Info("#12 open") + " · " + colors.StatusApproved("approved")
+	colors.SetEnabled(prev)
+
+	return &Stack{
+		Trunk:       trunk,
+		TrunkName:   "main",
+		Current:     "feat-a",
+		Nodes:       map[string]*Node{"main": trunk, "feat-a": a, `feat-"b"`: b},
+		Annotations: map[string]string{"feat-a": annotation},
+	}
+}
+
+func TestRenderDOT(t *testing.T) {
+	s := graphTestStack()
+	output := s.RenderDOT(GraphOptions{NeedsRestack: map[string]bool{`feat-"b"`: true}})
+
+	expected := []string{
+		"digraph stack {",
+		`"main" [label="main", fillcolor=lightgrey, style="rounded,filled"];`,
+		`"feat-a" [label="feat-a\n#12 open · approved", color=blue, penwidth=2, style="rounded,bold"];`,
+		`"feat-\"b\"" [label="feat-\"b\"\nneeds restack", color=orange, style="rounded,dashed"];`,
+		`"feat-a" -> "main";`,
+		`"feat-\"b\"" -> "feat-a";`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("expected DOT output to contain %q, got:\n%s", line, output)
+		}
+	}
+	if strings.Contains(output, "\033[") {
+		t.Errorf("expected no terminal colors in DOT output")
+	}
+}
+
+func TestRenderMermaid(t *testing.T) {
+	s := graphTestStack()
+	output := s.RenderMermaid(GraphOptions{NeedsRestack: map[string]bool{`feat-"b"`: true}})
+
+	expected := []string{
+		"graph BT",
+		`n0["main"]`,
+		`n1["feat-a<br/>#12 open · approved"]`,
+		`n2["feat-#quot;b#quot;<br/>needs restack"]`,
+		"n1 --> n0",
+		"n2 --> n1",
+		"class n0 trunk",
+		"class n1 current",
+		"class n2 needsRestack",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("expected Mermaid output to contain %q, got:\n%s", line, output)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package stack
+
+import (
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+)
+
+// SchemaVersion is the version of the JSON documents printed by --json.
+// It is bumped whenever a field is renamed, removed or changes meaning;
+// adding a field does not change it.
+const SchemaVersion = 1
+
+// Document is the top-level JSON object printed by --json
+type Document struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	Trunk         string       `json:"trunk"`
+	Current       string       `json:"current,omitempty"`
+	Branches      []BranchInfo `json:"branches"`
+}
+
+// BranchInfo describes one branch in a Document
+type BranchInfo struct {
+	Name         string   `json:"name"`
+	Parent       string   `json:"parent,omitempty"`
+	Children     []string `json:"children"`
+	SHA          string   `json:"sha"`
+	Depth        int      `json:"depth"`
+	Trunk        bool     `json:"trunk"`
+	Current      bool     `json:"current"`
+	Tracked      bool     `json:"tracked"`
+	NeedsRestack bool     `json:"needsRestack"`
+	Ahead        int      `json:"ahead"`
+	Behind       int      `json:"behind"`
+}
+
+// NewDocument describes the given branches. Names that are not part of the
+// stack are described as untracked branches.
+func (s *Stack) NewDocument(repo *git.Repo, metadata *config.Metadata, branches []string) *Document {
+	doc := &Document{
+		SchemaVersion: SchemaVersion,
+		Trunk:         s.TrunkName,
+		Current:       s.Current,
+		Branches:      []BranchInfo{},
+	}
+	for _, branch := range branches {
+		doc.Branches = append(doc.Branches, s.DescribeBranch(repo, metadata, branch))
+	}
+	return doc
+}
+
+// DescribeBranch collects the JSON description of a branch. Ahead and behind
+// count commits relative to the parent; both are zero for trunk.
+func (s *Stack) DescribeBranch(repo *git.Repo, metadata *config.Metadata, branch string) BranchInfo {
+	info := BranchInfo{
+		Name:     branch,
+		Children: []string{},
+		Current:  branch == s.Current,
+		Depth:    -1,
+	}
+
+	node := s.GetNode(branch)
+	if node == nil {
+		info.SHA, _ = repo.GetBranchCommit(branch)
+		return info
+	}
+
+	info.SHA = node.CommitSHA
+	info.Depth = s.GetStackDepth(branch)
+	info.Trunk = node.IsTrunk
+	info.Tracked = node.IsTrunk || metadata.IsTracked(branch)
+	for _, child := range node.SortedChildren() {
+		info.Children = append(info.Children, child.Name)
+	}
+
+	if node.Parent != nil {
+		info.Parent = node.Parent.Name
+		info.Ahead, info.Behind, _ = repo.AheadBehind(branch, node.Parent.Name)
+		info.NeedsRestack, _ = repo.IsBehind(branch, node.Parent.Name, metadata.GetParentSHA(branch))
+	}
+
+	return info
+}
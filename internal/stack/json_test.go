@@ -0,0 +1,73 @@
+package stack
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewDocument(t *testing.T) {
+	repo, cfg, metadata, dir, cleanup := setupStackRepo(t)
+	defer cleanup()
+
+	commit := func(name string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if _, err := repo.RunGitCommand("add", name); err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+		if _, err := repo.RunGitCommand("commit", "-m", name); err != nil {
+			t.Fatalf("failed to commit %s: %v", name, err)
+		}
+	}
+
+	if _, err := repo.RunGitCommand("checkout", "-b", "feat-1"); err != nil {
+		t.Fatalf("failed to create feat-1: %v", err)
+	}
+	commit("feat.txt")
+	metadata.TrackBranch("feat-1", "main")
+	if err := repo.CreateBranch("untracked"); err != nil {
+		t.Fatalf("failed to create untracked: %v", err)
+	}
+	if err := repo.CheckoutBranch("main"); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+	commit("main.txt")
+
+	s, err := BuildStack(repo, cfg, metadata)
+	if err != nil {
+		t.Fatalf("BuildStack failed: %v", err)
+	}
+
+	doc := s.NewDocument(repo, metadata, []string{"main", "feat-1", "untracked"})
+	if doc.SchemaVersion != SchemaVersion || doc.Trunk != "main" || doc.Current != "main" {
+		t.Fatalf("unexpected document header: %+v", doc)
+	}
+
+	trunk, feat, untracked := doc.Branches[0], doc.Branches[1], doc.Branches[2]
+	if !trunk.Trunk || !trunk.Current || trunk.Depth != 0 || len(trunk.Children) != 1 || trunk.Children[0] != "feat-1" {
+		t.Fatalf("unexpected trunk description: %+v", trunk)
+	}
+	if feat.Parent != "main" || feat.Depth != 1 || !feat.Tracked || !feat.NeedsRestack || feat.Ahead != 1 || feat.Behind != 1 {
+		t.Fatalf("unexpected feat-1 description: %+v", feat)
+	}
+	if untracked.Tracked || untracked.Depth != -1 || untracked.SHA == "" {
+		t.Fatalf("unexpected untracked description: %+v", untracked)
+	}
+
+	// Empty lists are encoded as [] rather than null
+	data, err := json.Marshal(untracked)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if _, ok := decoded["children"].([]interface{}); !ok {
+		t.Fatalf("expected children to be an array, got %v", decoded["children"])
+	}
+}
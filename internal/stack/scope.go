@@ -0,0 +1,97 @@
+package stack
+
+// Scope selects the branches a command acts on, relative to one branch
+type Scope string
+
+const (
+	// ScopeOnly is the branch alone
+	ScopeOnly Scope = "only"
+	// ScopeUpstack is the branch and all of its descendants
+	ScopeUpstack Scope = "upstack"
+	// ScopeDownstack is the branch and its ancestors down to, but excluding, trunk
+	ScopeDownstack Scope = "downstack"
+	// ScopeStack is the branch with both its ancestors and its descendants
+	ScopeStack Scope = "stack"
+)
+
+// SelectScope returns the branches in scope around branch, parents before children.
+// Trunk is never part of the result.
+func (s *Stack) SelectScope(branch string, scope Scope) []*Node {
+	node := s.GetNode(branch)
+	if node == nil {
+		return nil
+	}
+
+	result := []*Node{}
+	switch scope {
+	case ScopeOnly:
+		if !node.IsTrunk {
+			result = append(result, node)
+		}
+	case ScopeUpstack:
+		if !node.IsTrunk {
+			result = append(result, node)
+		}
+		result = append(result, s.GetUpstack(branch)...)
+	case ScopeDownstack:
+		result = append(result, s.GetDownstack(branch)...)
+	case ScopeStack:
+		result = append(result, s.GetDownstack(branch)...)
+		result = append(result, s.GetUpstack(branch)...)
+	}
+	return result
+}
+
+// Subset returns a copy of the stack holding trunk and the given branches.
+// A branch whose parent is left out hangs off trunk in the copy, but its Parent
+// still points at the real parent so commits are computed against it.
+func (s *Stack) Subset(branches []*Node) *Stack {
+	subset := &Stack{
+		Nodes:       make(map[string]*Node),
+		Current:     s.Current,
+		TrunkName:   s.TrunkName,
+		Annotations: s.Annotations,
+	}
+
+	copyNode := func(node *Node) *Node {
+		return &Node{
+			Name:      node.Name,
+			IsTrunk:   node.IsTrunk,
+			IsCurrent: node.IsCurrent,
+			CommitSHA: node.CommitSHA,
+			Children:  []*Node{},
+		}
+	}
+
+	subset.Trunk = copyNode(s.Trunk)
+	subset.Nodes[s.Trunk.Name] = subset.Trunk
+	for _, node := range branches {
+		if !node.IsTrunk {
+			subset.Nodes[node.Name] = copyNode(node)
+		}
+	}
+
+	// Link in topological order so siblings keep the order of the full stack
+	for _, node := range s.GetTopologicalOrder() {
+		child := subset.Nodes[node.Name]
+		if child == nil {
+			continue
+		}
+
+		parent := subset.Trunk
+		for ancestor := node.Parent; ancestor != nil; ancestor = ancestor.Parent {
+			if kept := subset.Nodes[ancestor.Name]; kept != nil {
+				parent = kept
+				break
+			}
+		}
+		parent.Children = append(parent.Children, child)
+
+		child.Parent = node.Parent
+		if kept := subset.Nodes[node.Parent.Name]; kept != nil {
+			child.Parent = kept
+		}
+	}
+
+	return subset
+}
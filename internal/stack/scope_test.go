@@ -0,0 +1,82 @@
+package stack
+
+import (
+	"strings"
+	"testing"
+)
+
+func scopeTestStack() *Stack {
+	trunk := &Node{Name: "main", IsTrunk: true}
+	a := &Node{Name: "feat-a", Parent: trunk}
+	b := &Node{Name: "feat-b", Parent: a}
+	c := &Node{Name: "feat-c", Parent: b}
+	d := &Node{Name: "feat-d", Parent: a}
+	e := &Node{Name: "feat-e", Parent: trunk}
+	trunk.Children = []*Node{e, a}
+	a.Children = []*Node{d, b}
+	b.Children = []*Node{c}
+
+	return &Stack{
+		Trunk:     trunk,
+		TrunkName: "main",
+		Nodes: map[string]*Node{
+			"main": trunk, "feat-a": a, "feat-b": b, "feat-c": c, "feat-d": d, "feat-e": e,
+		},
+	}
+}
+
+func TestSelectScope(t *testing.T) {
+	s := scopeTestStack()
+
+	tests := []struct {
+		branch string
+		scope  Scope
+		want   string
+	}{
+		{"feat-b", ScopeOnly, "feat-b"},
+		{"feat-b", ScopeUpstack, "feat-b feat-c"},
+		{"feat-b", ScopeDownstack, "feat-a feat-b"},
+		{"feat-b", ScopeStack, "feat-a feat-b feat-c"},
+		{"feat-a", ScopeUpstack, "feat-a feat-b feat-c feat-d"},
+		{"main", ScopeOnly, ""},
+		{"main", ScopeDownstack, ""},
+		{"main", ScopeUpstack, "feat-a feat-b feat-c feat-d feat-e"},
+		{"missing", ScopeStack, ""},
+	}
+
+	for _, tt := range tests {
+		got := []string{}
+		for _, node := range s.SelectScope(tt.branch, tt.scope) {
+			got = append(got, node.Name)
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("SelectScope(%s, %s) = %v, want %q", tt.branch, tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestSubset(t *testing.T) {
+	s := scopeTestStack()
+
+	subset := s.Subset(s.SelectScope("feat-b", ScopeUpstack))
+
+	if len(subset.Nodes) != 3 {
+		t.Fatalf("expected trunk and two branches, got %d nodes", len(subset.Nodes))
+	}
+	if len(subset.Trunk.Children) != 1 || subset.Trunk.Children[0].Name != "feat-b" {
+		t.Fatalf("expected feat-b to hang off trunk")
+	}
+
+	b := subset.GetNode("feat-b")
+	if b.Parent == nil || b.Parent.Name != "feat-a" {
+		t.Fatalf("expected feat-b to keep its real parent")
+	}
+	if c := subset.GetNode("feat-c"); c.Parent != b || len(b.Children) != 1 {
+		t.Fatalf("expected feat-c to stay under feat-b")
+	}
+
+	// The full stack is left untouched
+	if len(s.GetNode("feat-a").Children) != 2 {
+		t.Fatalf("expected the original stack to be unchanged")
+	}
+}
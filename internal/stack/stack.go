@@ -24,28 +24,41 @@ type Stack struct {
 	Nodes     map[string]*Node
 	Current   string
 	TrunkName string
+	// Annotations holds extra text rendered after branch names, keyed by branch
+	Annotations map[string]string
 }
 
-// BuildStack constructs the stack tree from metadata
+// BuildStack constructs the stack tree from metadata. Every branch is read
+// with one git call, cached on repo for later builds.
 func BuildStack(repo *git.Repo, cfg *config.Config, metadata *config.Metadata) (*Stack, error) {
 	stack := &Stack{
 		Nodes:     make(map[string]*Node),
 		TrunkName: cfg.Trunk,
 	}
 
+	branches, err := repo.Branches()
+	if err != nil {
+		return nil, err
+	}
+
 	// Get current branch
-	currentBranch, err := repo.GetCurrentBranch()
-	if err == nil {
-		stack.Current = currentBranch
+	for _, info := range branches {
+		if info.IsHead {
+			stack.Current = info.Name
+		}
 	}
 
-	// Verify trunk exists
-	if !repo.BranchExists(cfg.Trunk) {
+	// Verify trunk exists; it may also be a ref other than a local branch
+	var trunkSHA string
+	if info, ok := branches[cfg.Trunk]; ok {
+		trunkSHA = info.SHA
+	} else if repo.BranchExists(cfg.Trunk) {
+		trunkSHA, _ = repo.GetBranchCommit(cfg.Trunk)
+	} else {
 		return nil, fmt.Errorf("trunk branch '%s' does not exist", cfg.Trunk)
 	}
 
 	// Create trunk node
-	trunkSHA, _ := repo.GetBranchCommit(cfg.Trunk)
 	trunk := &Node{
 		Name:      cfg.Trunk,
 		IsTrunk:   true,
@@ -63,15 +76,15 @@ func BuildStack(repo *git.Repo, cfg *config.Config, metadata *config.Metadata) (
 		}
 
 		// Skip branches that don't exist in git
-		if !repo.BranchExists(branchName) {
+		info, ok := branches[branchName]
+		if !ok {
 			continue
 		}
 
-		commitSHA, _ := repo.GetBranchCommit(branchName)
 		node := &Node{
 			Name:      branchName,
 			IsCurrent: branchName == stack.Current,
-			CommitSHA: commitSHA,
+			CommitSHA: info.SHA,
 			Children:  []*Node{},
 		}
 		stack.Nodes[branchName] = node
@@ -231,3 +244,34 @@ func (s *Stack) GetTopologicalOrder() []*Node {
 
 	return result
 }
+
+// GetDownstack returns the branches from the bottom of the stack up to and including branch, excluding trunk
+func (s *Stack) GetDownstack(branch string) []*Node {
+	path := s.FindPath(branch)
+	result := []*Node{}
+	for _, node := range path {
+		if !node.IsTrunk {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+// GetUpstack returns all descendants of branch in topological order, excluding branch itself
+func (s *Stack) GetUpstack(branch string) []*Node {
+	node := s.GetNode(branch)
+	if node == nil {
+		return nil
+	}
+
+	result := []*Node{}
+	var visit func(n *Node)
+	visit = func(n *Node) {
+		for _, child := range n.SortedChildren() {
+			result = append(result, child)
+			visit(child)
+		}
+	}
+	visit(node)
+	return result
+}
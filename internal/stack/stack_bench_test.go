@@ -0,0 +1,100 @@
+package stack
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/config"
+	"github.com/israelmalagutti/git-wrapper/internal/git"
+)
+
+// setupBenchmarkStack tracks 150 branches in 15 stacks of 10, each branch one
+// commit on its parent
+func setupBenchmarkStack(b *testing.B) (*config.Config, *config.Metadata, func()) {
+	b.Helper()
+	repo, cfg, metadata, _, cleanup := setupStackRepo(b)
+
+	tree, err := repo.RunGitCommand("rev-parse", "HEAD^{tree}")
+	if err != nil {
+		b.Fatalf("failed to read tree: %v", err)
+	}
+	mainSHA, _ := repo.GetBranchCommit("main")
+
+	var updates []git.RefUpdate
+	tips := map[string]string{"main": mainSHA}
+	parent := "main"
+	for i := 0; i < 150; i++ {
+		if i%10 == 0 {
+			parent = "main"
+		}
+		name := fmt.Sprintf("feat-%03d", i)
+		sha, err := repo.CommitTree(tree, tips[parent], name)
+		if err != nil {
+			b.Fatalf("failed to commit %s: %v", name, err)
+		}
+		tips[name] = sha
+		updates = append(updates, git.RefUpdate{Ref: "refs/heads/" + name, NewSHA: sha})
+		metadata.TrackBranch(name, parent)
+		parent = name
+	}
+	if err := repo.UpdateRefs(updates); err != nil {
+		b.Fatalf("failed to create branches: %v", err)
+	}
+	return cfg, metadata, cleanup
+}
+
+// BenchmarkBuildStack builds the stack from a new Repo each time, as a command does
+func BenchmarkBuildStack(b *testing.B) {
+	cfg, metadata, cleanup := setupBenchmarkStack(b)
+	defer cleanup()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		repo, err := git.NewRepo()
+		if err != nil {
+			b.Fatalf("failed to open repo: %v", err)
+		}
+		s, err := BuildStack(repo, cfg, metadata)
+		if err != nil || len(s.Nodes) != 151 {
+			b.Fatalf("BuildStack failed: %v", err)
+		}
+		sortChildrenByTime(repo, s.Trunk.Children)
+	}
+}
+
+// BenchmarkBuildStackPerBranchLookups does the same work with the per-branch
+// git calls BuildStack used to make, for comparison
+func BenchmarkBuildStackPerBranchLookups(b *testing.B) {
+	cfg, metadata, cleanup := setupBenchmarkStack(b)
+	defer cleanup()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		repo, err := git.NewRepo()
+		if err != nil {
+			b.Fatalf("failed to open repo: %v", err)
+		}
+		_, _ = repo.GetCurrentBranch()
+		found := 0
+		for _, name := range append([]string{cfg.Trunk}, keys(metadata)...) {
+			if repo.BranchExists(name) {
+				_, _ = repo.GetBranchCommit(name)
+				found++
+			}
+		}
+		if found != 151 {
+			b.Fatalf("expected 151 branches, found %d", found)
+		}
+		for j := 0; j < 15; j++ {
+			_, _ = repo.RunGitCommand("log", "-1", "--format=%ct", fmt.Sprintf("feat-%03d", j*10))
+		}
+	}
+}
+
+func keys(metadata *config.Metadata) []string {
+	names := make([]string, 0, len(metadata.Branches))
+	for name := range metadata.Branches {
+		names = append(names, name)
+	}
+	return names
+}
@@ -8,9 +8,10 @@ import (
 
 	"github.com/israelmalagutti/git-wrapper/internal/config"
 	"github.com/israelmalagutti/git-wrapper/internal/git"
+	"github.com/israelmalagutti/git-wrapper/internal/git/gittest"
 )
 
-func setupStackRepo(t *testing.T) (*git.Repo, *config.Config, *config.Metadata, string, func()) {
+func setupStackRepo(t testing.TB) (*git.Repo, *config.Config, *config.Metadata, string, func()) {
 	t.Helper()
 
 	origDir, err := os.Getwd()
@@ -150,3 +151,72 @@ func TestGetTopologicalOrder(t *testing.T) {
 		t.Fatalf("unexpected topological order: %s, %s", order[0].Name, order[1].Name)
 	}
 }
+
+func TestGetDownstackAndUpstack(t *testing.T) {
+	trunk := &Node{Name: "main", IsTrunk: true}
+	a := &Node{Name: "feat-a", Parent: trunk}
+	b := &Node{Name: "feat-b", Parent: a}
+	c := &Node{Name: "feat-c", Parent: b}
+	d := &Node{Name: "feat-d", Parent: a}
+	trunk.Children = []*Node{a}
+	a.Children = []*Node{d, b}
+	b.Children = []*Node{c}
+
+	s := &Stack{
+		Trunk: trunk,
+		Nodes: map[string]*Node{"main": trunk, "feat-a": a, "feat-b": b, "feat-c": c, "feat-d": d},
+	}
+
+	names := func(nodes []*Node) []string {
+		result := []string{}
+		for _, n := range nodes {
+			result = append(result, n.Name)
+		}
+		return result
+	}
+
+	if got := names(s.GetDownstack("feat-c")); len(got) != 3 || got[0] != "feat-a" || got[2] != "feat-c" {
+		t.Fatalf("unexpected downstack: %v", got)
+	}
+	if got := names(s.GetUpstack("feat-a")); len(got) != 3 || got[0] != "feat-b" || got[1] != "feat-c" || got[2] != "feat-d" {
+		t.Fatalf("unexpected upstack: %v", got)
+	}
+	if got := s.GetDownstack("main"); len(got) != 0 {
+		t.Fatalf("expected empty downstack for trunk, got %v", names(got))
+	}
+	if s.GetUpstack("missing") != nil {
+		t.Fatalf("expected nil upstack for unknown branch")
+	}
+}
+
+func TestBuildStackReadsBranchesOnce(t *testing.T) {
+	runner := gittest.NewRunner(t)
+	runner.ExpectRepo(t.TempDir())
+	repo, err := git.NewRepoWithRunner(runner)
+	if err != nil {
+		t.Fatalf("NewRepoWithRunner failed: %v", err)
+	}
+
+	// The only git call for the whole build, and none for a second build
+	runner.Expect("for-each-ref", "--format=%(refname)\t%(objectname)\t%(committerdate:unix)\t%(HEAD)\t%(upstream:short)\t%(upstream:track,nobracket)", "refs/heads").
+		Returns("refs/heads/main\tm1\t100\t\t\t\nrefs/heads/feat-a\ta1\t200\t*\t\t\nrefs/heads/feat-b\tb1\t300\t\t\t\n")
+
+	cfg := config.NewConfig("main")
+	metadata := &config.Metadata{Branches: map[string]*config.BranchMetadata{}}
+	metadata.TrackBranch("feat-a", "main")
+	metadata.TrackBranch("feat-b", "main")
+	metadata.TrackBranch("deleted", "feat-a")
+
+	for i := 0; i < 2; i++ {
+		s, err := BuildStack(repo, cfg, metadata)
+		if err != nil {
+			t.Fatalf("BuildStack failed: %v", err)
+		}
+		if s.Current != "feat-a" || !s.GetNode("feat-a").IsCurrent || s.GetNode("feat-b").CommitSHA != "b1" || s.GetNode("deleted") != nil {
+			t.Fatalf("unexpected stack %+v", s.Nodes)
+		}
+		if sorted := sortChildrenByTime(repo, s.Trunk.Children); sorted[0].Name != "feat-b" {
+			t.Fatalf("expected the newer feat-b first, got %s", sorted[0].Name)
+		}
+	}
+}
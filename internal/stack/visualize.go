@@ -139,6 +139,9 @@ func (s *Stack) renderBranchLineWithCommits(result *strings.Builder, node *Node,
 	if node.IsCurrent {
 		result.WriteString(colors.Muted(" (current)"))
 	}
+	if annotation := s.Annotations[node.Name]; annotation != "" {
+		result.WriteString(" " + annotation)
+	}
 
 	// Get commits
 	var commits []Commit
@@ -289,10 +292,15 @@ func sortChildrenByTime(repo *git.Repo, children []*Node) []*Node {
 	sorted := make([]*Node, len(children))
 	copy(sorted, children)
 
-	// Get timestamps for all children
+	// Get timestamps for all children, from the cached branch list when possible
+	branches, _ := repo.Branches()
 	timestamps := make(map[string]int64)
 	for _, child := range sorted {
-		timestamps[child.Name] = getCommitTimestamp(repo, child.Name)
+		if info, ok := branches[child.Name]; ok {
+			timestamps[child.Name] = info.CommitTime.Unix()
+		} else {
+			timestamps[child.Name] = getCommitTimestamp(repo, child.Name)
+		}
 	}
 
 	// Sort by timestamp descending (newer first = higher timestamp first)
@@ -475,6 +483,9 @@ func (s *Stack) renderSiblingsShort(result *strings.Builder, siblings []*Node, r
 		if node.IsCurrent {
 			result.WriteString(colors.Muted(" (current)"))
 		}
+		if annotation := s.Annotations[node.Name]; annotation != "" {
+			result.WriteString(" " + annotation)
+		}
 		result.WriteString("\n")
 
 		// Connector line
@@ -61,6 +61,26 @@ func TestRenderTreeNilRepo(t *testing.T) {
 	}
 }
 
+func TestRenderAnnotations(t *testing.T) {
+	trunk := &Node{Name: "main", IsTrunk: true}
+	a := &Node{Name: "feat-a", Parent: trunk}
+	trunk.Children = []*Node{a}
+
+	s := &Stack{
+		Trunk:       trunk,
+		Nodes:       map[string]*Node{"main": trunk, "feat-a": a},
+		TrunkName:   "main",
+		Annotations: map[string]string{"feat-a": "#7 open"},
+	}
+
+	if out := s.RenderTree(nil, TreeOptions{}); !strings.Contains(out, "feat-a #7 open") {
+		t.Fatalf("expected annotation in tree render, got:\n%s", out)
+	}
+	if out := s.RenderShort(nil); !strings.Contains(out, "feat-a #7 open") {
+		t.Fatalf("expected annotation in short render, got:\n%s", out)
+	}
+}
+
 func TestCommitHelpersWithRepo(t *testing.T) {
 	repo, cfg, metadata, _, cleanup := setupStackRepo(t)
 	defer cleanup()
@@ -0,0 +1,84 @@
+package tui
+
+import "unicode/utf8"
+
+// Key identifies a decoded key press
+type Key int
+
+const (
+	// KeyRune is a printable character, stored in Event.Rune
+	KeyRune Key = iota
+	KeyUp
+	KeyDown
+	KeyEnter
+	KeyEscape
+	// KeyInterrupt is Ctrl+C
+	KeyInterrupt
+	// KeyUnknown is an escape sequence or control character gw does not use
+	KeyUnknown
+)
+
+// Event is a single key press read from a terminal in raw mode
+type Event struct {
+	Key  Key
+	Rune rune
+}
+
+// ParseEvents decodes the key presses in data, the bytes returned by one read
+// from a terminal in raw mode. Arrow keys arrive as ESC [ A / ESC [ B; an ESC
+// that ends the read is the Escape key.
+func ParseEvents(data []byte) []Event {
+	events := []Event{}
+	for len(data) > 0 {
+		var event Event
+		event, data = parseEvent(data)
+		events = append(events, event)
+	}
+	return events
+}
+
+// parseEvent decodes the key press at the start of data and returns the rest
+func parseEvent(data []byte) (Event, []byte) {
+	ch, size := utf8.DecodeRune(data)
+	rest := data[size:]
+
+	switch ch {
+	case '\r', '\n':
+		return Event{Key: KeyEnter}, rest
+	case 3:
+		return Event{Key: KeyInterrupt}, rest
+	case 27:
+		if len(rest) == 0 {
+			return Event{Key: KeyEscape}, rest
+		}
+		return parseEscapeSequence(rest)
+	}
+
+	if ch < 32 || ch == 127 || ch == utf8.RuneError {
+		return Event{Key: KeyUnknown}, rest
+	}
+	return Event{Key: KeyRune, Rune: ch}, rest
+}
+
+// parseEscapeSequence decodes the rest of a sequence after ESC
+func parseEscapeSequence(data []byte) (Event, []byte) {
+	if data[0] != '[' && data[0] != 'O' {
+		return Event{Key: KeyUnknown}, data[1:]
+	}
+
+	// Parameters are digits and semicolons, ended by a single final byte
+	for i := 1; i < len(data); i++ {
+		final := data[i]
+		if (final >= '0' && final <= '9') || final == ';' {
+			continue
+		}
+		switch final {
+		case 'A':
+			return Event{Key: KeyUp}, data[i+1:]
+		case 'B':
+			return Event{Key: KeyDown}, data[i+1:]
+		}
+		return Event{Key: KeyUnknown}, data[i+1:]
+	}
+	return Event{Key: KeyUnknown}, nil
+}
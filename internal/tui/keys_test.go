@@ -0,0 +1,43 @@
+package tui
+
+import "testing"
+
+func TestParseEvents(t *testing.T) {
+	got := ParseEvents([]byte("j\x1b[A\x1b[B\r\x03\x1b[1;5Cq\x01é"))
+
+	expected := []Event{
+		{Key: KeyRune, Rune: 'j'},
+		{Key: KeyUp},
+		{Key: KeyDown},
+		{Key: KeyEnter},
+		{Key: KeyInterrupt},
+		{Key: KeyUnknown},
+		{Key: KeyRune, Rune: 'q'},
+		{Key: KeyUnknown},
+		{Key: KeyRune, Rune: 'é'},
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d events, got %+v", len(expected), got)
+	}
+	for i, want := range expected {
+		if got[i] != want {
+			t.Fatalf("event %d: expected %+v, got %+v", i, want, got[i])
+		}
+	}
+}
+
+func TestParseEventsEscape(t *testing.T) {
+	// An ESC ending the read is the Escape key
+	if got := ParseEvents([]byte("\x1b")); len(got) != 1 || got[0].Key != KeyEscape {
+		t.Fatalf("expected escape, got %+v", got)
+	}
+
+	// A sequence cut off by the end of the read is unknown
+	if got := ParseEvents([]byte("\x1b[1;")); len(got) != 1 || got[0].Key != KeyUnknown {
+		t.Fatalf("expected one unknown event, got %+v", got)
+	}
+
+	if got := ParseEvents([]byte("\x1bxq")); len(got) != 2 || got[0].Key != KeyUnknown || got[1].Rune != 'q' {
+		t.Fatalf("expected unknown then q, got %+v", got)
+	}
+}
@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// ANSI sequences for the alternate screen and cursor visibility
+const (
+	enterAltScreen = "\033[?1049h\033[?25l"
+	leaveAltScreen = "\033[?25h\033[?1049l"
+)
+
+// Terminal is a terminal in raw mode showing the alternate screen
+type Terminal struct {
+	in      *os.File
+	out     io.Writer
+	state   *term.State
+	pending []Event // decoded from the last read but not handled yet
+}
+
+// Open puts in into raw mode and switches out to the alternate screen
+func Open(in *os.File, out io.Writer) (*Terminal, error) {
+	if !term.IsTerminal(int(in.Fd())) {
+		return nil, fmt.Errorf("gw tui needs an interactive terminal")
+	}
+
+	t := &Terminal{in: in, out: out}
+	if err := t.Resume(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Resume re-enters raw mode and the alternate screen after Suspend
+func (t *Terminal) Resume() error {
+	state, err := term.MakeRaw(int(t.in.Fd()))
+	if err != nil {
+		return fmt.Errorf("failed to enter raw mode: %w", err)
+	}
+	t.state = state
+	_, err = io.WriteString(t.out, enterAltScreen)
+	return err
+}
+
+// Suspend restores the normal screen and terminal mode, so commands can
+// print output and show prompts
+func (t *Terminal) Suspend() error {
+	if t.state == nil {
+		return nil
+	}
+	if _, err := io.WriteString(t.out, leaveAltScreen); err != nil {
+		return err
+	}
+	err := term.Restore(int(t.in.Fd()), t.state)
+	t.state = nil
+	t.pending = nil
+	return err
+}
+
+// WaitForKey waits for a key press on the normal screen while suspended
+func (t *Terminal) WaitForKey() error {
+	state, err := term.MakeRaw(int(t.in.Fd()))
+	if err != nil {
+		return fmt.Errorf("failed to enter raw mode: %w", err)
+	}
+	defer func() { _ = term.Restore(int(t.in.Fd()), state) }()
+
+	_, err = t.ReadEvent()
+	return err
+}
+
+// ReadEvent returns the next key press. The file is read directly, one read at
+// a time, so nothing typed after the TUI hands over to a prompt is read ahead.
+func (t *Terminal) ReadEvent() (Event, error) {
+	for len(t.pending) == 0 {
+		buf := make([]byte, 64)
+		n, err := t.in.Read(buf)
+		if err != nil {
+			return Event{}, err
+		}
+		t.pending = ParseEvents(buf[:n])
+	}
+
+	event := t.pending[0]
+	t.pending = t.pending[1:]
+	return event, nil
+}
+
+// Close leaves the terminal as it was before Open
+func (t *Terminal) Close() error {
+	return t.Suspend()
+}
+
+// Height returns the number of rows on the screen
+func (t *Terminal) Height() int {
+	if _, height, err := term.GetSize(int(t.in.Fd())); err == nil && height > 0 {
+		return height
+	}
+	return 24
+}
+
+// Draw writes a frame produced by View.Render
+func (t *Terminal) Draw(frame string) error {
+	_, err := io.WriteString(t.out, frame)
+	return err
+}
@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"os"
+	"testing"
+)
+
+func TestTerminalReadEvent(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	term := &Terminal{in: r}
+
+	// Keys from one read are handed out one at a time
+	if _, err := w.Write([]byte("jk")); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	for _, want := range []rune{'j', 'k'} {
+		event, err := term.ReadEvent()
+		if err != nil || event.Rune != want {
+			t.Fatalf("expected %q, got %+v, %v", want, event, err)
+		}
+	}
+
+	// Later input is read from the file only when the next key is asked for
+	if _, err := w.Write([]byte("\x1b[A")); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	if event, err := term.ReadEvent(); err != nil || event.Key != KeyUp {
+		t.Fatalf("expected up, got %+v, %v", event, err)
+	}
+}
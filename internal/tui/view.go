@@ -0,0 +1,142 @@
+// Package tui implements the full-screen stack browser behind 'gw tui'.
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+)
+
+// ANSI sequences used to draw a frame
+const (
+	clearScreen  = "\033[H\033[2J"
+	reverseVideo = "\033[7m"
+	resetStyle   = "\033[0m"
+)
+
+// View is the rendered stack with a cursor over its branch lines
+type View struct {
+	lines    []string
+	rows     []int          // indexes of lines that show a branch, top to bottom
+	branches map[int]string // branch shown on each of those lines
+	cursor   int            // index into rows
+	top      int            // first line on screen
+
+	// Moving is the branch picked up to be moved, if any
+	Moving string
+	// Status is shown above the key help
+	Status string
+	// Help lists the key bindings
+	Help string
+}
+
+// NewView splits a tree rendered by stack.RenderTree into lines and finds
+// the lines that show one of the known branches
+func NewView(tree string, known map[string]bool) *View {
+	v := &View{
+		lines:    strings.Split(strings.TrimRight(tree, "\n"), "\n"),
+		branches: map[int]string{},
+	}
+	for i, line := range v.lines {
+		if name := branchOnLine(line); known[name] {
+			v.rows = append(v.rows, i)
+			v.branches[i] = name
+		}
+	}
+	return v
+}
+
+// branchOnLine returns the branch name after the ○/◉ indicator of a branch line,
+// or "" for commit and connector lines
+func branchOnLine(line string) string {
+	chars := colors.DefaultTreeChars()
+	plain := colors.Strip(line)
+
+	rest := strings.TrimLeft(plain, " "+chars.Vertical+chars.Horizontal+chars.Tee+chars.Corner+chars.TopCorner+chars.BottomRight)
+	for _, indicator := range []string{chars.Circle, chars.FilledCircle} {
+		if strings.HasPrefix(rest, indicator+" ") {
+			fields := strings.Fields(strings.TrimPrefix(rest, indicator+" "))
+			if len(fields) > 0 {
+				return fields[0]
+			}
+		}
+	}
+	return ""
+}
+
+// Select moves the cursor to branch and reports whether it is shown
+func (v *View) Select(branch string) bool {
+	for i, line := range v.rows {
+		if v.branches[line] == branch {
+			v.cursor = i
+			return true
+		}
+	}
+	return false
+}
+
+// Selected returns the branch under the cursor
+func (v *View) Selected() string {
+	if len(v.rows) == 0 {
+		return ""
+	}
+	return v.branches[v.rows[v.cursor]]
+}
+
+// Up moves the cursor to the branch above
+func (v *View) Up() {
+	if v.cursor > 0 {
+		v.cursor--
+	}
+}
+
+// Down moves the cursor to the branch below
+func (v *View) Down() {
+	if v.cursor < len(v.rows)-1 {
+		v.cursor++
+	}
+}
+
+// Render draws the view for a screen of the given height. Lines end in \r\n
+// because output post-processing is off in raw mode.
+func (v *View) Render(height int) string {
+	var frame strings.Builder
+	frame.WriteString(clearScreen)
+
+	// Keep the selected line on screen, leaving room for the status and help lines
+	visible := height - 2
+	if visible < 1 {
+		visible = 1
+	}
+	selectedLine := -1
+	if len(v.rows) > 0 {
+		selectedLine = v.rows[v.cursor]
+	}
+	if selectedLine >= 0 && selectedLine < v.top {
+		v.top = selectedLine
+	}
+	if selectedLine >= v.top+visible {
+		v.top = selectedLine - visible + 1
+	}
+
+	for i := v.top; i < len(v.lines) && i < v.top+visible; i++ {
+		marker := "  "
+		if name, ok := v.branches[i]; ok && name == v.Moving {
+			marker = "» "
+		}
+
+		if i == selectedLine {
+			fmt.Fprintf(&frame, "%s%s%s%s\r\n", marker, reverseVideo, colors.Strip(v.lines[i]), resetStyle)
+		} else {
+			fmt.Fprintf(&frame, "%s%s\r\n", marker, v.lines[i])
+		}
+	}
+	for i := len(v.lines) - v.top; i < visible; i++ {
+		frame.WriteString("\r\n")
+	}
+
+	frame.WriteString(v.Status + "\r\n")
+	frame.WriteString(colors.Muted(v.Help))
+	return frame.String()
+}
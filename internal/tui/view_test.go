@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/israelmalagutti/git-wrapper/internal/colors"
+)
+
+const testTree = `◉ feat-b (current) · 2 minutes ago
+│ 1234567 - mentions ○ feat-a in a commit
+│
+○ feat-a · 5 minutes ago
+│ 89abcde - add a
+│
+├── ○ feat-c
+│
+○ main · 1 hour ago
+│ fedcba9 - init
+│
+`
+
+func testView() *View {
+	return NewView(testTree, map[string]bool{"main": true, "feat-a": true, "feat-b": true, "feat-c": true})
+}
+
+func TestNewViewFindsBranchLines(t *testing.T) {
+	v := testView()
+
+	names := []string{}
+	for {
+		names = append(names, v.Selected())
+		before := v.Selected()
+		v.Down()
+		if v.Selected() == before {
+			break
+		}
+	}
+	if strings.Join(names, " ") != "feat-b feat-a feat-c main" {
+		t.Fatalf("unexpected branch lines: %v", names)
+	}
+
+	v.Up()
+	if v.Selected() != "feat-c" {
+		t.Fatalf("expected Up to move to feat-c, got %s", v.Selected())
+	}
+	if !v.Select("feat-a") || v.Selected() != "feat-a" {
+		t.Fatalf("expected to select feat-a")
+	}
+	if v.Select("missing") {
+		t.Fatalf("expected unknown branch not to be selectable")
+	}
+}
+
+func TestViewRender(t *testing.T) {
+	prev := colors.IsEnabled()
+	defer colors.SetEnabled(prev)
+	colors.SetEnabled(false)
+
+	v := testView()
+	v.Select("feat-a")
+	v.Moving = "feat-c"
+	v.Status = "Moving feat-c"
+	v.Help = "q quit"
+
+	frame := v.Render(40)
+	if !strings.HasPrefix(frame, clearScreen) {
+		t.Fatalf("expected frame to clear the screen")
+	}
+	if !strings.Contains(frame, "  "+reverseVideo+"○ feat-a · 5 minutes ago"+resetStyle+"\r\n") {
+		t.Fatalf("expected the selected line to be highlighted, got %q", frame)
+	}
+	if !strings.Contains(frame, "» ├── ○ feat-c\r\n") {
+		t.Fatalf("expected the moving branch to be marked, got %q", frame)
+	}
+	if !strings.HasSuffix(frame, "Moving feat-c\r\nq quit") {
+		t.Fatalf("expected status and help at the bottom, got %q", frame)
+	}
+
+	// A short screen scrolls to keep the selection visible
+	v.Select("main")
+	frame = v.Render(4)
+	if !strings.Contains(frame, "○ main") || strings.Contains(frame, "feat-b") {
+		t.Fatalf("expected the view to scroll to main, got %q", frame)
+	}
+}